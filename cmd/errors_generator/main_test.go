@@ -1,10 +1,21 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"flag"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,10 +33,136 @@ func TestNewGenerator(t *testing.T) {
 	assert.NotNil(t, gen)
 	assert.NotNil(t, gen.templates)
 	assert.Equal(t, Version, gen.data.Version)
+	assert.Equal(t, "errors", gen.data.PackageName)
+	assert.True(t, gen.Format)
 	assert.True(t, gen.data.WithGenHeader)
 	assert.Equal(t, TestGenNone, gen.TestGenLevel)
 	assert.NotEmpty(t, gen.data.Date)
-	assert.Equal(t, []string{"attr", "common", "error", "join", "json", "map", "string", "wrap"}, gen.Formats)
+	assert.Equal(t, []string{"attr", "common", "error", "join", "json", "map", "string", "wrap", "xml"}, gen.Formats)
+}
+
+// TestLoadConfig tests the loadConfig function with the generator flags.
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                string
+		config              string
+		args                []string
+		expectErrorIn       string
+		expectOutputDir     string
+		expectPackageName   string
+		expectInputDir      string
+		expectFormats       string
+		expectTestGen       string
+		expectError         bool
+		expectWithGenHeader bool
+	}{
+		{
+			name: "file_only_config",
+			config: "output-dir: ./pkg/zap\n" +
+				"package: zaperrors\n" +
+				"input-dir: ./tmpl\n" +
+				"with-gen-header: false\n" +
+				"test-gen: strict\n" +
+				"formats:\n  - zap\n  - slog\n",
+			expectOutputDir:     "./pkg/zap",
+			expectPackageName:   "zaperrors",
+			expectInputDir:      "./tmpl",
+			expectFormats:       "zap,slog",
+			expectTestGen:       TestGenStrict,
+			expectWithGenHeader: false,
+		},
+		{
+			name:                "cli_overrides_file_values",
+			config:              "output-dir: ./pkg/zap\npackage: zaperrors\ntest-gen: strict\n",
+			args:                []string{"-package", "clierrors", "-test-gen", "flex"},
+			expectOutputDir:     "./pkg/zap",
+			expectPackageName:   "clierrors",
+			expectTestGen:       TestGenFlex,
+			expectWithGenHeader: true,
+		},
+		{
+			name:                "empty_config_keeps_defaults",
+			config:              "",
+			expectPackageName:   "errors",
+			expectTestGen:       TestGenNone,
+			expectWithGenHeader: true,
+		},
+		{
+			name:          "unknown_key",
+			config:        "output-dir: ./pkg\nouput-dir: ./typo\n",
+			expectError:   true,
+			expectErrorIn: "field ouput-dir not found",
+		},
+		{
+			name:          "malformed_config",
+			config:        "output-dir: [unterminated\n",
+			expectError:   true,
+			expectErrorIn: "parsing config file",
+		},
+		{
+			name:          "invalid_value",
+			config:        "with-gen-header: maybe\n",
+			expectError:   true,
+			expectErrorIn: "parsing config file",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a config file and the command-line arguments
+				path := filepath.Join(t.TempDir(), "errors.gen.yaml")
+				require.NoError(t, os.WriteFile(path, []byte(test.config), filePermissions))
+
+				var options cliOptions
+
+				gen := New()
+				flagSet := gen.flagSet(&options, flag.ContinueOnError)
+				require.NoError(t, flagSet.Parse(append([]string{"-config", path}, test.args...)))
+
+				// when: loading the config file
+				err := loadConfig(flagSet, options.config)
+
+				// then: the values should be set, with command-line flags taking precedence
+				if test.expectError {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), test.expectErrorIn)
+
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, test.expectOutputDir, gen.OutputDir)
+				assert.Equal(t, test.expectPackageName, gen.data.PackageName)
+				assert.Equal(t, test.expectInputDir, gen.InputDir)
+				assert.Equal(t, test.expectWithGenHeader, gen.data.WithGenHeader)
+				assert.Equal(t, test.expectFormats, options.formats)
+				assert.Equal(t, test.expectTestGen, options.testGen)
+			},
+		)
+	}
+}
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	t.Parallel()
+
+	// given: a flag set and a missing config file
+	var options cliOptions
+
+	flagSet := New().flagSet(&options, flag.ContinueOnError)
+
+	// when: loading the config file
+	err := loadConfig(flagSet, filepath.Join(t.TempDir(), "missing.yaml"))
+
+	// then: an error should be returned
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading config file")
 }
 
 // TestValidateTestGenLevel tests the validateTestGenLevel method.
@@ -158,6 +295,85 @@ func TestLoadFormats(t *testing.T) {
 	}
 }
 
+// TestExcludeFormats tests the loadExclude and excludeFormats methods.
+func TestExcludeFormats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		exclude         string
+		initialFormats  []string
+		expectedFormats []string
+	}{
+		{
+			name:            "empty_exclude_keeps_formats",
+			exclude:         "",
+			initialFormats:  []string{"attr", "common"},
+			expectedFormats: []string{"attr", "common"},
+		},
+		{
+			name:            "exclude_with_explicit_list",
+			exclude:         "logrus",
+			initialFormats:  []string{"attr", "common", "logrus", "zap"},
+			expectedFormats: []string{"attr", "common", "zap"},
+		},
+		{
+			name:            "exclude_multiple_formats",
+			exclude:         "logrus,zap",
+			initialFormats:  []string{"attr", "logrus", "common", "zap"},
+			expectedFormats: []string{"attr", "common"},
+		},
+		{
+			name:            "exclude_nonexistent_format_is_noop",
+			exclude:         "nonexistent",
+			initialFormats:  []string{"attr", "common"},
+			expectedFormats: []string{"attr", "common"},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator with initial formats
+				gen := New()
+				gen.Formats = test.initialFormats
+
+				// when: excluding formats
+				gen.loadExclude(test.exclude)
+				gen.excludeFormats()
+
+				// then: formats should match expectation
+				assert.Equal(t, test.expectedFormats, gen.Formats)
+			},
+		)
+	}
+}
+
+// TestRunExcludeWithAll tests the Run method excluding formats from all the discovered ones.
+func TestRunExcludeWithAll(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator with all formats and some excluded
+	gen := New()
+	gen.OutputDir = t.TempDir()
+	gen.loadFormats("all")
+	gen.loadExclude("logrus,nonexistent")
+
+	// when: running the generator
+	err := gen.Run()
+
+	// then: every format but the excluded ones should be generated
+	require.NoError(t, err)
+	assert.NotContains(t, gen.Formats, "logrus")
+	assert.Contains(t, gen.Formats, "zap")
+	assert.NoFileExists(t, filepath.Join(gen.OutputDir, "logrus.go"))
+	assert.FileExists(t, filepath.Join(gen.OutputDir, "zap.go"))
+}
+
 // TestDiscoverTemplateFormats tests the discoverTemplateFormats method.
 func TestDiscoverTemplateFormats(t *testing.T) {
 	t.Parallel()
@@ -381,12 +597,13 @@ func TestGenerateFile(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		setupGen     func(*Generator)
-		validateFile func(*testing.T, string)
-		name         string
-		templateName string
-		outputName   string
-		expectError  bool
+		setupGen      func(*Generator)
+		validateFile  func(*testing.T, string)
+		name          string
+		templateName  string
+		outputName    string
+		expectErrorIn string
+		expectError   bool
 	}{
 		{
 			name:         "successful_generation",
@@ -403,7 +620,7 @@ func TestGenerateFile(t *testing.T) {
 
 				content, err := os.ReadFile(path) //nolint:gosec // security is not a concern here
 				require.NoError(t, err)
-				assert.Equal(t, "package testpkg", string(content))
+				assert.Equal(t, "package testpkg\n", string(content))
 			},
 		},
 		{
@@ -423,6 +640,63 @@ func TestGenerateFile(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name:         "unformatted_template_is_formatted",
+			templateName: "unformatted.tmpl",
+			outputName:   "unformatted.go",
+			setupGen: func(gen *Generator) {
+				tmpl := template.Must(
+					template.New("unformatted.tmpl").Parse(
+						"package {{.PackageName}}\nfunc   Foo( a int)int{\nreturn a}\n\n\n",
+					),
+				)
+				gen.templates["unformatted.tmpl"] = tmpl
+				gen.data.PackageName = "testpkg"
+			},
+			expectError: false,
+			validateFile: func(t *testing.T, path string) {
+				t.Helper()
+
+				content, err := os.ReadFile(path) //nolint:gosec // security is not a concern here
+				require.NoError(t, err)
+				assert.Equal(t, "package testpkg\n\nfunc Foo(a int) int {\n\treturn a\n}\n", string(content))
+
+				formatted, err := format.Source(content)
+				require.NoError(t, err)
+				assert.Equal(t, string(formatted), string(content))
+			},
+		},
+		{
+			name:         "unformatted_template_without_format",
+			templateName: "unformatted.tmpl",
+			outputName:   "unformatted.go",
+			setupGen: func(gen *Generator) {
+				tmpl := template.Must(template.New("unformatted.tmpl").Parse("package {{.PackageName}}\nfunc   Foo(){}"))
+				gen.templates["unformatted.tmpl"] = tmpl
+				gen.data.PackageName = "testpkg"
+				gen.Format = false
+			},
+			expectError: false,
+			validateFile: func(t *testing.T, path string) {
+				t.Helper()
+
+				content, err := os.ReadFile(path) //nolint:gosec // security is not a concern here
+				require.NoError(t, err)
+				assert.Equal(t, "package testpkg\nfunc   Foo(){}", string(content))
+			},
+		},
+		{
+			name:         "invalid_go_with_format",
+			templateName: "invalid.tmpl",
+			outputName:   "invalid.go",
+			setupGen: func(gen *Generator) {
+				tmpl := template.Must(template.New("invalid.tmpl").Parse("package {{.PackageName}}\nfunc {"))
+				gen.templates["invalid.tmpl"] = tmpl
+				gen.data.PackageName = "testpkg"
+			},
+			expectError:   true,
+			expectErrorIn: "formatting invalid.go",
+		},
 	}
 
 	for _, tt := range tests {
@@ -442,7 +716,12 @@ func TestGenerateFile(t *testing.T) {
 
 				// then: error should match expectation
 				if test.expectError {
-					assert.Error(t, err)
+					require.Error(t, err)
+
+					if test.expectErrorIn != "" {
+						assert.Contains(t, err.Error(), test.expectErrorIn)
+						assert.NoFileExists(t, filepath.Join(gen.OutputDir, test.outputName))
+					}
 				} else {
 					assert.NoError(t, err)
 
@@ -456,82 +735,37 @@ func TestGenerateFile(t *testing.T) {
 	}
 }
 
-// TestGenerateFormat tests the generateFormat method.
-func TestGenerateFormat(t *testing.T) {
+// TestGenerateFileSkipExisting tests the generateFile method with existing output files.
+func TestGenerateFileSkipExisting(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		setupGen     func(*Generator)
-		name         string
-		format       string
-		testGenLevel string
-		expectFiles  []string
-		expectError  bool
+		name          string
+		expectContent string
+		expectOut     string
+		skipExisting  bool
+		preCreate     bool
 	}{
 		{
-			name:         "none_level_no_test",
-			format:       "error",
-			testGenLevel: TestGenNone,
-			setupGen: func(gen *Generator) {
-				tmpl := template.Must(template.New("error.tmpl").Parse("package {{.PackageName}}"))
-				gen.templates["error.tmpl"] = tmpl
-			},
-			expectError: false,
-			expectFiles: []string{"error.go"},
-		},
-		{
-			name:         "flex_level_with_test_template",
-			format:       "error",
-			testGenLevel: TestGenFlex,
-			setupGen: func(gen *Generator) {
-				tmpl := template.Must(template.New("error.tmpl").Parse("package {{.PackageName}}"))
-				gen.templates["error.tmpl"] = tmpl
-				testTmpl := template.Must(template.New("error_test.tmpl").Parse("package {{.PackageName}}"))
-				gen.templates["error_test.tmpl"] = testTmpl
-			},
-			expectError: false,
-			expectFiles: []string{"error.go", "error_test.go"},
-		},
-		{
-			name:         "flex_level_without_test_template",
-			format:       "error",
-			testGenLevel: TestGenFlex,
-			setupGen: func(gen *Generator) {
-				tmpl := template.Must(template.New("error.tmpl").Parse("package {{.PackageName}}"))
-				gen.templates["error.tmpl"] = tmpl
-			},
-			expectError: false,
-			expectFiles: []string{"error.go"},
-		},
-		{
-			name:         "strict_level_with_test_template",
-			format:       "error",
-			testGenLevel: TestGenStrict,
-			setupGen: func(gen *Generator) {
-				tmpl := template.Must(template.New("error.tmpl").Parse("package {{.PackageName}}"))
-				gen.templates["error.tmpl"] = tmpl
-				testTmpl := template.Must(template.New("error_test.tmpl").Parse("package {{.PackageName}}"))
-				gen.templates["error_test.tmpl"] = testTmpl
-			},
-			expectError: false,
-			expectFiles: []string{"error.go", "error_test.go"},
+			name:          "skip_existing_file",
+			skipExisting:  true,
+			preCreate:     true,
+			expectContent: "package handtuned\n",
+			expectOut:     "skipping existing: error.go\n",
 		},
 		{
-			name:         "strict_level_without_test_template",
-			format:       "error",
-			testGenLevel: TestGenStrict,
-			setupGen: func(gen *Generator) {
-				tmpl := template.Must(template.New("error.tmpl").Parse("package {{.PackageName}}"))
-				gen.templates["error.tmpl"] = tmpl
-			},
-			expectError: true,
+			name:          "skip_existing_without_file",
+			skipExisting:  true,
+			preCreate:     false,
+			expectContent: "package testpkg\n",
+			expectOut:     "",
 		},
 		{
-			name:         "missing_main_template",
-			format:       "error",
-			testGenLevel: TestGenNone,
-			setupGen:     func(*Generator) {},
-			expectError:  true,
+			name:          "overwrite_existing_file",
+			skipExisting:  false,
+			preCreate:     true,
+			expectContent: "package testpkg\n",
+			expectOut:     "",
 		},
 	}
 
@@ -542,63 +776,78 @@ func TestGenerateFormat(t *testing.T) {
 			test.name, func(t *testing.T) {
 				t.Parallel()
 
-				// given: a generator with specific test generation level
+				// given: a generator and an optionally pre-created output file
+				var stdout bytes.Buffer
+
 				gen := New()
 				gen.OutputDir = t.TempDir()
-				gen.TestGenLevel = test.testGenLevel
+				gen.SkipExisting = test.skipExisting
+				gen.stdout = &stdout
 				gen.data.PackageName = "testpkg"
-				test.setupGen(gen)
-
-				// when: generating format
-				err := gen.generateFormat(test.format)
+				gen.templates["error.tmpl"] = template.Must(template.New("error.tmpl").Parse("package {{.PackageName}}"))
 
-				// then: error and files should match expectations
-				if test.expectError {
-					assert.Error(t, err)
-				} else {
-					assert.NoError(t, err)
+				outputPath := filepath.Join(gen.OutputDir, "error.go")
 
-					for _, expectedFile := range test.expectFiles {
-						filePath := filepath.Join(gen.OutputDir, expectedFile)
-						_, err := os.Stat(filePath)
-						assert.NoError(t, err, "expected file %s to exist", expectedFile)
-					}
+				if test.preCreate {
+					require.NoError(t, os.WriteFile(outputPath, []byte("package handtuned\n"), filePermissions))
 				}
+
+				// when: generating the file
+				err := gen.generateFile("error.tmpl", "error.go")
+
+				// then: the file should be kept or overwritten
+				require.NoError(t, err)
+
+				content, err := os.ReadFile(outputPath) //nolint:gosec // security is not a concern here
+				require.NoError(t, err)
+				assert.Equal(t, test.expectContent, string(content))
+				assert.Equal(t, test.expectOut, stdout.String())
 			},
 		)
 	}
 }
 
-// TestRun tests the Run method.
-func TestRun(t *testing.T) {
+// TestLoadBuildTags tests the loadBuildTags method.
+func TestLoadBuildTags(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
-		setupGen    func(*Generator)
-		name        string
-		expectError bool
+		expectedTags map[string]string
+		name         string
+		buildTags    string
+		expectError  bool
 	}{
 		{
-			name: "successful_run_with_defaults",
-			setupGen: func(gen *Generator) {
-				gen.OutputDir = t.TempDir()
-				gen.Formats = []string{}
-			},
-			expectError: false,
+			name:         "empty_build_tags_adds_no_tags",
+			buildTags:    "",
+			expectedTags: nil,
+			expectError:  false,
 		},
 		{
-			name: "successful_run_with_formats",
-			setupGen: func(gen *Generator) {
-				gen.OutputDir = t.TempDir()
-				gen.Formats = nil // Will trigger discovery
-			},
-			expectError: false,
+			name:         "single_build_tag",
+			buildTags:    "zap=zap",
+			expectedTags: map[string]string{"zap": "zap"},
+			expectError:  false,
 		},
 		{
-			name: "invalid_target_directory",
-			setupGen: func(gen *Generator) {
-				gen.OutputDir = string([]byte{0})
-			},
+			name:         "multiple_build_tags_with_expressions",
+			buildTags:    "zap=zap,logrus=logrus && !nologrus",
+			expectedTags: map[string]string{"zap": "zap", "logrus": "logrus && !nologrus"},
+			expectError:  false,
+		},
+		{
+			name:        "missing_constraint",
+			buildTags:   "zap",
+			expectError: true,
+		},
+		{
+			name:        "missing_format",
+			buildTags:   "=zap",
+			expectError: true,
+		},
+		{
+			name:        "invalid_constraint",
+			buildTags:   "zap=zap &&",
 			expectError: true,
 		},
 	}
@@ -612,22 +861,1509 @@ func TestRun(t *testing.T) {
 
 				// given: a generator
 				gen := New()
+
+				// when: loading build tags
+				err := gen.loadBuildTags(test.buildTags)
+
+				// then: build tags should match expectation
+				if test.expectError {
+					assert.Error(t, err)
+				} else {
+					require.NoError(t, err)
+					assert.Equal(t, test.expectedTags, gen.BuildTags)
+				}
+			},
+		)
+	}
+}
+
+// TestGenerateFormatBuildTags tests the generateFormat method with build tags.
+func TestGenerateFormatBuildTags(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator with a build tag for the zap format only
+	gen := New()
+	gen.OutputDir = t.TempDir()
+	gen.TestGenLevel = TestGenStrict
+	require.NoError(t, gen.loadBuildTags("zap=zap && !nozap"))
+	require.NoError(t, gen.loadEmbeddedTemplates())
+
+	// when: generating a tagged and an untagged format
+	require.NoError(t, gen.generateFormat("zap"))
+	require.NoError(t, gen.generateFormat("error"))
+
+	// then: the build constraint should be the first line of the tagged files only, and be gofmt stable
+	for _, name := range []string{"zap.go", "zap_test.go"} {
+		content, err := os.ReadFile(filepath.Join(gen.OutputDir, name)) //nolint:gosec // test
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(content), "//go:build zap && !nozap\n\n"), name)
+
+		formatted, err := format.Source(content)
+		require.NoError(t, err)
+		assert.Equal(t, string(formatted), string(content))
+	}
+
+	content, err := os.ReadFile(filepath.Join(gen.OutputDir, "error.go")) //nolint:gosec // test
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "//go:build")
+	assert.Empty(t, gen.data.BuildTag)
+}
+
+func TestGenerateFormatBench(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		bench       bool
+		expectFiles []string
+		absentFiles []string
+	}{
+		{
+			name:        "bench_enabled",
+			bench:       true,
+			expectFiles: []string{"json.go", "json_bench_test.go", "error.go"},
+			absentFiles: []string{"error_bench_test.go"},
+		},
+		{
+			name:        "bench_disabled",
+			bench:       false,
+			expectFiles: []string{"json.go", "error.go"},
+			absentFiles: []string{"json_bench_test.go", "error_bench_test.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: json has a benchmark template and error does not
+				gen := New()
+				gen.OutputDir = t.TempDir()
+				gen.Bench = test.bench
+				require.NoError(t, gen.loadEmbeddedTemplates())
+				require.True(t, gen.hasTemplate("json_bench.tmpl"))
+				require.False(t, gen.hasTemplate("error_bench.tmpl"))
+
+				// when: generating both formats
+				require.NoError(t, gen.generateFormat("json"))
+				require.NoError(t, gen.generateFormat("error"))
+
+				// then: the bench file should be generated only when enabled and the template exists
+				for _, name := range test.expectFiles {
+					assert.FileExists(t, filepath.Join(gen.OutputDir, name))
+				}
+
+				for _, name := range test.absentFiles {
+					assert.NoFileExists(t, filepath.Join(gen.OutputDir, name))
+				}
+			},
+		)
+	}
+}
+
+func TestGenerateFormatFuzz(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		fuzz   bool
+		expect bool
+	}{
+		{
+			name:   "fuzz_enabled",
+			fuzz:   true,
+			expect: true,
+		},
+		{
+			name:   "fuzz_disabled",
+			fuzz:   false,
+			expect: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: json has fuzz targets in its test template
+				gen := New()
+				gen.OutputDir = t.TempDir()
+				gen.TestGenLevel = TestGenStrict
+				gen.data.Fuzz = test.fuzz
+				require.NoError(t, gen.loadEmbeddedTemplates())
+
+				// when: generating the format
+				require.NoError(t, gen.generateFormat("json"))
+
+				// then: the fuzz targets should be generated only when enabled
+				content, err := os.ReadFile(filepath.Join(gen.OutputDir, "json_test.go")) //nolint:gosec // test
+				require.NoError(t, err)
+				assert.Equal(t, test.expect, strings.Contains(string(content), "func FuzzUnmarshalJSON("))
+				assert.Equal(t, test.expect, strings.Contains(string(content), `"unicode/utf8"`))
+			},
+		)
+	}
+}
+
+func TestDiscoverTemplateFormatsSkipsBench(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator with the embedded templates
+	gen := New()
+	require.NoError(t, gen.loadEmbeddedTemplates())
+
+	// when: discovering the formats
+	formats := gen.discoverTemplateFormats()
+
+	// then: benchmark templates should not be formats
+	assert.Contains(t, formats, "json")
+	assert.NotContains(t, formats, "json_bench")
+	assert.NotContains(t, formats, "string_bench")
+	assert.NotContains(t, formats, "example")
+}
+
+// TestGenerateFormat tests the generateFormat method.
+func TestGenerateFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		setupGen     func(*Generator)
+		name         string
+		format       string
+		testGenLevel string
+		expectFiles  []string
+		expectError  bool
+	}{
+		{
+			name:         "none_level_no_test",
+			format:       "error",
+			testGenLevel: TestGenNone,
+			setupGen: func(gen *Generator) {
+				tmpl := template.Must(template.New("error.tmpl").Parse("package {{.PackageName}}"))
+				gen.templates["error.tmpl"] = tmpl
+			},
+			expectError: false,
+			expectFiles: []string{"error.go"},
+		},
+		{
+			name:         "flex_level_with_test_template",
+			format:       "error",
+			testGenLevel: TestGenFlex,
+			setupGen: func(gen *Generator) {
+				tmpl := template.Must(template.New("error.tmpl").Parse("package {{.PackageName}}"))
+				gen.templates["error.tmpl"] = tmpl
+				testTmpl := template.Must(template.New("error_test.tmpl").Parse("package {{.PackageName}}"))
+				gen.templates["error_test.tmpl"] = testTmpl
+			},
+			expectError: false,
+			expectFiles: []string{"error.go", "error_test.go"},
+		},
+		{
+			name:         "flex_level_without_test_template",
+			format:       "error",
+			testGenLevel: TestGenFlex,
+			setupGen: func(gen *Generator) {
+				tmpl := template.Must(template.New("error.tmpl").Parse("package {{.PackageName}}"))
+				gen.templates["error.tmpl"] = tmpl
+			},
+			expectError: false,
+			expectFiles: []string{"error.go"},
+		},
+		{
+			name:         "strict_level_with_test_template",
+			format:       "error",
+			testGenLevel: TestGenStrict,
+			setupGen: func(gen *Generator) {
+				tmpl := template.Must(template.New("error.tmpl").Parse("package {{.PackageName}}"))
+				gen.templates["error.tmpl"] = tmpl
+				testTmpl := template.Must(template.New("error_test.tmpl").Parse("package {{.PackageName}}"))
+				gen.templates["error_test.tmpl"] = testTmpl
+			},
+			expectError: false,
+			expectFiles: []string{"error.go", "error_test.go"},
+		},
+		{
+			name:         "strict_level_without_test_template",
+			format:       "error",
+			testGenLevel: TestGenStrict,
+			setupGen: func(gen *Generator) {
+				tmpl := template.Must(template.New("error.tmpl").Parse("package {{.PackageName}}"))
+				gen.templates["error.tmpl"] = tmpl
+			},
+			expectError: true,
+		},
+		{
+			name:         "missing_main_template",
+			format:       "error",
+			testGenLevel: TestGenNone,
+			setupGen:     func(*Generator) {},
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator with specific test generation level
+				gen := New()
+				gen.OutputDir = t.TempDir()
+				gen.TestGenLevel = test.testGenLevel
+				gen.data.PackageName = "testpkg"
+				test.setupGen(gen)
+
+				// when: generating format
+				err := gen.generateFormat(test.format)
+
+				// then: error and files should match expectations
+				if test.expectError {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+
+					for _, expectedFile := range test.expectFiles {
+						filePath := filepath.Join(gen.OutputDir, expectedFile)
+						_, err := os.Stat(filePath)
+						assert.NoError(t, err, "expected file %s to exist", expectedFile)
+					}
+				}
+			},
+		)
+	}
+}
+
+// TestRun tests the Run method.
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		setupGen    func(*Generator)
+		name        string
+		expectError bool
+	}{
+		{
+			name: "successful_run_with_defaults",
+			setupGen: func(gen *Generator) {
+				gen.OutputDir = t.TempDir()
+				gen.Formats = []string{}
+			},
+			expectError: false,
+		},
+		{
+			name: "successful_run_with_formats",
+			setupGen: func(gen *Generator) {
+				gen.OutputDir = t.TempDir()
+				gen.Formats = nil // Will trigger discovery
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid_target_directory",
+			setupGen: func(gen *Generator) {
+				gen.OutputDir = string([]byte{0})
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator
+				gen := New()
+				test.setupGen(gen)
+
+				// when: running the generator
+				err := gen.Run()
+
+				// then: error should match expectation
+				if test.expectError {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			},
+		)
+	}
+}
+
+// TestRunHeaderFile tests the Run method with a custom header file.
+func TestRunHeaderFile(t *testing.T) {
+	t.Parallel()
+
+	header := "// Code generated by acme-gen; DO NOT EDIT.\n\n// Copyright (c) ACME Corp.\n// Licensed under the ACME License."
+
+	tests := []struct {
+		name      string
+		buildTags map[string]string
+		expected  map[string]string
+	}{
+		{
+			name: "header_at_the_top",
+			expected: map[string]string{
+				"json.go":       header + "\n\npackage errors\n",
+				"json_test.go":  header + "\n\npackage errors\n",
+				"error.go":      header + "\n\npackage errors\n",
+				"error_test.go": header + "\n\npackage errors\n",
+			},
+		},
+		{
+			name:      "header_after_build_tags",
+			buildTags: map[string]string{"json": "with_json"},
+			expected: map[string]string{
+				"json.go":       "//go:build with_json\n\n" + header + "\n\npackage errors\n",
+				"json_test.go":  "//go:build with_json\n\n" + header + "\n\npackage errors\n",
+				"error.go":      header + "\n\npackage errors\n",
+				"error_test.go": header + "\n\npackage errors\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a header file with trailing new lines
+				headerFile := filepath.Join(t.TempDir(), "header.txt")
+				require.NoError(t, os.WriteFile(headerFile, []byte(header+"\n\n"), 0o600))
+
+				gen := New()
+				gen.OutputDir = t.TempDir()
+				gen.Formats = []string{"json", "error"}
+				gen.TestGenLevel = TestGenFlex
+				gen.HeaderFile = headerFile
+				gen.BuildTags = test.buildTags
+
+				// when: running the generator
+				err := gen.Run()
+
+				// then: every generated file should have the header, replacing the default one
+				require.NoError(t, err)
+				assert.Equal(t, header, gen.data.Header)
+
+				entries, err := os.ReadDir(gen.OutputDir)
+				require.NoError(t, err)
+				assert.Len(t, entries, len(test.expected)+2) // compatibility and normalize bench tests
+
+				for _, entry := range entries {
+					content, errR := os.ReadFile(filepath.Join(gen.OutputDir, entry.Name()))
+					require.NoError(t, errR)
+					assert.Contains(t, string(content), header, entry.Name())
+					assert.NotContains(t, string(content), "Code generated by errors_generator", entry.Name())
+				}
+
+				for name, prefix := range test.expected {
+					content, errR := os.ReadFile(filepath.Join(gen.OutputDir, name))
+					require.NoError(t, errR)
+					assert.True(t, strings.HasPrefix(string(content), prefix), name)
+				}
+			},
+		)
+	}
+}
+
+// TestRunHeaderFileMissing tests the Run method with a header file that does not exist.
+func TestRunHeaderFileMissing(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator with a missing header file
+	gen := New()
+	gen.OutputDir = t.TempDir()
+	gen.HeaderFile = filepath.Join(t.TempDir(), "missing.txt")
+
+	// when: running the generator
+	err := gen.Run()
+
+	// then: an error should be returned
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loading header file")
+}
+
+// TestRunInputStdin tests the Run method with a template read from stdin.
+func TestRunInputStdin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		formatName string
+		template   string
+		wantError  string
+	}{
+		{
+			name:       "template_generated_as_format_name",
+			formatName: "custom",
+			template:   "package {{.PackageName}}\n\n// Custom is read from stdin.\nfunc Custom() string { return nilValue }\n",
+		},
+		{
+			name:      "missing_format_name",
+			template:  "package {{.PackageName}}\n",
+			wantError: "input-stdin requires a format name",
+		},
+		{
+			name:       "invalid_template",
+			formatName: "custom",
+			template:   "package {{.PackageName}\n",
+			wantError:  "parsing stdin template custom.tmpl",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator reading a template from stdin, along with an embedded format
+				gen := New()
+				gen.OutputDir = t.TempDir()
+				gen.Formats = []string{"common"}
+				gen.InputStdin = true
+				gen.FormatName = test.formatName
+				gen.stdin = strings.NewReader(test.template)
+
+				// when: running the generator
+				err := gen.Run()
+
+				// then: the stdin template should be generated as <format-name>.go next to the embedded format
+				if test.wantError != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), test.wantError)
+
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, []string{"common", "custom"}, gen.Formats)
+				assert.FileExists(t, filepath.Join(gen.OutputDir, "common.go"))
+
+				content, errR := os.ReadFile(filepath.Join(gen.OutputDir, "custom.go"))
+				require.NoError(t, errR)
+				assert.Contains(t, string(content), "package errors")
+				assert.Contains(t, string(content), "func Custom() string { return nilValue }")
+			},
+		)
+	}
+}
+
+func TestRunWithDoc(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		withDoc bool
+	}{
+		{
+			name:    "doc_file_lists_generated_formats",
+			withDoc: true,
+		},
+		{
+			name:    "no_doc_file_by_default",
+			withDoc: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator with the core formats, except a few
+				gen := New()
+				gen.OutputDir = t.TempDir()
+				gen.Exclude = []string{"join", "xml"}
+				gen.Validate = true
+				gen.data.WithDoc = test.withDoc
+
+				// when: running the generator
+				err := gen.Run()
+
+				// then: doc.go should hold the package documentation instead of attr.go
+				require.NoError(t, err)
+
+				attr, errR := os.ReadFile(filepath.Join(gen.OutputDir, "attr.go"))
+				require.NoError(t, errR)
+
+				if !test.withDoc {
+					assert.NoFileExists(t, filepath.Join(gen.OutputDir, "doc.go"))
+					assert.Contains(t, string(attr), "// Package errors is a drop-in replacement")
+
+					return
+				}
+
+				assert.NotContains(t, string(attr), "// Package errors")
+
+				content, errR := os.ReadFile(filepath.Join(gen.OutputDir, "doc.go"))
+				require.NoError(t, errR)
+				assert.Contains(t, string(content), "// Package errors is a drop-in replacement")
+				assert.Contains(t, string(content), "//   - attr\n//   - common\n//   - error\n")
+				assert.Contains(t, string(content), "//   - json\n")
+				assert.NotContains(t, string(content), "//   - join")
+				assert.NotContains(t, string(content), "//   - xml")
+				assert.True(t, strings.HasSuffix(string(content), "\npackage errors\n"))
+			},
+		)
+	}
+}
+
+func TestRunWithAssertions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		withAssertions bool
+	}{
+		{
+			name:           "assertions_included",
+			withAssertions: true,
+		},
+		{
+			name:           "no_assertions_by_default",
+			withAssertions: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator with the core formats
+				gen := New()
+				gen.OutputDir = t.TempDir()
+				gen.Validate = true
+				gen.data.WithAssertions = test.withAssertions
+
+				// when: running the generator
+				err := gen.Run()
+
+				// then: the formats should assert their interfaces only when enabled
+				require.NoError(t, err)
+
+				jsonContent, errR := os.ReadFile(filepath.Join(gen.OutputDir, "json.go"))
+				require.NoError(t, errR)
+
+				xmlContent, errR := os.ReadFile(filepath.Join(gen.OutputDir, "xml.go"))
+				require.NoError(t, errR)
+
+				stringContent, errR := os.ReadFile(filepath.Join(gen.OutputDir, "string.go"))
+				require.NoError(t, errR)
+
+				assertions := map[string]string{
+					"_ json.Marshaler   = (*StructuredError)(nil)": string(jsonContent),
+					"_ json.Unmarshaler = (*StructuredError)(nil)": string(jsonContent),
+					"_ json.Marshaler   = (*Attr)(nil)":            string(jsonContent),
+					"_ xml.Marshaler = (*StructuredError)(nil)":    string(xmlContent),
+					"_ xml.Marshaler = (*Attr)(nil)":               string(xmlContent),
+					"_ fmt.Stringer = (*Attr)(nil)":                string(stringContent),
+				}
+
+				for assertion, content := range assertions {
+					if test.withAssertions {
+						assert.Contains(t, content, assertion)
+					} else {
+						assert.NotContains(t, content, assertion)
+					}
+				}
+			},
+		)
+	}
+}
+
+func TestRunWithExamples(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		withExamples  bool
+		attrTypeNames bool
+		want          []string
+		wantNot       []string
+	}{
+		{
+			name:         "examples_reference_only_generated_formats",
+			withExamples: true,
+			want: []string{
+				"func ExampleNew()",
+				"func ExampleStructuredError_WithAttrs()",
+				"func ExampleStructuredError_MarshalJSON()",
+				"func ExampleStructuredError_MarshalLogfmt()",
+				"func ExampleStructuredError_MarshalZerologObject()",
+				`"github.com/rs/zerolog"`,
+				`"type":16}`,
+			},
+			wantNot: []string{
+				"MarshalXML",
+				"LogValue",
+				"MarshalLogObject",
+				`"encoding/xml"`,
+				`"log/slog"`,
+				`"go.uber.org/zap"`,
+			},
+		},
+		{
+			name:          "examples_follow_attr_type_names",
+			withExamples:  true,
+			attrTypeNames: true,
+			want:          []string{`"type":"string"}`, `"type":"int"}`},
+			wantNot:       []string{`"type":16}`},
+		},
+		{
+			name:         "no_examples_by_default",
+			withExamples: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator with the core formats, logfmt and zerolog, except xml
+				gen := New()
+				gen.OutputDir = t.TempDir()
+				gen.Formats = append(gen.Formats, "logfmt", "zerolog")
+				gen.Exclude = []string{"xml"}
+				gen.Validate = true
+				gen.data.WithExamples = test.withExamples
+				gen.data.AttrTypeNames = test.attrTypeNames
+
+				// when: running the generator
+				err := gen.Run()
+
+				// then: example_test.go should hold the examples of the generated formats only
+				require.NoError(t, err)
+
+				if !test.withExamples {
+					assert.NoFileExists(t, filepath.Join(gen.OutputDir, "example_test.go"))
+
+					return
+				}
+
+				content, errR := os.ReadFile(filepath.Join(gen.OutputDir, "example_test.go"))
+				require.NoError(t, errR)
+
+				for _, want := range test.want {
+					assert.Contains(t, string(content), want)
+				}
+
+				for _, wantNot := range test.wantNot {
+					assert.NotContains(t, string(content), wantNot)
+				}
+			},
+		)
+	}
+}
+
+func TestRunAttrTypeNames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		attrTypeNames bool
+		want          string
+	}{
+		{
+			name:          "type_names_enabled_by_default",
+			attrTypeNames: true,
+			want:          "attrTypeNameMode = true",
+		},
+		{
+			name:          "type_numbers_by_default",
+			attrTypeNames: false,
+			want:          "attrTypeNameMode = false",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator with the json format
+				gen := New()
+				gen.OutputDir = t.TempDir()
+				gen.Formats = []string{"common", "attr", "json"}
+				gen.Validate = true
+				gen.data.AttrTypeNames = test.attrTypeNames
+
+				// when: running the generator
+				err := gen.Run()
+
+				// then: the default of the attr type JSON mode should follow the flag
+				require.NoError(t, err)
+
+				content, errR := os.ReadFile(filepath.Join(gen.OutputDir, "json.go"))
+				require.NoError(t, errR)
+				assert.Contains(t, string(content), test.want)
+			},
+		)
+	}
+}
+
+func TestRunGoVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		goVersion string
+		want      string
+		notWant   string
+		wantErr   bool
+	}{
+		{
+			name:      "old_go_version_emits_local_cmp_or",
+			goVersion: "1.18",
+			want:      "for _, val := range vals {",
+			notWant:   "cmp.Or(vals...)",
+		},
+		{
+			name:      "new_go_version_uses_std_cmp_or",
+			goVersion: "1.22",
+			want:      "return cmp.Or(vals...)",
+			notWant:   "for _, val := range vals {",
+		},
+		{
+			name:      "go_prefixed_patch_version_uses_std_cmp_or",
+			goVersion: "go1.23.4",
+			want:      "return cmp.Or(vals...)",
+			notWant:   "for _, val := range vals {",
+		},
+		{
+			name:      "invalid_go_version",
+			goVersion: "latest",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator with the core formats, targeting the given Go version
+				gen := New()
+				gen.OutputDir = t.TempDir()
+				gen.Validate = true
+				gen.data.GoVersion = test.goVersion
+
+				// when: running the generator
+				err := gen.Run()
+
+				// then: cmpOr should be local or call cmp.Or depending on the Go version
+				if test.wantErr {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), "invalid go version")
+					assert.NoFileExists(t, filepath.Join(gen.OutputDir, "common.go"))
+
+					return
+				}
+
+				require.NoError(t, err)
+
+				content, errR := os.ReadFile(filepath.Join(gen.OutputDir, "common.go"))
+				require.NoError(t, errR)
+				assert.Contains(t, string(content), test.want)
+				assert.NotContains(t, string(content), test.notWant)
+			},
+		)
+	}
+}
+
+func TestGoVersionAtLeast(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		minimum string
+		version string
+		want    bool
+	}{
+		{name: "same_version", minimum: "1.22", version: "1.22", want: true},
+		{name: "newer_version", minimum: "1.22", version: "1.23", want: true},
+		{name: "newer_patch_version", minimum: "1.22", version: "1.22.5", want: true},
+		{name: "go_prefixed_version", minimum: "1.22", version: "go1.24", want: true},
+		{name: "older_version", minimum: "1.22", version: "1.21.9", want: false},
+		{name: "two_digit_minor_version", minimum: "1.9", version: "1.18", want: true},
+		{name: "empty_version", minimum: "1.22", version: "", want: false},
+		{name: "major_version_only", minimum: "1.22", version: "1", want: false},
+		{name: "other_major_version", minimum: "1.22", version: "2.0", want: false},
+		{name: "non_numeric_minor_version", minimum: "1.22", version: "1.x", want: false},
+		{name: "non_numeric_patch_version", minimum: "1.22", version: "1.22.rc1", want: false},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when: comparing the versions
+				got := goVersionAtLeast(test.minimum, test.version)
+
+				// then: the version should be at least the minimum only when it is valid and not older
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+// TestRunSingleFile tests the Run method combining formats into a single file.
+func TestRunSingleFile(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator combining two formats with overlapping imports
+	gen := New()
+	gen.OutputDir = t.TempDir()
+	gen.Formats = []string{"json", "logfmt"}
+	gen.SingleFile = true
+	gen.SingleFileName = "combined.go"
+	gen.TestGenLevel = TestGenFlex
+
+	// when: running the generator
+	err := gen.Run()
+
+	// then: a single main file should be written, with test files still apart
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(gen.OutputDir, "json.go"))
+	assert.NoFileExists(t, filepath.Join(gen.OutputDir, "logfmt.go"))
+	assert.FileExists(t, filepath.Join(gen.OutputDir, "json_test.go"))
+	assert.FileExists(t, filepath.Join(gen.OutputDir, "logfmt_test.go"))
+
+	content, err := os.ReadFile(filepath.Join(gen.OutputDir, "combined.go"))
+	require.NoError(t, err)
+
+	// then: the file should parse, with the generated header, a single package and a single import block
+	file, err := parser.ParseFile(token.NewFileSet(), "combined.go", content, parser.ParseComments)
+	require.NoError(t, err)
+	assert.Equal(t, "errors", file.Name.Name)
+	assert.Equal(t, 1, strings.Count(string(content), "package errors"))
+	assert.Equal(t, 1, strings.Count(string(content), "// Code generated by errors_generator; DO NOT EDIT."))
+
+	importBlocks := 0
+
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			importBlocks++
+		}
+	}
+
+	assert.Equal(t, 1, importBlocks)
+
+	paths := make(map[string]int)
+	for _, importSpec := range file.Imports {
+		paths[importSpec.Path.Value]++
+	}
+
+	assert.Equal(t, 1, paths[`"strings"`])
+	assert.Equal(t, 1, paths[`"strconv"`])
+
+	// then: the declarations of both formats should be kept
+	assert.Contains(t, string(content), "func (receiver *StructuredError) MarshalJSON()")
+	assert.Contains(t, string(content), "func (receiver *StructuredError) MarshalLogfmt()")
+}
+
+// TestRunSingleFileWithBuildTags tests that build tags cannot be combined into a single file.
+func TestRunSingleFileWithBuildTags(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator combining formats with a build tag
+	gen := New()
+	gen.OutputDir = t.TempDir()
+	gen.Formats = []string{"json"}
+	gen.SingleFile = true
+	gen.BuildTags = map[string]string{"json": "with_json"}
+
+	// when: running the generator
+	err := gen.Run()
+
+	// then: an error should be returned
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "build tags")
+}
+
+// TestRunSplitPackages tests that -split-packages writes every format but the base ones into a package of its own.
+func TestRunSplitPackages(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator splitting the formats of a module
+	moduleDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte("module example.com/app\n"), 0o600))
+
+	gen := New()
+	gen.OutputDir = filepath.Join(moduleDir, "pkg", "errs")
+	gen.Formats = []string{"attr", "common", "error", "string", "json", "logfmt"}
+	gen.SplitPackages = true
+	gen.TestGenLevel = TestGenFlex
+
+	// when: running the generator
+	err := gen.Run()
+
+	// then: the base formats should be in the base package, and the rest in a directory and package of their own
+	require.NoError(t, err)
+
+	tests := []struct {
+		file        string
+		packageName string
+		imports     bool
+	}{
+		{file: "attr.go", packageName: "errors"},
+		{file: "common.go", packageName: "errors"},
+		{file: "error.go", packageName: "errors"},
+		{file: "string.go", packageName: "errors"},
+		{file: filepath.Join("json", "json.go"), packageName: "json", imports: true},
+		{file: filepath.Join("json", "json_test.go"), packageName: "json", imports: true},
+		{file: filepath.Join("logfmt", "logfmt.go"), packageName: "logfmt", imports: true},
+		{file: filepath.Join("logfmt", "logfmt_test.go"), packageName: "logfmt", imports: true},
+	}
+
+	for _, test := range tests {
+		file, errP := parser.ParseFile(token.NewFileSet(), filepath.Join(gen.OutputDir, test.file), nil, parser.ImportsOnly)
+		require.NoError(t, errP, test.file)
+		assert.Equal(t, test.packageName, file.Name.Name, test.file)
+
+		imported := false
+
+		for _, importSpec := range file.Imports {
+			if importSpec.Path.Value == `"example.com/app/pkg/errs"` {
+				imported = importSpec.Name != nil && importSpec.Name.Name == "errors"
+			}
+		}
+
+		assert.Equal(t, test.imports, imported, test.file)
+	}
+
+	assert.NoFileExists(t, filepath.Join(gen.OutputDir, "json.go"))
+	assert.NoFileExists(t, filepath.Join(gen.OutputDir, "logfmt.go"))
+	assert.Equal(t, "errors", gen.data.PackageName)
+}
+
+// TestRunSplitPackagesErrors tests the options -split-packages cannot be used with.
+func TestRunSplitPackagesErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		singleFile  bool
+		expectError string
+	}{
+		{
+			name:        "with_single_file",
+			singleFile:  true,
+			expectError: "split packages cannot be used with a single file",
+		},
+		{
+			name:        "without_go_mod",
+			expectError: "go.mod not found",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator splitting the formats of a directory out of any module
+				gen := New()
+				gen.OutputDir = t.TempDir()
+				gen.Formats = []string{"json"}
+				gen.SplitPackages = true
+				gen.SingleFile = test.singleFile
+
+				// when: running the generator
+				err := gen.Run()
+
+				// then: an error should be returned
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectError)
+			},
+		)
+	}
+}
+
+// TestImportPath tests the importPath function.
+func TestImportPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		goMod       string
+		dir         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "module_root",
+			goMod:    "module example.com/app\n\ngo 1.18\n",
+			expected: "example.com/app",
+		},
+		{
+			name:     "nested_directory",
+			goMod:    "// comment\nmodule example.com/app\n",
+			dir:      filepath.Join("pkg", "errs"),
+			expected: "example.com/app/pkg/errs",
+		},
+		{
+			name:     "quoted_module_path",
+			goMod:    "module \"example.com/app\"\n",
+			dir:      "errs",
+			expected: "example.com/app/errs",
+		},
+		{
+			name:        "missing_module_path",
+			goMod:       "go 1.18\n",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a module with the given go.mod
+				moduleDir := t.TempDir()
+				require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte(test.goMod), 0o600))
+
+				// when: resolving the import path of a directory in it
+				got, err := importPath(filepath.Join(moduleDir, test.dir))
+
+				// then: the module path should be joined with the directory
+				if test.expectError {
+					require.Error(t, err)
+
+					return
+				}
+
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, got)
+			},
+		)
+	}
+}
+
+// TestMergeSources tests the mergeSources function.
+func TestMergeSources(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		sources     []string
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "merges_and_deduplicates_imports",
+			sources: []string{
+				"// header\n\npackage errors\n\nimport (\n\tstderrors \"errors\"\n\t\"fmt\"\n)\n\n" +
+					"var a = fmt.Sprint(stderrors.New(\"a\"))\n",
+				"package errors\n\nimport \"fmt\"\nimport \"github.com/example/pkg\"\n\nvar b = fmt.Sprint(pkg.B)\n",
+			},
+			expected: "// header\n\npackage errors\n\n" +
+				"import (\n\tstderrors \"errors\"\n\t\"fmt\"\n\n\t\"github.com/example/pkg\"\n)\n\n" +
+				"var a = fmt.Sprint(stderrors.New(\"a\"))\n\nvar b = fmt.Sprint(pkg.B)\n",
+		},
+		{
+			name: "keeps_sources_without_imports",
+			sources: []string{
+				"package errors\n\nconst a = 1\n",
+				"package errors\n\nconst b = 2\n",
+			},
+			expected: "package errors\n\nconst a = 1\n\nconst b = 2\n",
+		},
+		{
+			name:        "invalid_source",
+			sources:     []string{"not go"},
+			expectError: true,
+		},
+		{
+			name:        "no_sources",
+			sources:     nil,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: the sources
+				sources := make([][]byte, 0, len(test.sources))
+				for _, source := range test.sources {
+					sources = append(sources, []byte(source))
+				}
+
+				// when: merging them
+				content, err := mergeSources(sources)
+
+				// then: the formatted result should match
+				if test.expectError {
+					assert.Error(t, err)
+
+					return
+				}
+
+				require.NoError(t, err)
+
+				formatted, err := format.Source(content)
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, string(formatted))
+			},
+		)
+	}
+}
+
+// TestRunDryRun tests the Run method in dry-run mode.
+func TestRunDryRun(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		setupGen    func(*Generator)
+		name        string
+		expectOut   []string
+		expectError bool
+	}{
+		{
+			name:     "dry_run_with_defaults",
+			setupGen: func(*Generator) {},
+			expectOut: []string{
+				"error.go",
+				"json.go",
+			},
+			expectError: false,
+		},
+		{
+			name: "dry_run_with_tests",
+			setupGen: func(gen *Generator) {
+				gen.Formats = []string{"error"}
+				gen.TestGenLevel = TestGenStrict
+			},
+			expectOut: []string{
+				"error.go",
+				"error_test.go",
+				"compatibility_test.go",
+				"normalize_bench_test.go",
+			},
+			expectError: false,
+		},
+		{
+			name: "dry_run_with_template_error",
+			setupGen: func(gen *Generator) {
+				gen.InputDir = t.TempDir()
+				err := os.WriteFile(
+					filepath.Join(gen.InputDir, "error.tmpl"),
+					[]byte("{{.NonExistent}}"),
+					filePermissions,
+				)
+				require.NoError(t, err)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator in dry-run mode
+				var stdout bytes.Buffer
+
+				gen := New()
+				gen.OutputDir = filepath.Join(t.TempDir(), "out")
+				gen.DryRun = true
+				gen.stdout = &stdout
 				test.setupGen(gen)
 
 				// when: running the generator
 				err := gen.Run()
 
-				// then: error should match expectation
+				// then: no files should be created
+				assert.NoDirExists(t, gen.OutputDir)
+
+				if test.expectError {
+					assert.Error(t, err)
+
+					return
+				}
+
+				require.NoError(t, err)
+
+				for _, out := range test.expectOut {
+					assert.Contains(t, stdout.String(), "would write: "+filepath.Join(gen.OutputDir, out)+" (")
+				}
+			},
+		)
+	}
+}
+
+// TestRunValidate tests the Run method with validation of the generated files.
+func TestRunValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		userTemplates map[string]string
+		name          string
+		expectErrorIn []string
+		expectFiles   []string
+		validate      bool
+		format        bool
+		expectError   bool
+	}{
+		{
+			name:          "valid_templates",
+			userTemplates: map[string]string{"valid.tmpl": "package {{.PackageName}}\n"},
+			validate:      true,
+			expectError:   false,
+			expectFiles:   []string{"valid.go"},
+		},
+		{
+			name: "invalid_templates",
+			userTemplates: map[string]string{
+				"broken.tmpl":  "package {{.PackageName}}\nfunc (",
+				"invalid.tmpl": "func main() {}\n",
+				"valid.tmpl":   "package {{.PackageName}}\n",
+			},
+			validate:      true,
+			expectError:   true,
+			expectErrorIn: []string{"validating generated files", "broken.go:2:7", "invalid.go:1:1"},
+			expectFiles:   []string{"valid.go"},
+		},
+		{
+			name: "invalid_templates_with_format",
+			userTemplates: map[string]string{
+				"broken.tmpl":  "package {{.PackageName}}\nfunc (",
+				"invalid.tmpl": "func main() {}\n",
+				"valid.tmpl":   "package {{.PackageName}}\n",
+			},
+			validate:      true,
+			format:        true,
+			expectError:   true,
+			expectErrorIn: []string{"validating generated files", "broken.go:2:7", "invalid.go:1:1"},
+			expectFiles:   []string{"valid.go"},
+		},
+		{
+			name:          "invalid_templates_without_validate",
+			userTemplates: map[string]string{"broken.tmpl": "package {{.PackageName}}\nfunc ("},
+			validate:      false,
+			expectError:   false,
+			expectFiles:   []string{"broken.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator with user templates
+				gen := New()
+				gen.InputDir = t.TempDir()
+				gen.OutputDir = t.TempDir()
+				gen.Format = test.format
+				gen.Validate = test.validate
+				gen.Formats = nil
+
+				for name, content := range test.userTemplates {
+					err := os.WriteFile(filepath.Join(gen.InputDir, name), []byte(content), filePermissions)
+					require.NoError(t, err)
+
+					gen.Formats = append(gen.Formats, strings.TrimSuffix(name, ".tmpl"))
+				}
+
+				// when: running the generator
+				err := gen.Run()
+
+				// then: invalid files should be reported and not written
 				if test.expectError {
-					assert.Error(t, err)
+					require.Error(t, err)
+
+					for _, expected := range test.expectErrorIn {
+						assert.Contains(t, err.Error(), expected)
+					}
+
+					assert.NoFileExists(t, filepath.Join(gen.OutputDir, "broken.go"))
 				} else {
-					assert.NoError(t, err)
+					require.NoError(t, err)
+				}
+
+				for _, file := range test.expectFiles {
+					assert.FileExists(t, filepath.Join(gen.OutputDir, file))
 				}
 			},
 		)
 	}
 }
 
+// TestPrintVersion tests the -version flag and the printVersion method.
+func TestPrintVersion(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator with the version flag set
+	var (
+		options cliOptions
+		stdout  bytes.Buffer
+		usage   bytes.Buffer
+	)
+
+	gen := New()
+	gen.stdout = &stdout
+
+	flagSet := gen.flagSet(&options, flag.ContinueOnError)
+	flagSet.SetOutput(&usage)
+	require.NoError(t, flagSet.Parse([]string{"-version"}))
+
+	// when: printing the version
+	err := gen.printVersion()
+
+	// then: the version should be printed without loading templates
+	require.NoError(t, err)
+	assert.True(t, options.version)
+	assert.Equal(t, "errors_generator v"+Version+"\n", stdout.String())
+	assert.Empty(t, gen.templates)
+
+	flagSet.Usage()
+	assert.Contains(t, usage.String(), "-version")
+}
+
+// TestListFormats tests the listFormats method.
+func TestListFormats(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator with a user template directory containing a custom format
+	var stdout bytes.Buffer
+
+	gen := New()
+	gen.InputDir = t.TempDir()
+	gen.stdout = &stdout
+
+	err := os.WriteFile(filepath.Join(gen.InputDir, "custom.tmpl"), []byte("package {{.PackageName}}"), filePermissions)
+	require.NoError(t, err)
+
+	// when: listing the formats
+	err = gen.listFormats()
+
+	// then: embedded and custom formats should be listed, sorted, with their test template status
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	assert.Contains(t, lines, "custom\twithout test")
+	assert.Contains(t, lines, "json\twith test")
+	assert.NotContains(t, lines, "compatibility_test\twith test")
+	assert.True(t, sort.StringsAreSorted(lines))
+}
+
+// TestTemplateFuncs tests the functions available to user templates.
+func TestTemplateFuncs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "upper",
+			template: `{{upper .PackageName}}`,
+			expected: "MYPKG",
+		},
+		{
+			name:     "lower",
+			template: `{{lower "MyPkg"}}`,
+			expected: "mypkg",
+		},
+		{
+			name:     "title",
+			template: `{{title "my logger_name-v2"}}`,
+			expected: "My Logger_Name-V2",
+		},
+		{
+			name:     "trim_prefix_in_pipeline",
+			template: `{{.PackageName | trimPrefix "my"}}`,
+			expected: "pkg",
+		},
+		{
+			name:     "trim_suffix_in_pipeline",
+			template: `{{.PackageName | trimSuffix "pkg" | title}}`,
+			expected: "My",
+		},
+		{
+			name:     "replace",
+			template: `{{replace "pkg" "logger" .PackageName}}`,
+			expected: "mylogger",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a user template using a template function
+				gen := New()
+				gen.InputDir = t.TempDir()
+				gen.OutputDir = t.TempDir()
+				gen.Format = false
+				gen.data.PackageName = "mypkg"
+
+				err := os.WriteFile(filepath.Join(gen.InputDir, "funcs.tmpl"), []byte(test.template), filePermissions)
+				require.NoError(t, err)
+
+				err = gen.loadUserTemplates(gen.InputDir)
+				require.NoError(t, err)
+
+				// when: generating the file
+				err = gen.generateFile("funcs.tmpl", "funcs.go")
+
+				// then: the function should be applied
+				require.NoError(t, err)
+
+				content, err := os.ReadFile(filepath.Join(gen.OutputDir, "funcs.go")) //nolint:gosec // test
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, string(content))
+			},
+		)
+	}
+}
+
 // TestExportTemplates tests the exportTemplates method.
 func TestExportTemplates(t *testing.T) {
 	t.Parallel()
@@ -834,3 +2570,315 @@ func TestExportTemplates(t *testing.T) {
 		)
 	}
 }
+
+func TestScaffoldFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		format    string
+		existing  string
+		wantError string
+		noInput   bool
+	}{
+		{
+			name:   "skeletons_written_to_input_dir",
+			format: "my_format",
+		},
+		{
+			name:      "missing_input_dir",
+			format:    "my_format",
+			noInput:   true,
+			wantError: "scaffold-format requires an input directory",
+		},
+		{
+			name:      "invalid_format_name",
+			format:    "my-format",
+			wantError: "invalid format name: my-format",
+		},
+		{
+			name:      "existing_template_not_overwritten",
+			format:    "my_format",
+			existing:  "my_format_test.tmpl",
+			wantError: "template my_format_test.tmpl already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator scaffolding a new format into an input directory
+				gen := New()
+				gen.ScaffoldFormat = test.format
+
+				if !test.noInput {
+					gen.InputDir = filepath.Join(t.TempDir(), "templates")
+				}
+
+				if test.existing != "" {
+					require.NoError(t, os.MkdirAll(gen.InputDir, folderPermissions))
+					require.NoError(
+						t,
+						os.WriteFile(filepath.Join(gen.InputDir, test.existing), []byte("custom"), filePermissions),
+					)
+				}
+
+				// when: scaffolding the format
+				err := gen.scaffoldFormat()
+
+				// then: both skeletons should be written with the format name in place of the placeholders
+				if test.wantError != "" {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), test.wantError)
+
+					if test.existing != "" {
+						content, errR := os.ReadFile(filepath.Join(gen.InputDir, test.existing))
+						require.NoError(t, errR)
+						assert.Equal(t, "custom", string(content))
+						assert.NoFileExists(t, filepath.Join(gen.InputDir, "my_format.tmpl"))
+					}
+
+					return
+				}
+
+				require.NoError(t, err)
+
+				content, errR := os.ReadFile(filepath.Join(gen.InputDir, "my_format.tmpl"))
+				require.NoError(t, errR)
+				assert.Contains(t, string(content), "package {{.PackageName}}")
+				assert.Contains(t, string(content), "//   - .PackageName:")
+				assert.Contains(t, string(content), "//   - .BuildTag:")
+				assert.Contains(t, string(content), "func (receiver *StructuredError) MarshalMyFormat() ([]byte, error) {")
+				assert.Contains(t, string(content), "into the my_format format")
+
+				testContent, errR := os.ReadFile(filepath.Join(gen.InputDir, "my_format_test.tmpl"))
+				require.NoError(t, errR)
+				assert.Contains(t, string(testContent), "func TestStructuredErrorMarshalMyFormat(t *testing.T) {")
+			},
+		)
+	}
+}
+
+func TestRunScaffoldedFormat(t *testing.T) {
+	t.Parallel()
+
+	// given: a scaffolded format in the input directory
+	gen := New()
+	gen.InputDir = t.TempDir()
+	gen.OutputDir = t.TempDir()
+	gen.ScaffoldFormat = "custom"
+	gen.Validate = true
+	gen.TestGenLevel = TestGenStrict
+	require.NoError(t, gen.scaffoldFormat())
+
+	gen.Formats = append(gen.Formats, "custom")
+
+	// when: generating the scaffolded format along with the core formats
+	err := gen.Run()
+
+	// then: the skeletons should render into valid Go files
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(gen.OutputDir, "custom.go"))
+	assert.FileExists(t, filepath.Join(gen.OutputDir, "custom_test.go"))
+}
+
+func TestRegenerate(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator that already generated a format from a user template
+	var stdout bytes.Buffer
+
+	gen := New()
+	gen.InputDir = t.TempDir()
+	gen.OutputDir = t.TempDir()
+	gen.Formats = []string{"custom", "error"}
+	gen.stdout = &stdout
+
+	templatePath := filepath.Join(gen.InputDir, "custom.tmpl")
+	require.NoError(t, os.WriteFile(templatePath, []byte("package {{.PackageName}}\n\nconst Version = 1\n"), 0o600))
+	require.NoError(t, gen.Run())
+
+	outputPath := filepath.Join(gen.OutputDir, "custom.go")
+
+	// when: the template changes and the regeneration callback is invoked
+	require.NoError(t, os.WriteFile(templatePath, []byte("package {{.PackageName}}\n\nconst Version = 2\n"), 0o600))
+	require.NoError(t, gen.regenerate([]string{"custom.tmpl"}))
+
+	// then: only the changed format is regenerated and printed
+	content, err := os.ReadFile(outputPath) //nolint:gosec // test
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "const Version = 2")
+	assert.Equal(t, "regenerated: custom\n", stdout.String())
+
+	// when: the template no longer parses
+	stdout.Reset()
+	require.NoError(t, os.WriteFile(templatePath, []byte("package {{.PackageName"), 0o600))
+	err = gen.regenerate([]string{"custom.tmpl"})
+
+	// then: an error is returned and the generated file is kept
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parsing user template custom.tmpl")
+	assert.Empty(t, stdout.String())
+
+	content, err = os.ReadFile(outputPath) //nolint:gosec // test
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "const Version = 2")
+}
+
+func TestAffectedFormats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		changed       []string
+		testGenLevel  string
+		expectFormats []string
+		expectFiles   []string
+	}{
+		{
+			name:          "main_test_and_bench_templates",
+			changed:       []string{"string_bench.tmpl", "json_test.tmpl", "error.tmpl", "json.tmpl"},
+			testGenLevel:  TestGenNone,
+			expectFormats: []string{"error", "json", "string"},
+		},
+		{
+			name:         "formats_not_generated",
+			changed:      []string{"zap.tmpl", "notes.tmpl"},
+			testGenLevel: TestGenNone,
+		},
+		{
+			name:         "test_files_without_tests",
+			changed:      []string{"compatibility_test.tmpl"},
+			testGenLevel: TestGenNone,
+		},
+		{
+			name:         "test_files_with_tests",
+			changed:      []string{"compatibility_test.tmpl", "normalize_bench_test.tmpl"},
+			testGenLevel: TestGenFlex,
+			expectFiles:  []string{"compatibility_test", "normalize_bench_test"},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given: a generator with its default formats
+				gen := New()
+				gen.TestGenLevel = test.testGenLevel
+
+				// when: resolving the formats affected by the changed templates
+				formats, files := gen.affectedFormats(test.changed)
+
+				// then: the formats keep the generator order
+				assert.Equal(t, test.expectFormats, formats)
+				assert.Equal(t, test.expectFiles, files)
+			},
+		)
+	}
+}
+
+func TestChangedTemplates(t *testing.T) {
+	t.Parallel()
+
+	// given: two snapshots with a modified, an added, a removed and an unchanged template
+	now := time.Now()
+	previous := map[string]templateState{
+		"json.tmpl":   {modTime: now, size: 10},
+		"error.tmpl":  {modTime: now, size: 10},
+		"string.tmpl": {modTime: now, size: 10},
+	}
+	current := map[string]templateState{
+		"json.tmpl":   {modTime: now.Add(time.Second), size: 10},
+		"error.tmpl":  {modTime: now, size: 10},
+		"custom.tmpl": {modTime: now, size: 5},
+	}
+
+	// when: comparing them
+	changed := changedTemplates(previous, current)
+
+	// then: every change is reported, sorted
+	assert.Equal(t, []string{"custom.tmpl", "json.tmpl", "string.tmpl"}, changed)
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator watching its input directory with a short interval
+	var stdout syncBuffer
+
+	gen := New()
+	gen.InputDir = t.TempDir()
+	gen.OutputDir = t.TempDir()
+	gen.Formats = []string{"custom"}
+	gen.WatchInterval = 10 * time.Millisecond
+	gen.stdout = &stdout
+
+	templatePath := filepath.Join(gen.InputDir, "custom.tmpl")
+	require.NoError(t, os.WriteFile(templatePath, []byte("package {{.PackageName}}\n\nconst Version = 1\n"), 0o600))
+	require.NoError(t, gen.Run())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() { done <- gen.watch(ctx) }()
+
+	require.Eventually(
+		t, func() bool { return strings.Contains(stdout.String(), "watching: ") }, 5*time.Second, time.Millisecond,
+	)
+
+	// when: the template changes
+	require.NoError(t, os.WriteFile(templatePath, []byte("package {{.PackageName}}\n\nconst Version = 22\n"), 0o600))
+
+	// then: the format is regenerated until the context is canceled
+	require.Eventually(
+		t, func() bool { return strings.Contains(stdout.String(), "regenerated: custom") }, 5*time.Second, time.Millisecond,
+	)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	content, err := os.ReadFile(filepath.Join(gen.OutputDir, "custom.go")) //nolint:gosec // test
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "const Version = 22")
+}
+
+func TestWatchWithoutInputDir(t *testing.T) {
+	t.Parallel()
+
+	// given: a generator without an input directory
+	gen := New()
+
+	// when: watching
+	err := gen.watch(context.Background())
+
+	// then: an error is returned
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "watch requires an input directory")
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use, to read the output of watch while it runs.
+type syncBuffer struct {
+	buffer bytes.Buffer
+	mutex  sync.Mutex
+}
+
+func (receiver *syncBuffer) Write(p []byte) (int, error) {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	return receiver.buffer.Write(p) //nolint:wrapcheck // bytes.Buffer never fails
+}
+
+func (receiver *syncBuffer) String() string {
+	receiver.mutex.Lock()
+	defer receiver.mutex.Unlock()
+
+	return receiver.buffer.String()
+}
@@ -0,0 +1,377 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+type (
+	// cborError is the stable CBOR representation of a StructuredError.
+	cborError struct {
+		Message    string       `cbor:"message"`
+		Code       string       `cbor:"code,omitempty"`
+		HTTPStatus int          `cbor:"http_status,omitempty"`
+		Tags       []string     `cbor:"tags,omitempty"`
+		Attrs      []cborAttr   `cbor:"attrs,omitempty"`
+		Errors     []*cborError `cbor:"errors,omitempty"`
+		Stack      []byte       `cbor:"stack,omitempty"`
+	}
+
+	// cborAttr is the CBOR representation of an Attr.
+	// Value keeps the encoded value, so it can be decoded into the concrete type given by Type.
+	cborAttr struct {
+		Value cbor.RawMessage `cbor:"value"`
+		Key   string          `cbor:"key"`
+		Type  Type            `cbor:"type"`
+	}
+)
+
+var (
+	// ErrMarshalCBOR is returned when marshaling to CBOR fails.
+	ErrMarshalCBOR = New("failed to marshal CBOR")
+
+	// ErrUnmarshalCBOR is returned when unmarshaling from CBOR fails.
+	ErrUnmarshalCBOR = New("failed to unmarshal CBOR")
+)
+
+//nolint:gochecknoglobals // needed to build the encoding mode only once
+var (
+	cborEncModeOnce sync.Once
+	cborEncMode     cbor.EncMode
+	errCBOREncMode  error
+)
+
+//nolint:errcheck // this is for interface assertion
+var (
+	_ cbor.Marshaler   = (*StructuredError)(nil)
+	_ cbor.Unmarshaler = (*StructuredError)(nil)
+)
+
+// MarshalCBOR implements cbor.Marshaler.
+//
+// It marshals the StructuredError into a canonical CBOR map (RFC 7049 canonical form, sorted keys)
+// with the following keys:
+//   - message
+//   - code
+//   - tags
+//   - attrs
+//   - errors
+//   - stack.
+//
+// Attrs are encoded as {"value","key","type"} maps, with the value encoded with its concrete type,
+// so UnmarshalCBOR restores an Int64Type Attr as an int64 and not as a float64.
+// Time values are encoded as RFC 3339 strings with nanosecond precision.
+// Errors that are not a *StructuredError are encoded with their Error() message only.
+//
+// If the receiver is nil, it will have a single "message" key with the value nilValue.
+func (receiver *StructuredError) MarshalCBOR() ([]byte, error) {
+	structured, err := receiver.asCBOR()
+	if err != nil {
+		return nil, JoinIf(err, ErrMarshalCBOR)
+	}
+
+	encMode, err := cborEncoder()
+	if err != nil {
+		return nil, JoinIf(err, ErrMarshalCBOR)
+	}
+
+	data, err := encMode.Marshal(structured)
+	if err != nil {
+		return nil, JoinIf(err, ErrMarshalCBOR)
+	}
+
+	return data, nil
+}
+
+// UnmarshalCBOR implements cbor.Unmarshaler.
+//
+// It takes a byte slice produced by MarshalCBOR and unmarshals it into the StructuredError.
+// Attr values are decoded into the concrete type given by their Type, AnyType values are
+// decoded as the cbor package does for an any value.
+func (receiver *StructuredError) UnmarshalCBOR(data []byte) error {
+	var structured cborError
+
+	err := cbor.Unmarshal(data, &structured)
+	if err != nil {
+		return JoinIf(err, ErrUnmarshalCBOR)
+	}
+
+	err = structured.fillStructuredError(receiver)
+	if err != nil {
+		return JoinIf(err, ErrUnmarshalCBOR)
+	}
+
+	return nil
+}
+
+// asCBOR is the actual implementation for MarshalCBOR.
+func (receiver *StructuredError) asCBOR() (*cborError, error) {
+	if receiver == nil {
+		return &cborError{Message: nilValue}, nil
+	}
+
+	structured := &cborError{
+		Message:    trimmedMessage(receiver.Message),
+		Code:       receiver.Code,
+		HTTPStatus: receiver.HTTPStatus,
+		Tags:       receiver.Tags,
+		Stack:      receiver.Stack,
+	}
+
+	if len(receiver.Attrs) > zero {
+		attrs, err := attrsToCBOR(withoutOmittedAttrs(receiver.Attrs))
+		if err != nil {
+			return nil, err
+		}
+
+		structured.Attrs = attrs
+	}
+
+	if len(receiver.Errors) > zero {
+		normalized := receiver.normalizedErrors()
+
+		structured.Errors = make([]*cborError, zero, len(normalized))
+
+		for _, err := range normalized {
+			_structured, errM := errorToCBOR(err)
+			if errM != nil {
+				return nil, errM
+			}
+
+			structured.Errors = append(structured.Errors, _structured)
+		}
+	}
+
+	return structured, nil
+}
+
+// asCBOR converts the Attr into a cborAttr, encoding its value with its concrete type.
+// If the Attr is sensitive, the value is "[REDACTED]".
+// LogValuers are encoded as the string of their resolved slog.Value, and complex numbers as their string form.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) asCBOR() (cborAttr, error) {
+	receiver = receiver.redacted().resolved()
+
+	attr := cborAttr{Key: receiver.Key, Type: receiver.Type}
+
+	value := receiver.Value
+
+	if receiver.Type == ObjectType {
+		attrs, err := attrsToCBOR(receiver.Value.([]Attr))
+		if err != nil {
+			return attr, err
+		}
+
+		value = attrs
+	}
+
+	if receiver.Type == Complex128Type {
+		value = complexToString(receiver.Value.(complex128))
+	}
+
+	encMode, err := cborEncoder()
+	if err != nil {
+		return attr, err
+	}
+
+	data, err := encMode.Marshal(value)
+	if err != nil {
+		return attr, err //nolint:wrapcheck // wrapped by MarshalCBOR
+	}
+
+	attr.Value = data
+
+	return attr, nil
+}
+
+// attr converts the cborAttr back into an Attr, decoding its value into the concrete type given by Type.
+func (receiver *cborAttr) attr() (Attr, error) {
+	attr := Attr{Key: receiver.Key, Type: receiver.Type}
+
+	var err error
+
+	switch receiver.Type {
+	case ObjectType:
+		var attrs []cborAttr
+
+		attrs, err = cborToValue[[]cborAttr](receiver.Value)
+		if err == nil {
+			attr.Value, err = cborToAttrs(attrs)
+		}
+	case BoolType:
+		attr.Value, err = cborToValue[bool](receiver.Value)
+	case BoolsType:
+		attr.Value, err = cborToValue[[]bool](receiver.Value)
+	case TimeType:
+		attr.Value, err = cborToValue[time.Time](receiver.Value)
+	case TimesType:
+		attr.Value, err = cborToValue[[]time.Time](receiver.Value)
+	case DurationType:
+		attr.Value, err = cborToValue[time.Duration](receiver.Value)
+	case DurationsType:
+		attr.Value, err = cborToValue[[]time.Duration](receiver.Value)
+	case IntType:
+		attr.Value, err = cborToValue[int](receiver.Value)
+	case IntsType:
+		attr.Value, err = cborToValue[[]int](receiver.Value)
+	case Int64Type:
+		attr.Value, err = cborToValue[int64](receiver.Value)
+	case Int64sType:
+		attr.Value, err = cborToValue[[]int64](receiver.Value)
+	case Uint64Type:
+		attr.Value, err = cborToValue[uint64](receiver.Value)
+	case Uint64sType:
+		attr.Value, err = cborToValue[[]uint64](receiver.Value)
+	case Float64Type:
+		attr.Value, err = cborToValue[float64](receiver.Value)
+	case Float64sType:
+		attr.Value, err = cborToValue[[]float64](receiver.Value)
+	case StringType:
+		attr.Value, err = cborToValue[string](receiver.Value)
+	case StringsType:
+		attr.Value, err = cborToValue[[]string](receiver.Value)
+	case IPType:
+		attr.Value, err = cborToValue[net.IP](receiver.Value)
+	case URLType:
+		attr.Value, err = cborToValue[*url.URL](receiver.Value)
+	case JSONRawType:
+		attr.Value, err = cborToValue[json.RawMessage](receiver.Value)
+	case LogValuerType:
+		attr.Type = StringType
+		attr.Value, err = cborToValue[string](receiver.Value)
+	case RuneType:
+		attr.Value, err = cborToValue[rune](receiver.Value)
+	case Complex128Type:
+		var value string
+
+		value, err = cborToValue[string](receiver.Value)
+		if err == nil {
+			attr.Value, err = strconv.ParseComplex(value, complexBitSize)
+		}
+	default:
+		attr.Value, err = cborToValue[any](receiver.Value)
+	}
+
+	return attr, err
+}
+
+// fillStructuredError takes a cborError and fills a StructuredError with the unmarshalled data.
+func (receiver *cborError) fillStructuredError(structured *StructuredError) error {
+	structured.Message = receiver.Message
+	structured.Code = receiver.Code
+	structured.HTTPStatus = receiver.HTTPStatus
+	structured.Tags = receiver.Tags
+	structured.Stack = receiver.Stack
+
+	if len(receiver.Attrs) > zero {
+		attrs, err := cborToAttrs(receiver.Attrs)
+		if err != nil {
+			return err
+		}
+
+		structured.Attrs = attrs
+	}
+
+	if len(receiver.Errors) > zero {
+		structured.Errors = make([]error, zero, len(receiver.Errors))
+
+		for _, err := range receiver.Errors {
+			_structured := &StructuredError{}
+
+			errF := err.fillStructuredError(_structured)
+			if errF != nil {
+				return errF
+			}
+
+			structured.Errors = append(structured.Errors, _structured)
+		}
+	}
+
+	return nil
+}
+
+// errorToCBOR converts an error into a cborError.
+//
+// If the error is nil, the cborError has the message nilValue.
+//
+// If the error is a *StructuredError, it converts the *StructuredError.
+//
+// If the error is not a *StructuredError, the cborError has the error's Error() message,
+// or nilValue if the message is empty.
+func errorToCBOR(err error) (*cborError, error) {
+	var value *StructuredError
+	switch {
+	case err == nil:
+		return &cborError{Message: nilValue}, nil
+	case stderrors.As(err, &value):
+		return value.asCBOR()
+	default:
+		errStr := strings.TrimSpace(err.Error())
+
+		return &cborError{Message: cmpOr(errStr, nilValue)}, nil
+	}
+}
+
+// attrsToCBOR converts a slice of Attr into a slice of cborAttr.
+func attrsToCBOR(attrs []Attr) ([]cborAttr, error) {
+	result := make([]cborAttr, zero, len(attrs))
+
+	for index := range attrs {
+		attr, err := attrs[index].asCBOR()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, attr)
+	}
+
+	return result, nil
+}
+
+// cborToAttrs converts a slice of cborAttr back into a slice of Attr.
+func cborToAttrs(attrs []cborAttr) ([]Attr, error) {
+	result := make([]Attr, zero, len(attrs))
+
+	for index := range attrs {
+		attr, err := attrs[index].attr()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, attr)
+	}
+
+	return result, nil
+}
+
+// cborToValue decodes a CBOR encoded value into a value of type T.
+func cborToValue[T any](data []byte) (T, error) {
+	var value T
+
+	err := cbor.Unmarshal(data, &value)
+
+	return value, err //nolint:wrapcheck // wrapped by UnmarshalCBOR
+}
+
+// cborEncoder returns the canonical encoding mode used by MarshalCBOR, building it on the first call.
+func cborEncoder() (cbor.EncMode, error) {
+	cborEncModeOnce.Do(
+		func() {
+			options := cbor.CanonicalEncOptions()
+			options.Time = cbor.TimeRFC3339Nano
+
+			cborEncMode, errCBOREncMode = options.EncMode()
+		},
+	)
+
+	return cborEncMode, errCBOREncMode //nolint:wrapcheck // wrapped by MarshalCBOR
+}
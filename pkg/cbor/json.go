@@ -0,0 +1,899 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// unmarshalJSONAttrs accepts attrs in both the array and the object form.
+	unmarshalJSONAttrs []Attr
+
+	// unmarshalJSONStack accepts the stack in both the base64 and the array of lines form.
+	unmarshalJSONStack []byte
+
+	// jsonWriter is the buffer JSON is written to. When it has a destination writer, as in WriteJSON,
+	// the buffer is flushed to it once it holds jsonFlushSize bytes, so the whole document is never in memory.
+	jsonWriter struct {
+		*bytes.Buffer
+		destination io.Writer
+		err         error
+	}
+
+	// StackMode is how MarshalJSON emits the stack.
+	StackMode uint8
+
+	// marshalJSONAttr has the fields of Attr without its methods, to avoid MarshalJSON recursion.
+	marshalJSONAttr Attr
+
+	// marshalJSONNamedAttr has the fields of Attr with the type emitted as its name, see SetAttrTypeNameMode.
+	marshalJSONNamedAttr struct {
+		Value any    `json:"value"`
+		Key   string `json:"key"`
+		Type  string `json:"type"`
+	}
+
+	// unmarshalJSONAttr keeps the type and the value of an attr raw, so they are validated before building the Attr.
+	unmarshalJSONAttr struct {
+		Value json.RawMessage `json:"value"`
+		Key   string          `json:"key"`
+		Type  json.RawMessage `json:"type"`
+	}
+
+	unmarshalJSONError struct {
+		Message    string                `json:"message,omitempty"`
+		Code       string                `json:"code,omitempty"`
+		HTTPStatus int                   `json:"http_status,omitempty"`
+		Attrs      unmarshalJSONAttrs    `json:"attrs,omitempty"`
+		Errors     []*unmarshalJSONError `json:"errors,omitempty"`
+		Tags       []string              `json:"tags,omitempty"`
+		Stack      unmarshalJSONStack    `json:"stack,omitempty"`
+		Frames     []StackFrame          `json:"frames,omitempty"`
+		Caller     string                `json:"caller,omitempty"`
+		Joined     bool                  `json:"joined,omitempty"`
+	}
+)
+
+// StackMode constants define how MarshalJSON emits the stack.
+const (
+	// StackAsBase64 emits the stack as a base64 encoded string.
+	StackAsBase64 StackMode = iota
+	// StackAsLines emits the stack as an array of strings, one per line, readable in log viewers.
+	StackAsLines
+)
+
+var (
+	// ErrUnmarshalJSON is returned when unmarshaling fails.
+	ErrUnmarshalJSON = New("failed to unmarshal JSON")
+)
+
+const (
+	jsonNull      = "null"
+	typeBitSize   = 8
+	jsonFlushSize = 4 << ten
+)
+
+//nolint:gochecknoglobals // needed to avoid use of specific instance of StructuredError
+var (
+	attrObjectMode   = false
+	attrTypeNameMode = false
+	stackJSONMode    = StackAsBase64
+
+	// attrTypeNames holds the name of every Type, indexed by Type.
+	attrTypeNames = [...]string{
+		AnyType:        "any",
+		ObjectType:     "object",
+		BoolType:       "bool",
+		BoolsType:      "bools",
+		TimeType:       "time",
+		TimesType:      "times",
+		DurationType:   "duration",
+		DurationsType:  "durations",
+		IntType:        "int",
+		IntsType:       "ints",
+		Int64Type:      "int64",
+		Int64sType:     "int64s",
+		Uint64Type:     "uint64",
+		Uint64sType:    "uint64s",
+		Float64Type:    "float64",
+		Float64sType:   "float64s",
+		StringType:     "string",
+		StringsType:    "strings",
+		IPType:         "ip",
+		URLType:        "url",
+		JSONRawType:    "json_raw",
+		LogValuerType:  "log_valuer",
+		RuneType:       "rune",
+		Complex128Type: "complex128",
+	}
+
+	jsonBufferPool = sync.Pool{
+		New: func() any {
+			return new(bytes.Buffer)
+		},
+	}
+)
+
+// AttrObjectMode reports whether MarshalJSON emits attrs as a JSON object.
+func AttrObjectMode() bool {
+	return attrObjectMode
+}
+
+// SetAttrObjectMode sets how MarshalJSON emits attrs.
+//
+// By default, attrs are emitted as an array of {"value","key","type"} objects.
+// When enabled, attrs are emitted as a flat object keyed by Attr.Key, like {"request_id":"123","count":42},
+// object attrs are emitted as nested objects, and duplicate keys are last-write-wins.
+//
+// The zap, zerolog, logrus and slog marshalers always emit attrs as an object.
+//
+// SetAttrObjectMode is not thread-safe. It should be called before any
+// StructuredError is marshaled.
+func SetAttrObjectMode(enabled bool) {
+	attrObjectMode = enabled
+}
+
+// AttrTypeNameMode reports whether MarshalJSON emits the type of attrs as its name.
+func AttrTypeNameMode() bool {
+	return attrTypeNameMode
+}
+
+// SetAttrTypeNameMode sets how MarshalJSON emits the type of attrs.
+//
+// By default, the type is emitted as its integer value, like {"value":"123","key":"request_id","type":16}.
+// When enabled, the type is emitted as its name, like {"value":"123","key":"request_id","type":"string"},
+// so stored errors do not depend on the numbering of Type. UnmarshalJSON accepts both forms,
+// and types it does not know are unmarshaled as AnyType attrs.
+//
+// The default can be changed with the -attr-type-names flag of the generator.
+//
+// SetAttrTypeNameMode is not thread-safe. It should be called before any
+// StructuredError is marshaled.
+func SetAttrTypeNameMode(enabled bool) {
+	attrTypeNameMode = enabled
+}
+
+// StackJSONMode returns how MarshalJSON emits the stack.
+func StackJSONMode() StackMode {
+	return stackJSONMode
+}
+
+// SetStackJSONMode sets how MarshalJSON emits the stack.
+//
+// By default, the stack is emitted as a base64 encoded string, with StackAsBase64.
+// With StackAsLines, the stack is emitted as an array of strings, one per line, like the slog marshaler does.
+// UnmarshalJSON accepts both forms, joining the lines back with newlines.
+//
+// SetStackJSONMode is not thread-safe. It should be called before any
+// StructuredError is marshaled.
+func SetStackJSONMode(mode StackMode) {
+	stackJSONMode = mode
+}
+
+// MarshalJSON marshals the Attr into a {"value","key","type"} object.
+// If the Attr is sensitive, the value is "[REDACTED]".
+func (receiver *Attr) MarshalJSON() ([]byte, error) {
+	attr := *receiver.redacted()
+	attr.Value = attrValueToJSON(&attr)
+
+	if attrTypeNameMode {
+		return json.Marshal(&marshalJSONNamedAttr{ //nolint:wrapcheck // plain encoding/json output
+			Value: attr.Value,
+			Key:   attr.Key,
+			Type:  attrTypeName(attr.Type),
+		})
+	}
+
+	return json.Marshal((*marshalJSONAttr)(&attr)) //nolint:wrapcheck // plain encoding/json output
+}
+
+// attrTypeName returns the name of the given Type, or its integer value if it has no name.
+func attrTypeName(attrType Type) string {
+	if int(attrType) < len(attrTypeNames) {
+		return attrTypeNames[attrType]
+	}
+
+	return strconv.FormatUint(uint64(attrType), ten)
+}
+
+// parseJSONAttrType returns the Type of the given JSON value, either its integer value or its name.
+// It returns false if the value is not a known Type.
+func parseJSONAttrType(data []byte) (Type, bool) {
+	var name string
+
+	if json.Unmarshal(data, &name) == nil {
+		for attrType, attrTypeName := range attrTypeNames {
+			if attrTypeName == name {
+				return Type(attrType), true
+			}
+		}
+
+		return AnyType, false
+	}
+
+	attrType, err := strconv.ParseUint(string(data), ten, typeBitSize)
+	if err != nil || Type(attrType) > Complex128Type {
+		return AnyType, false
+	}
+
+	return Type(attrType), true
+}
+
+// attrValueToJSON returns the value of the given Attr as it is passed to json.Marshal.
+// IPs and URLs are passed as their string, so a URL is never marshaled field by field with its password.
+// Raw JSON is passed as it is when it is well-formed, or as a string otherwise.
+// LogValuers are passed as the string of their resolved value, and runes and complex numbers as their string form.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func attrValueToJSON(attr *Attr) any {
+	switch attr.Type { //nolint:exhaustive // the other values are marshaled as they are
+	case IPType:
+		return ipToString(attr.Value.(net.IP))
+	case URLType:
+		return urlToString(attr.Value.(*url.URL))
+	case JSONRawType:
+		value := attr.Value.(json.RawMessage)
+		if json.Valid(value) {
+			return value
+		}
+
+		return jsonRawToString(value)
+	case LogValuerType:
+		return stringerToString(attr.Value.(fmt.Stringer))
+	case RuneType:
+		return runeToString(attr.Value.(rune))
+	case Complex128Type:
+		return complexToString(attr.Value.(complex128))
+	default:
+		return attr.Value
+	}
+}
+
+// UnmarshalJSON takes a byte slice with attrs in the array or the object form and unmarshals it.
+// Attrs in the object form are unmarshaled as AnyType attrs, keeping their order.
+// Attrs in the array form with an unknown type, or a value that does not match their type,
+// are unmarshaled as AnyType attrs with the plain JSON value.
+func (receiver *unmarshalJSONAttrs) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	if !bytes.HasPrefix(data, []byte(curlyOpen)) {
+		var raws []unmarshalJSONAttr
+
+		err := json.Unmarshal(data, &raws)
+		if err != nil || raws == nil {
+			return err //nolint:wrapcheck // wrapped by UnmarshalJSON
+		}
+
+		attrs := make([]Attr, zero, len(raws))
+		for index := range raws {
+			attrs = append(attrs, raws[index].attr())
+		}
+
+		*receiver = attrs
+
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	_, err := decoder.Token()
+	if err != nil {
+		return err //nolint:wrapcheck // wrapped by UnmarshalJSON
+	}
+
+	attrs := make([]Attr, zero)
+
+	for decoder.More() {
+		token, errT := decoder.Token()
+		if errT != nil {
+			return errT //nolint:wrapcheck // wrapped by UnmarshalJSON
+		}
+
+		var value any
+
+		errD := decoder.Decode(&value)
+		if errD != nil {
+			return errD //nolint:wrapcheck // wrapped by UnmarshalJSON
+		}
+
+		attrs = append(attrs, Any(token.(string), value)) //nolint:forcetypeassert,errcheck // object keys are strings
+	}
+
+	*receiver = attrs
+
+	return nil
+}
+
+// UnmarshalJSON takes a byte slice with the stack as a base64 encoded string, or as an array of lines,
+// and unmarshals it. The lines are joined back with newlines.
+func (receiver *unmarshalJSONStack) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	if !bytes.HasPrefix(data, []byte(bracketOpen)) {
+		return json.Unmarshal(data, (*[]byte)(receiver)) //nolint:wrapcheck // wrapped by UnmarshalJSON
+	}
+
+	var lines []string
+
+	err := json.Unmarshal(data, &lines)
+	if err != nil {
+		return err //nolint:wrapcheck // wrapped by UnmarshalJSON
+	}
+
+	*receiver = []byte(strings.Join(lines, newLine))
+
+	return nil
+}
+
+// attr returns the Attr with the receiver's key, type and value.
+// If the type is unknown, or the value cannot be decoded as that type, it returns an AnyType Attr
+// with the value decoded as a plain JSON value, so the marshalers never see a mismatched Attr.
+func (receiver *unmarshalJSONAttr) attr() Attr {
+	attrType, known := parseJSONAttrType(receiver.Type)
+	if known && attrType != AnyType && string(receiver.Value) != jsonNull {
+		value, ok := decodeJSONAttrValue(attrType, receiver.Value)
+		if ok && attrType == RuneType {
+			return Rune(receiver.Key, value.(rune)) //nolint:forcetypeassert,errcheck // decoded as a rune
+		}
+
+		if ok {
+			return attrOf(receiver.Key, value)
+		}
+	}
+
+	var value any
+
+	_ = json.Unmarshal(receiver.Value, &value) //nolint:errcheck // invalid or missing values are kept as nil
+
+	return Any(receiver.Key, value)
+}
+
+// decodeJSONAttrValue decodes the given JSON value into the Go type of the given attr type.
+// It returns false if the value does not match the type.
+// LogValuerType values are decoded as strings, as the original slog.LogValuer cannot be rebuilt.
+func decodeJSONAttrValue(attrType Type, data []byte) (any, bool) {
+	switch attrType { //nolint:exhaustive // AnyType values are decoded by the caller
+	case ObjectType:
+		var value unmarshalJSONAttrs
+
+		err := json.Unmarshal(data, &value)
+
+		return []Attr(value), err == nil && value != nil
+	case BoolType:
+		return decodeJSONValue[bool](data)
+	case BoolsType:
+		return decodeJSONValue[[]bool](data)
+	case TimeType:
+		return decodeJSONValue[time.Time](data)
+	case TimesType:
+		return decodeJSONValue[[]time.Time](data)
+	case DurationType:
+		return decodeJSONValue[time.Duration](data)
+	case DurationsType:
+		return decodeJSONValue[[]time.Duration](data)
+	case IntType:
+		return decodeJSONValue[int](data)
+	case IntsType:
+		return decodeJSONValue[[]int](data)
+	case Int64Type:
+		return decodeJSONValue[int64](data)
+	case Int64sType:
+		return decodeJSONValue[[]int64](data)
+	case Uint64Type:
+		return decodeJSONValue[uint64](data)
+	case Uint64sType:
+		return decodeJSONValue[[]uint64](data)
+	case Float64Type:
+		return decodeJSONValue[float64](data)
+	case Float64sType:
+		return decodeJSONValue[[]float64](data)
+	case StringType:
+		return decodeJSONValue[string](data)
+	case StringsType:
+		return decodeJSONValue[[]string](data)
+	case IPType:
+		return decodeJSONValue[net.IP](data)
+	case URLType:
+		return decodeJSONURL(data)
+	case JSONRawType:
+		return json.RawMessage(cloneSlice(data)), true
+	case LogValuerType:
+		return decodeJSONValue[string](data)
+	case RuneType:
+		return decodeJSONRune(data)
+	case Complex128Type:
+		return decodeJSONComplex(data)
+	default:
+		return nil, false
+	}
+}
+
+// decodeJSONURL decodes the given JSON string into a *url.URL.
+// It returns false if the value is not a string or cannot be parsed as a URL.
+func decodeJSONURL(data []byte) (any, bool) {
+	var value string
+
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return nil, false
+	}
+
+	parsed, err := url.Parse(value)
+
+	return parsed, err == nil
+}
+
+// decodeJSONRune decodes the given JSON string, in the form written by runeToString, into a rune.
+// Just its code point is read, so the quoted character may be escaped in any way.
+func decodeJSONRune(data []byte) (any, bool) {
+	var value string
+
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return nil, false
+	}
+
+	index := strings.LastIndex(value, runeCodePointPrefix)
+	if index < zero || !strings.HasSuffix(value, parenthesisClose) {
+		return nil, false
+	}
+
+	codePoint := value[index+len(runeCodePointPrefix) : len(value)-len(parenthesisClose)]
+
+	parsed, err := strconv.ParseInt(codePoint, sixteen, thirtyTwo)
+
+	return rune(parsed), err == nil
+}
+
+// decodeJSONComplex decodes the given JSON string, in the form written by complexToString, into a complex128.
+func decodeJSONComplex(data []byte) (any, bool) {
+	var value string
+
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return nil, false
+	}
+
+	parsed, err := strconv.ParseComplex(value, complexBitSize)
+
+	return parsed, err == nil
+}
+
+// decodeJSONValue decodes the given JSON value into a T.
+// It returns false if the value cannot be decoded into a T.
+func decodeJSONValue[T any](data []byte) (any, bool) {
+	var value T
+
+	err := json.Unmarshal(data, &value)
+
+	return value, err == nil
+}
+
+// fillStructuredError takes a unmarshalJSONError and fills a StructuredError with the unmarshalled data.
+// Nested errors are filled recursively, so every level keeps its fields. Null nested errors carry no data
+// and are skipped.
+func (receiver *unmarshalJSONError) fillStructuredError(structured *StructuredError) {
+	structured.Message = receiver.Message
+	structured.Code = receiver.Code
+	structured.HTTPStatus = receiver.HTTPStatus
+	structured.Attrs = receiver.Attrs
+	structured.Tags = receiver.Tags
+	structured.Stack = receiver.Stack
+	structured.frames = receiver.Frames
+	structured.caller = receiver.Caller
+	structured.joined = receiver.Joined
+
+	if len(receiver.Errors) > zero {
+		structured.Errors = make([]error, zero, len(receiver.Errors))
+
+		for _, err := range receiver.Errors {
+			if err == nil {
+				continue
+			}
+
+			_structured := &StructuredError{}
+
+			err.fillStructuredError(_structured)
+
+			structured.Errors = append(structured.Errors, _structured)
+		}
+	}
+}
+
+// UnmarshalJSON takes a byte slice and unmarshals it into the StructuredError.
+// It returns an error if the unmarshaling fails.
+//
+// The unmarshaled data is stored in the StructuredError.
+// If the unmarshaling data is nil, no fields are added to the StructuredError.
+func (receiver *StructuredError) UnmarshalJSON(data []byte) error {
+	var err unmarshalJSONError
+
+	_err := json.Unmarshal(data, &err)
+	if _err != nil {
+		return JoinIf(_err, ErrUnmarshalJSON)
+	}
+
+	err.fillStructuredError(receiver)
+
+	return nil
+}
+
+// MarshalJSON marshals the StructuredError into a byte slice.
+// It returns the marshaled byte slice and no error.
+//
+// The returned []byte will have the following attributes:
+//   - Message
+//   - Code
+//   - HTTPStatus
+//   - Tags
+//   - Attrs
+//   - Joined, only for errors created via Join or JoinIf
+//   - Errors
+//   - Stack
+//   - Frames
+//   - Caller.
+//
+// The marshaled data is stored in the StructuredError.
+// If the marshaled data is nil, no fields are added to the StructuredError.
+//
+// The marshaling is done in a pooled buffer, and the returned []byte is a copy that is safe to retain.
+func (receiver *StructuredError) MarshalJSON() ([]byte, error) {
+	bytesBuffer := jsonBufferPool.Get().(*bytes.Buffer) //nolint:forcetypeassert,errcheck // the pool only holds *bytes.Buffer
+	bytesBuffer.Reset()
+
+	defer func() {
+		if bytesBuffer.Cap() <= maxPooledBufferSize {
+			jsonBufferPool.Put(bytesBuffer)
+		}
+	}()
+
+	receiver.asJSON(&jsonWriter{Buffer: bytesBuffer})
+
+	data := make([]byte, bytesBuffer.Len())
+	copy(data, bytesBuffer.Bytes())
+
+	return data, nil
+}
+
+// WriteJSON streams the StructuredError as JSON to the given writer, like an http.ResponseWriter or a log sink.
+//
+// It writes the same bytes as MarshalJSON, but the pooled buffer is flushed to the writer
+// as the errors are marshaled, instead of building the whole document in memory.
+// This suits very large joined errors.
+//
+// It returns the first error returned by the writer, if any, after which nothing else is written.
+func (receiver *StructuredError) WriteJSON(writer io.Writer) error {
+	bytesBuffer := jsonBufferPool.Get().(*bytes.Buffer) //nolint:forcetypeassert,errcheck // the pool only holds *bytes.Buffer
+	bytesBuffer.Reset()
+
+	defer func() {
+		if bytesBuffer.Cap() <= maxPooledBufferSize {
+			jsonBufferPool.Put(bytesBuffer)
+		}
+	}()
+
+	stream := jsonWriter{Buffer: bytesBuffer, destination: writer}
+
+	receiver.asJSON(&stream)
+	stream.flush(true)
+
+	return stream.err
+}
+
+// flush writes the buffered JSON to the destination writer and resets the buffer.
+//
+// Unless forced, it waits until the buffer holds jsonFlushSize bytes, avoiding many small writes.
+// It does nothing without a destination writer, as in MarshalJSON,
+// and it discards the buffered JSON once the destination writer failed.
+func (receiver *jsonWriter) flush(force bool) {
+	if receiver.destination == nil {
+		return
+	}
+
+	if receiver.err != nil {
+		receiver.Reset()
+
+		return
+	}
+
+	if !force && receiver.Len() < jsonFlushSize {
+		return
+	}
+
+	_, receiver.err = receiver.destination.Write(receiver.Bytes())
+	receiver.Reset()
+}
+
+// asJSON marshals the StructuredError into a byte slice.
+//
+// It returns the marshaled byte slice and no error.
+// The marshaled data is stored in the StructuredError.
+// If the marshaled data is nil, no fields are added to the StructuredError.
+//
+// Parameters:
+//
+//	writer - the jsonWriter to be written to.
+//
+// Returns: The marshaled byte slice and no error.
+func (receiver *StructuredError) asJSON(writer *jsonWriter) {
+	writer.WriteString(curlyOpen)
+	defer writer.WriteString(curlyClose)
+
+	if receiver == nil {
+		valueToJSON(writer, messageKey, nilValue)
+
+		return
+	}
+
+	valueToJSON(writer, messageKey, cmpOr(trimmedMessage(receiver.Message), nilValue))
+
+	if receiver.Code != emptyString {
+		writer.WriteString(comma)
+		valueToJSON(writer, codeKey, receiver.Code)
+	}
+
+	if receiver.HTTPStatus != zero {
+		writer.WriteString(comma)
+		writer.WriteString(quote)
+		writer.WriteString(httpStatusKey)
+		writer.WriteString(quote)
+		writer.WriteString(colon)
+		writer.WriteString(strconv.Itoa(receiver.HTTPStatus))
+	}
+
+	if keepField(len(receiver.Tags)) {
+		writer.WriteString(comma)
+		sliceToJSON(writer, tagsKey, receiver.Tags)
+	}
+
+	if attrs := withoutOmittedAttrs(receiver.Attrs); keepField(len(attrs)) {
+		writer.WriteString(comma)
+
+		if attrObjectMode {
+			attrsToJSONObject(writer, attrsKey, attrs)
+		} else {
+			sliceToJSON(writer, attrsKey, attrs)
+		}
+	}
+
+	if receiver.joined {
+		writer.WriteString(comma)
+		writer.WriteString(quote)
+		writer.WriteString(joinedKey)
+		writer.WriteString(quote)
+		writer.WriteString(colon)
+		writer.WriteString(strconv.FormatBool(true))
+	}
+
+	if keepField(len(receiver.Errors)) {
+		normalized := receiver.normalizedErrors()
+
+		writer.WriteString(comma)
+		sliceToJSON(writer, errorsKey, normalized)
+	}
+
+	if keepField(len(receiver.Stack)) {
+		writer.WriteString(comma)
+
+		if stackJSONMode == StackAsLines {
+			sliceToJSON(writer, stackKey, stackLines(receiver.Stack))
+		} else {
+			encoded := base64.StdEncoding.EncodeToString(receiver.Stack)
+			valueToJSON(writer, stackKey, encoded)
+		}
+	}
+
+	if len(receiver.frames) > zero {
+		writer.WriteString(comma)
+		sliceToJSON(writer, framesKey, receiver.frames)
+	}
+
+	if receiver.caller != emptyString {
+		writer.WriteString(comma)
+		valueToJSON(writer, callerKey, receiver.caller)
+	}
+}
+
+// valueToJSON writes a JSON encoded value to the provided jsonWriter.
+//
+// Parameters:
+//
+//	writer - the jsonWriter to write to
+//	key - the key of the JSON object
+//	value - the value to be encoded
+//
+// Returns: A JSON encoded value is written to the provided jsonWriter.
+func valueToJSON(writer *jsonWriter, key, value string) {
+	writer.WriteString(quote)
+	writer.WriteString(key)
+	writer.WriteString(quote)
+	writer.WriteString(colon)
+
+	if needsJSONEscape(value) {
+		encoded, _ := json.Marshal(value) //nolint:errchkjson // strings are always marshaled
+		writer.Write(encoded)
+
+		return
+	}
+
+	writer.WriteString(quote)
+	writer.WriteString(value)
+	writer.WriteString(quote)
+}
+
+// needsJSONEscape reports whether the given value must be escaped to be a JSON string,
+// as encoding/json does. Printable ASCII values without quotes, backslashes or HTML characters
+// are written as they are, avoiding the allocation of json.Marshal.
+func needsJSONEscape(value string) bool {
+	for index := zero; index < len(value); index++ {
+		switch char := value[index]; {
+		case char < ' ' || char > '~':
+			return true
+		case char == '"' || char == '\\' || char == '<' || char == '>' || char == '&':
+			return true
+		}
+	}
+
+	return false
+}
+
+// errorToJSON writes a JSON encoded value to the provided jsonWriter.
+//
+// Parameters:
+//
+//	writer - the jsonWriter to write to
+//	err - the error to be encoded
+//
+// The function writes a JSON object to the provided jsonWriter.
+// If the error is nil, the function writes a JSON object with the key "message" and the value "nil".
+// If the error is a StructuredError, the function writes a JSON object with the same fields as the StructuredError.
+// If the error is not a StructuredError, the function writes a JSON object with the key "message"
+// and the value of the error's Error() method.
+//
+// Returns: A JSON encoded value is written to the provided jsonWriter.
+func errorToJSON(writer *jsonWriter, err error) {
+	var value *StructuredError
+	switch {
+	case err == nil:
+		writer.WriteString(curlyOpen)
+		valueToJSON(writer, messageKey, nilValue)
+		writer.WriteString(curlyClose)
+	case stderrors.As(err, &value):
+		value.asJSON(writer)
+	default:
+		errStr := strings.TrimSpace(err.Error())
+
+		writer.WriteString(curlyOpen)
+		valueToJSON(writer, messageKey, cmpOr(errStr, nilValue))
+		writer.WriteString(curlyClose)
+	}
+}
+
+// sliceToJSON writes a JSON encoded value to the provided jsonWriter.
+//
+// Parameters:
+//
+//	writer - the jsonWriter to write to
+//	key - the key of the JSON object
+//	slice - the slice of values to be encoded
+//
+// Returns: A JSON encoded value is written to the provided jsonWriter.
+func sliceToJSON[T any](writer *jsonWriter, key string, slice []T) {
+	writer.WriteString(quote)
+	writer.WriteString(key)
+	writer.WriteString(quote)
+	writer.WriteString(colon)
+
+	if len(slice) == zero {
+		writer.WriteString(bracketOpen)
+		writer.WriteString(bracketClose)
+
+		return
+	}
+
+	switch values := any(slice).(type) {
+	case []error:
+		writer.WriteString(bracketOpen)
+
+		for index, value := range values {
+			if index > zero {
+				writer.WriteString(comma)
+			}
+
+			errorToJSON(writer, value)
+			writer.flush(false)
+		}
+
+		writer.WriteString(bracketClose)
+	default:
+		arr, err := json.Marshal(slice)
+		if err != nil {
+			writer.WriteString(bracketOpen)
+			writer.WriteString(err.Error())
+			writer.WriteString(bracketClose)
+
+			return
+		}
+
+		writer.Write(arr)
+	}
+}
+
+// attrsToJSONObject writes the given attrs as a JSON object keyed by Attr.Key to the provided jsonWriter.
+//
+// Object attrs are written as nested objects, and duplicate keys are last-write-wins,
+// keeping the position of their first occurrence.
+//
+// Parameters:
+//
+//	writer - the jsonWriter to write to
+//	key - the key of the JSON object
+//	attrs - the attrs to be encoded
+//
+// Returns: A JSON encoded value is written to the provided jsonWriter.
+func attrsToJSONObject(writer *jsonWriter, key string, attrs []Attr) {
+	writer.WriteString(quote)
+	writer.WriteString(key)
+	writer.WriteString(quote)
+	writer.WriteString(colon)
+
+	attrValuesToJSONObject(writer, attrs)
+}
+
+// attrValuesToJSONObject writes the given attrs as a JSON object keyed by Attr.Key to the provided jsonWriter.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func attrValuesToJSONObject(writer *jsonWriter, attrs []Attr) {
+	positions := make(map[string]int, len(attrs))
+	unique := make([]Attr, zero, len(attrs))
+
+	for _, attr := range attrs {
+		if position, ok := positions[attr.Key]; ok {
+			unique[position] = attr
+
+			continue
+		}
+
+		positions[attr.Key] = len(unique)
+		unique = append(unique, attr)
+	}
+
+	writer.WriteString(curlyOpen)
+	defer writer.WriteString(curlyClose)
+
+	for index, attr := range unique {
+		if index > zero {
+			writer.WriteString(comma)
+		}
+
+		attr = *attr.redacted()
+
+		key, _ := json.Marshal(attr.Key) //nolint:errchkjson // strings are always marshaled
+
+		writer.Write(key)
+		writer.WriteString(colon)
+
+		if attr.Type == ObjectType {
+			attrValuesToJSONObject(writer, attr.Value.([]Attr))
+
+			continue
+		}
+
+		value, err := json.Marshal(attrValueToJSON(&attr))
+		if err != nil {
+			value, _ = json.Marshal(err.Error()) //nolint:errchkjson // strings are always marshaled
+		}
+
+		writer.Write(value)
+	}
+}
@@ -1,7 +1,11 @@
 package errors
 
 import (
+	"encoding/json"
 	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
 	"strings"
 )
 
@@ -11,6 +15,8 @@ import (
 //
 // Otherwise, it will have the following attributes:
 //   - Message
+//   - Code, if not empty
+//   - HTTPStatus, if not zero
 //   - Tags
 //   - Attrs
 //   - Errors
@@ -23,6 +29,19 @@ func (receiver *StructuredError) AsMap() map[string]any {
 	return fields
 }
 
+// ToMap returns the StructuredError as a nested map[string]any, for inspection
+// or for generic serializers. It is the same as AsMap:
+//   - "message" holds the message, or nilValue if the receiver is nil
+//   - "code" holds the code, if not empty
+//   - "http_status" holds the HTTP status as an int, if not zero
+//   - "tags" holds the tags as a []string
+//   - "attrs" holds a map[string]any of the attr values keyed by Attr.Key
+//   - "errors" holds the nested errors as a []map[string]any, built recursively
+//   - "stack" holds the lines of the stack as a []string.
+func (receiver *StructuredError) ToMap() map[string]any {
+	return receiver.AsMap()
+}
+
 // asMap is the actual implementation for AsMap.
 func (receiver *StructuredError) asMap(fields map[string]any) {
 	if receiver == nil {
@@ -31,27 +50,32 @@ func (receiver *StructuredError) asMap(fields map[string]any) {
 		return
 	}
 
-	fields[messageKey] = cmpOr(receiver.Message, nilValue)
+	fields[messageKey] = cmpOr(trimmedMessage(receiver.Message), nilValue)
+
+	if receiver.Code != emptyString {
+		fields[codeKey] = receiver.Code
+	}
+
+	if receiver.HTTPStatus != zero {
+		fields[httpStatusKey] = receiver.HTTPStatus
+	}
 
-	if len(receiver.Tags) > zero {
+	if keepField(len(receiver.Tags)) {
 		sliceToMap(fields, tagsKey, receiver.Tags)
 	}
 
-	if len(receiver.Attrs) > zero {
-		sliceToMap(fields, attrsKey, receiver.Attrs)
+	if attrs := withoutOmittedAttrs(receiver.Attrs); keepField(len(attrs)) {
+		sliceToMap(fields, attrsKey, attrs)
 	}
 
-	if len(receiver.Errors) > zero {
-		target := normalizerTarget{
-			errs: make([]error, zero, len(receiver.Errors)),
-		}
-		normalizeErrors(zero, &target, receiver.Errors...)
+	if keepField(len(receiver.Errors)) {
+		normalized := receiver.normalizedErrors()
 
-		sliceToMap(fields, errorsKey, target.errs)
+		sliceToMap(fields, errorsKey, normalized)
 	}
 
-	if len(receiver.Stack) > zero {
-		sliceToMap(fields, stackKey, strings.Split(string(receiver.Stack), newLine))
+	if keepField(len(receiver.Stack)) {
+		sliceToMap(fields, stackKey, stackLines(receiver.Stack))
 	}
 }
 
@@ -77,9 +101,23 @@ func (receiver *Attr) asMap(fields map[string]any) {
 		return
 	}
 
-	switch receiver.Type { //nolint:exhaustive // just strings need specific assert
+	receiver = receiver.redacted()
+
+	switch receiver.Type { //nolint:exhaustive // just strings, IPs, URLs and raw JSON need specific assert
 	case StringsType:
 		sliceToMap(fields, receiver.Key, receiver.Value.([]string))
+	case IPType:
+		fields[receiver.Key] = ipToString(receiver.Value.(net.IP))
+	case URLType:
+		fields[receiver.Key] = urlToString(receiver.Value.(*url.URL))
+	case JSONRawType:
+		fields[receiver.Key] = jsonRawToString(receiver.Value.(json.RawMessage))
+	case LogValuerType:
+		fields[receiver.Key] = stringerToString(receiver.Value.(fmt.Stringer))
+	case RuneType:
+		fields[receiver.Key] = runeToString(receiver.Value.(rune))
+	case Complex128Type:
+		fields[receiver.Key] = complexToString(receiver.Value.(complex128))
 	default:
 		fields[receiver.Key] = receiver.Value
 	}
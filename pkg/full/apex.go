@@ -0,0 +1,128 @@
+package errors
+
+import (
+	stderrors "errors"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+//nolint:errcheck // this is for interface assertion
+var (
+	_ log.Fielder = (*StructuredError)(nil)
+	_ log.Fielder = (*Attr)(nil)
+)
+
+// Fields implements log.Fielder.
+//
+// It flattens the StructuredError into a log.Fields.
+// If the receiver is nil, it adds a single field to the log.Fields with the key "message"
+// and the value nilValue.
+//
+// Otherwise, it will have the following fields:
+//   - message
+//   - code, if not empty
+//   - http_status, if not zero
+//   - tags
+//   - one field per Attr, keyed by the Attr key
+//   - errors, as a slice of log.Fields
+//   - stack.
+//
+// Attrs never override the message, code, http_status, tags, errors or stack fields.
+//
+// Usage must be like:
+//
+//	var _err *errors.StructuredError
+//
+//	if !errors.As(err, &_err) {
+//	  log.Fatal("what!?", err)
+//	}
+//
+//	log.WithFields(_err).Error("message")
+func (receiver *StructuredError) Fields() log.Fields {
+	fields := make(log.Fields)
+
+	receiver.asApex(fields)
+
+	return fields
+}
+
+// asApex is the actual implementation for Fields.
+func (receiver *StructuredError) asApex(fields log.Fields) {
+	if receiver == nil {
+		fields[messageKey] = nilValue
+
+		return
+	}
+
+	for _, attr := range withoutOmittedAttrs(receiver.Attrs) {
+		attr.asMap(fields)
+	}
+
+	fields[messageKey] = cmpOr(trimmedMessage(receiver.Message), nilValue)
+
+	if receiver.Code != emptyString {
+		fields[codeKey] = receiver.Code
+	}
+
+	if receiver.HTTPStatus != zero {
+		fields[httpStatusKey] = receiver.HTTPStatus
+	}
+
+	if keepField(len(receiver.Tags)) {
+		sliceToMap(fields, tagsKey, receiver.Tags)
+	}
+
+	if keepField(len(receiver.Errors)) {
+		normalized := receiver.normalizedErrors()
+
+		errs := make([]log.Fields, zero, len(normalized))
+		for _, err := range normalized {
+			errs = append(errs, errorToApex(err))
+		}
+
+		fields[errorsKey] = errs
+	}
+
+	if keepField(len(receiver.Stack)) {
+		sliceToMap(fields, stackKey, stackLines(receiver.Stack))
+	}
+}
+
+// Fields implements log.Fielder.
+//
+// If the receiver is nil, it adds a single field to the log.Fields with the key nilValue and the value nilValue.
+//
+// Otherwise, it will have a single field with the key receiver.Key and the value receiver.Value.
+func (receiver *Attr) Fields() log.Fields {
+	fields := make(log.Fields, one)
+
+	receiver.asMap(fields)
+
+	return fields
+}
+
+// errorToApex flattens the given error into a log.Fields.
+//
+// If the error is nil, it has a single field with the key "message" and the value nilValue.
+//
+// If the error is a *StructuredError, it has the same fields as the *StructuredError.
+//
+// If the error is not a *StructuredError, it has a single field with the key "message"
+// and the value of the error's Error() method, or nilValue if the error message is empty.
+func errorToApex(err error) log.Fields {
+	fields := make(log.Fields)
+
+	var value *StructuredError
+	switch {
+	case err == nil:
+		fields[messageKey] = nilValue
+	case stderrors.As(err, &value):
+		value.asApex(fields)
+	default:
+		errStr := strings.TrimSpace(err.Error())
+		fields[messageKey] = cmpOr(errStr, nilValue)
+	}
+
+	return fields
+}
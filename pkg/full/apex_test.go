@@ -0,0 +1,187 @@
+package errors
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredErrorFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		want log.Fields
+	}{
+		{
+			name: "given_nil_error_when_fields_then_returns_nil_message",
+			err:  nil,
+			want: log.Fields{"message": "!NILVALUE"},
+		},
+		{
+			name: "given_error_with_empty_message_when_fields_then_returns_nil_message",
+			err:  New(""),
+			want: log.Fields{"message": "!NILVALUE"},
+		},
+		{
+			name: "given_error_with_code_when_fields_then_returns_code",
+			err:  New("test").WithCode("NOT_FOUND"),
+			want: log.Fields{"message": "test", "code": "NOT_FOUND"},
+		},
+		{
+			name: "given_error_with_http_status_when_fields_then_returns_http_status",
+			err:  New("test").WithHTTPStatus(404),
+			want: log.Fields{"message": "test", "http_status": 404},
+		},
+		{
+			name: "given_error_with_tags_and_attrs_when_fields_then_flattens_them",
+			err:  New("test").WithTags(" tag1 ", "tag2").WithAttrs(String("request_id", "123"), Int("code", 500)),
+			want: log.Fields{
+				"message":    "test",
+				"tags":       []string{"tag1", "tag2"},
+				"request_id": "123",
+				"code":       500,
+			},
+		},
+		{
+			name: "given_error_with_colliding_attr_when_fields_then_keeps_message",
+			err:  New("test").WithAttrs(String("message", "attr")),
+			want: log.Fields{"message": "test"},
+		},
+		{
+			name: "given_error_with_nested_errors_when_fields_then_recurses_into_slice",
+			err: New("parent").WithErrors(
+				stderrors.New(" child "),
+				New("structured").WithAttrs(Bool("retry", true)),
+				nil,
+			),
+			want: log.Fields{
+				"message": "parent",
+				"errors": []log.Fields{
+					{"message": "child"},
+					{"message": "structured", "retry": true},
+					{"message": "!NILVALUE"},
+				},
+			},
+		},
+		{
+			name: "given_error_with_stack_when_fields_then_returns_stack_lines",
+			err:  New("test").WithStack([]byte("line1\nline2")),
+			want: log.Fields{"message": "test", "stack": []string{"line1", "line2"}},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.Fields()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestAttrFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attr *Attr
+		name string
+		want log.Fields
+	}{
+		{
+			name: "given_nil_attr_when_fields_then_returns_nil_value",
+			attr: nil,
+			want: log.Fields{"!NILVALUE": "!NILVALUE"},
+		},
+		{
+			name: "given_int_attr_when_fields_then_returns_key_value",
+			attr: &Attr{Type: IntType, Key: "count", Value: 3},
+			want: log.Fields{"count": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.attr.Fields()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorFieldsIntegration(t *testing.T) {
+	t.Parallel()
+
+	// given
+	handler := memory.New()
+	logger := &log.Logger{Handler: handler, Level: log.InfoLevel}
+	err := New("operation failed").WithTags("database").WithAttrs(Int("code", 500))
+
+	// when
+	logger.WithFields(err).Error("error occurred")
+
+	// then
+	require.Len(t, handler.Entries, 1)
+	assert.Equal(t, "operation failed", handler.Entries[0].Fields["message"])
+	assert.Equal(t, []string{"database"}, handler.Entries[0].Fields["tags"])
+	assert.Equal(t, 500, handler.Entries[0].Fields["code"])
+}
+
+func TestStructuredErrorFieldsWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	var got log.Fields
+
+	assert.NotPanics(t, func() { got = err.Fields() })
+
+	// then
+	assert.Equal(t, "not an int", got["count"])
+	assert.Equal(t, "john", got["user"])
+}
+
+func TestStructuredErrorFieldsWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var err *StructuredError
+
+	// when
+	got := err.Fields()
+
+	// then
+	assert.Equal(t, "NULL", got["message"])
+}
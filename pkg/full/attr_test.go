@@ -1,6 +1,9 @@
 package errors
 
 import (
+	"encoding/json"
+	"net"
+	"net/url"
 	"testing"
 	"time"
 
@@ -120,6 +123,96 @@ func TestObject(t *testing.T) {
 	}
 }
 
+func TestObjectBuilder(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		// given
+		builder *ObjectBuilder
+		// then
+		want Attr
+	}{
+		{
+			name:    "given_empty_builder_when_build_then_returns_empty_object",
+			builder: NewObject("empty"),
+			want:    Object("empty", []Attr{}...),
+		},
+		{
+			name: "given_builder_with_typed_values_when_build_then_matches_object",
+			builder: NewObject("user").
+				Str("name", "john").
+				Int("age", 30).
+				Int64("id", 42).
+				Uint64("flags", 7).
+				Float64("score", 9.5).
+				Bool("active", true).
+				Time("created", now).
+				Duration("ttl", time.Second).
+				Any("meta", []byte("x")).
+				Attrs(Strings("roles", "admin")),
+			want: Object(
+				"user",
+				String("name", "john"),
+				Int("age", 30),
+				Int64("id", 42),
+				Uint64("flags", 7),
+				Float64("score", 9.5),
+				Bool("active", true),
+				Time("created", now),
+				Duration("ttl", time.Second),
+				Any("meta", []byte("x")),
+				Strings("roles", "admin"),
+			),
+		},
+		{
+			name: "given_nested_builders_when_build_then_matches_nested_object",
+			builder: NewObject("user").
+				Str("name", "john").
+				Object(NewObject("address").Str("city", "NYC").Object(NewObject("geo").Float64("lat", 1.5))).
+				Object(nil),
+			want: Object(
+				"user",
+				String("name", "john"),
+				Object("address", String("city", "NYC"), Object("geo", Float64("lat", 1.5))),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.builder.Build()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestObjectBuilderBuildReturnsCopy(t *testing.T) {
+	t.Parallel()
+
+	// given
+	builder := NewObject("obj").Str("a", "b")
+
+	// when
+	got := builder.Build()
+
+	builder.Int("c", 1)
+
+	// then
+	assert.Equal(t, Object("obj", String("a", "b")), got)
+	assert.Equal(t, Object("obj", String("a", "b"), Int("c", 1)), builder.Build())
+}
+
 func TestBool(t *testing.T) {
 	t.Parallel()
 
@@ -900,3 +993,446 @@ func TestStrings(t *testing.T) {
 		)
 	}
 }
+
+func TestIP(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		key   string
+		value net.IP
+		want  Attr
+	}{
+		{
+			name:  "given_ipv4_when_ip_then_returns_attr_with_ip_type",
+			key:   "client",
+			value: net.IPv4(127, 0, 0, 1),
+			want: Attr{
+				Type:  IPType,
+				Key:   "client",
+				Value: net.IPv4(127, 0, 0, 1),
+			},
+		},
+		{
+			name:  "given_nil_ip_when_ip_then_returns_attr_with_ip_type",
+			key:   "client",
+			value: nil,
+			want: Attr{
+				Type:  IPType,
+				Key:   "client",
+				Value: net.IP(nil),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := IP(test.key, test.value)
+
+				// then
+				assert.Equal(t, test.want.Type, got.Type)
+				assert.Equal(t, test.want.Key, got.Key)
+				assert.Equal(t, test.want.Value, got.Value)
+			},
+		)
+	}
+}
+
+func TestURL(t *testing.T) {
+	t.Parallel()
+
+	endpoint := &url.URL{Scheme: "https", Host: "example.com", Path: "/path"}
+
+	tests := []struct {
+		name  string
+		key   string
+		value *url.URL
+		want  Attr
+	}{
+		{
+			name:  "given_url_when_url_then_returns_attr_with_url_type",
+			key:   "endpoint",
+			value: endpoint,
+			want: Attr{
+				Type:  URLType,
+				Key:   "endpoint",
+				Value: endpoint,
+			},
+		},
+		{
+			name:  "given_nil_url_when_url_then_returns_attr_with_url_type",
+			key:   "endpoint",
+			value: nil,
+			want: Attr{
+				Type:  URLType,
+				Key:   "endpoint",
+				Value: (*url.URL)(nil),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := URL(test.key, test.value)
+
+				// then
+				assert.Equal(t, test.want.Type, got.Type)
+				assert.Equal(t, test.want.Key, got.Key)
+				assert.Equal(t, test.want.Value, got.Value)
+			},
+		)
+	}
+}
+
+func TestJSONRaw(t *testing.T) {
+	t.Parallel()
+
+	// given
+	raw := json.RawMessage(`{"id":1}`)
+
+	// when
+	got := JSONRaw("upstream", raw)
+	cloned := got.clone()
+
+	raw[2] = 'X'
+
+	// then
+	assert.Equal(t, JSONRawType, got.Type)
+	assert.Equal(t, "upstream", got.Key)
+	assert.Equal(t, json.RawMessage(`{"id":1}`), cloned.Value)
+}
+
+func TestSensitive(t *testing.T) {
+	t.Parallel()
+
+	// when
+	got := Sensitive("password", "secret")
+
+	// then
+	assert.Equal(t, StringType, got.Type)
+	assert.Equal(t, "password", got.Key)
+	assert.Equal(t, "secret", got.Value)
+	assert.True(t, got.IsSensitive())
+}
+
+func TestAttrIsSensitive(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attr *Attr
+		name string
+		want bool
+	}{
+		{
+			name: "given_nil_attr_when_is_sensitive_then_returns_false",
+			attr: nil,
+			want: false,
+		},
+		{
+			name: "given_plain_attr_when_is_sensitive_then_returns_false",
+			attr: &Attr{Type: StringType, Key: "user", Value: "john"},
+			want: false,
+		},
+		{
+			name: "given_sensitive_attr_when_is_sensitive_then_returns_true",
+			attr: &Attr{Type: StringType, Key: "token", Value: "abc", sensitive: true},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.attr.IsSensitive()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestAttrOmitEmpty(t *testing.T) {
+	t.Parallel()
+
+	// given
+	attr := String("user_id", "")
+
+	// when
+	got := attr.OmitEmpty()
+
+	// then
+	assert.True(t, got.omitEmpty)
+	assert.False(t, attr.omitEmpty)
+	assert.Equal(t, attr.Key, got.Key)
+	assert.Equal(t, attr.Value, got.Value)
+	assert.Equal(t, attr.Type, got.Type)
+}
+
+func TestAttrIsOmitted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		attr Attr
+		want bool
+	}{
+		{
+			name: "given_empty_string_without_omit_empty_when_is_omitted_then_returns_false",
+			attr: String("user_id", ""),
+			want: false,
+		},
+		{
+			name: "given_empty_string_when_is_omitted_then_returns_true",
+			attr: String("user_id", "").OmitEmpty(),
+			want: true,
+		},
+		{
+			name: "given_non_empty_string_when_is_omitted_then_returns_false",
+			attr: String("user_id", "7").OmitEmpty(),
+			want: false,
+		},
+		{
+			name: "given_zero_int_when_is_omitted_then_returns_true",
+			attr: Int("count", 0).OmitEmpty(),
+			want: true,
+		},
+		{
+			name: "given_false_bool_when_is_omitted_then_returns_true",
+			attr: Bool("enabled", false).OmitEmpty(),
+			want: true,
+		},
+		{
+			name: "given_zero_time_when_is_omitted_then_returns_true",
+			attr: Time("at", time.Time{}).OmitEmpty(),
+			want: true,
+		},
+		{
+			name: "given_empty_slice_when_is_omitted_then_returns_true",
+			attr: Strings("tags").OmitEmpty(),
+			want: true,
+		},
+		{
+			name: "given_nil_url_when_is_omitted_then_returns_true",
+			attr: URL("endpoint", nil).OmitEmpty(),
+			want: true,
+		},
+		{
+			name: "given_nil_any_when_is_omitted_then_returns_true",
+			attr: Any("value", nil).OmitEmpty(),
+			want: true,
+		},
+		{
+			name: "given_object_with_only_omitted_attrs_when_is_omitted_then_returns_true",
+			attr: Object("user", String("id", "").OmitEmpty()).OmitEmpty(),
+			want: true,
+		},
+		{
+			name: "given_object_with_kept_attrs_when_is_omitted_then_returns_false",
+			attr: Object("user", String("id", "7").OmitEmpty()).OmitEmpty(),
+			want: false,
+		},
+		{
+			name: "given_mismatched_value_when_is_omitted_then_returns_false",
+			attr: Attr{Type: IntType, Key: "count", Value: "", omitEmpty: true},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.attr.isOmitted()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestAttrRedacted(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attr *Attr
+		name string
+		want *Attr
+	}{
+		{
+			name: "given_attr_with_matching_value_when_redacted_then_returns_attr",
+			attr: &Attr{Type: IntType, Key: "count", Value: 1},
+			want: &Attr{Type: IntType, Key: "count", Value: 1},
+		},
+		{
+			name: "given_sensitive_attr_when_redacted_then_returns_redacted_string",
+			attr: &Attr{Type: IntType, Key: "count", Value: 1, sensitive: true},
+			want: &Attr{Type: StringType, Key: "count", Value: "[REDACTED]"},
+		},
+		{
+			name: "given_attr_with_mismatched_value_when_redacted_then_returns_any_attr",
+			attr: &Attr{Type: IntType, Key: "count", Value: "not an int"},
+			want: &Attr{Type: AnyType, Key: "count", Value: "not an int"},
+		},
+		{
+			name: "given_object_attr_with_mismatched_value_when_redacted_then_returns_any_attr",
+			attr: &Attr{Type: ObjectType, Key: "user", Value: nil},
+			want: &Attr{Type: AnyType, Key: "user", Value: nil},
+		},
+		{
+			name: "given_url_attr_with_password_when_redacted_then_returns_url_with_redacted_password",
+			attr: &Attr{
+				Type:  URLType,
+				Key:   "endpoint",
+				Value: &url.URL{Scheme: "https", Host: "example.com", User: url.UserPassword("john", "secret")},
+			},
+			want: &Attr{
+				Type:  URLType,
+				Key:   "endpoint",
+				Value: &url.URL{Scheme: "https", Host: "example.com", User: url.UserPassword("john", "xxxxx")},
+			},
+		},
+		{
+			name: "given_url_attr_without_password_when_redacted_then_returns_attr",
+			attr: &Attr{
+				Type:  URLType,
+				Key:   "endpoint",
+				Value: &url.URL{Scheme: "https", Host: "example.com", User: url.User("john")},
+			},
+			want: &Attr{
+				Type:  URLType,
+				Key:   "endpoint",
+				Value: &url.URL{Scheme: "https", Host: "example.com", User: url.User("john")},
+			},
+		},
+		{
+			name: "given_attr_with_unknown_type_when_redacted_then_returns_attr",
+			attr: &Attr{Type: Type(99), Key: "count", Value: 1},
+			want: &Attr{Type: Type(99), Key: "count", Value: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.attr.redacted()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestAttrCloneWithMismatchedValue(t *testing.T) {
+	t.Parallel()
+
+	// given
+	attr := Attr{Type: StringsType, Key: "tags", Value: "not a slice"}
+
+	// when
+	var got Attr
+
+	assert.NotPanics(t, func() { got = attr.clone() })
+
+	// then
+	assert.Equal(t, attr, got)
+}
+
+func TestRedact(t *testing.T) { //nolint:paralleltest // Redact is not thread-safe
+	// given
+	Redact("test_redact_key")
+	t.Cleanup(func() { delete(sensitiveKeys, "test_redact_key") })
+
+	attr := Int("test_redact_key", 42)
+	err := New("test").WithAttrs(attr, String("user", "john"))
+
+	// when
+	got := err.AsMap()
+
+	// then
+	assert.True(t, attr.IsSensitive())
+	assert.Equal(t, map[string]any{"test_redact_key": "[REDACTED]", "user": "john"}, got["attrs"])
+
+	raw, ok := err.GetAttr("test_redact_key")
+	assert.True(t, ok)
+	assert.Equal(t, 42, raw.Value)
+}
+
+func TestAttrOf(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		// given
+		value any
+		// then
+		want Attr
+	}{
+		{
+			name:  "given_attrs_when_attr_of_then_returns_object",
+			value: []Attr{Int("id", 1)},
+			want:  Object("key", Int("id", 1)),
+		},
+		{name: "given_bool_when_attr_of_then_returns_bool", value: true, want: Bool("key", true)},
+		{name: "given_bools_when_attr_of_then_returns_bools", value: []bool{true}, want: Bools("key", true)},
+		{name: "given_time_when_attr_of_then_returns_time", value: now, want: Time("key", now)},
+		{name: "given_times_when_attr_of_then_returns_times", value: []time.Time{now}, want: Times("key", now)},
+		{name: "given_duration_when_attr_of_then_returns_duration", value: time.Second, want: Duration("key", time.Second)},
+		{
+			name:  "given_durations_when_attr_of_then_returns_durations",
+			value: []time.Duration{time.Second},
+			want:  Durations("key", time.Second),
+		},
+		{name: "given_int_when_attr_of_then_returns_int", value: 1, want: Int("key", 1)},
+		{name: "given_ints_when_attr_of_then_returns_ints", value: []int{1}, want: Ints("key", 1)},
+		{name: "given_int64_when_attr_of_then_returns_int64", value: int64(1), want: Int64("key", 1)},
+		{name: "given_int64s_when_attr_of_then_returns_int64s", value: []int64{1}, want: Int64s("key", 1)},
+		{name: "given_uint64_when_attr_of_then_returns_uint64", value: uint64(1), want: Uint64("key", 1)},
+		{name: "given_uint64s_when_attr_of_then_returns_uint64s", value: []uint64{1}, want: Uint64s("key", 1)},
+		{name: "given_float64_when_attr_of_then_returns_float64", value: 1.5, want: Float64("key", 1.5)},
+		{name: "given_float64s_when_attr_of_then_returns_float64s", value: []float64{1.5}, want: Float64s("key", 1.5)},
+		{name: "given_string_when_attr_of_then_returns_string", value: "value", want: String("key", "value")},
+		{name: "given_strings_when_attr_of_then_returns_strings", value: []string{"a"}, want: Strings("key", "a")},
+		{name: "given_other_type_when_attr_of_then_returns_any", value: int32(1), want: Any("key", int32(1))},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := attrOf("key", test.value)
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
@@ -0,0 +1,192 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// The benchmarks of this file compare the structured errors against the standard library and pkg/errors,
+// for the same information: a message with a few typed fields, marshaled to text or JSON.
+// The standard library and pkg/errors carry the fields in the message, or in a map for JSON.
+//
+// Run them with:
+//
+//	go test ./pkg/full -run '^$' -bench 'Comparison' -benchmem
+
+const (
+	comparisonChildren = 10
+	comparisonUserID   = "user-123"
+	comparisonRetries  = 3
+	comparisonRatio    = 0.75
+)
+
+//nolint:gochecknoglobals // shared fixtures, so every benchmark measures the same values
+var (
+	comparisonCreatedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	comparisonSink      any
+)
+
+// comparisonStructured returns a StructuredError exercising the typed Attr paths, not just a plain message.
+func comparisonStructured(message string) *StructuredError {
+	return New(message).
+		WithCode("E_LOAD").
+		WithTags("storage", "retryable").
+		WithAttrs(
+			String("user_id", comparisonUserID),
+			Int("retries", comparisonRetries),
+			Int64("size", 1<<20),
+			Float64("ratio", comparisonRatio),
+			Bool("temporary", true),
+			Duration("elapsed", 150*time.Millisecond),
+			Time("created_at", comparisonCreatedAt),
+			Strings("regions", "us-east-1", "eu-west-1"),
+			Object("request", String("method", "GET"), Int("status", 503)),
+		)
+}
+
+// comparisonFields returns the fields of comparisonStructured as a map, the way they are
+// usually logged alongside an error of the standard library or pkg/errors.
+func comparisonFields(err error) map[string]any {
+	return map[string]any{
+		"message":    err.Error(),
+		"code":       "E_LOAD",
+		"tags":       []string{"storage", "retryable"},
+		"user_id":    comparisonUserID,
+		"retries":    comparisonRetries,
+		"size":       1 << 20,
+		"ratio":      comparisonRatio,
+		"temporary":  true,
+		"elapsed":    150 * time.Millisecond,
+		"created_at": comparisonCreatedAt,
+		"regions":    []string{"us-east-1", "eu-west-1"},
+		"request":    map[string]any{"method": "GET", "status": 503},
+	}
+}
+
+func BenchmarkComparisonNewError(b *testing.B) {
+	b.Run("structured", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			comparisonSink = comparisonStructured("failed to load user").Error()
+		}
+	})
+
+	b.Run("stdlib_errors_new", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			comparisonSink = stderrors.New("failed to load user").Error()
+		}
+	})
+
+	b.Run("stdlib_fmt_errorf", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			comparisonSink = fmt.Errorf(
+				"failed to load user: user_id=%s retries=%d temporary=%t elapsed=%s created_at=%s",
+				comparisonUserID, comparisonRetries, true, 150*time.Millisecond, comparisonCreatedAt,
+			).Error()
+		}
+	})
+
+	b.Run("pkg_errors", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			comparisonSink = pkgerrors.Errorf(
+				"failed to load user: user_id=%s retries=%d temporary=%t elapsed=%s created_at=%s",
+				comparisonUserID, comparisonRetries, true, 150*time.Millisecond, comparisonCreatedAt,
+			).Error()
+		}
+	})
+}
+
+func BenchmarkComparisonMarshalJSON(b *testing.B) {
+	b.Run("structured", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			comparisonSink, _ = comparisonStructured("failed to load user").MarshalJSON()
+		}
+	})
+
+	b.Run("stdlib_errors_new", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			comparisonSink, _ = json.Marshal(comparisonFields(stderrors.New("failed to load user")))
+		}
+	})
+
+	b.Run("pkg_errors", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			comparisonSink, _ = json.Marshal(comparisonFields(pkgerrors.New("failed to load user")))
+		}
+	})
+}
+
+// BenchmarkComparisonJoined compares an error with comparisonChildren children.
+// The standard library and pkg/errors have no join on Go 1.18, so their children are a chain of wraps.
+func BenchmarkComparisonJoined(b *testing.B) {
+	b.Run("structured", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			errs := make([]error, zero, comparisonChildren)
+			for index := zero; index < comparisonChildren; index++ {
+				errs = append(errs, comparisonStructured("child "+strconv.Itoa(index)))
+			}
+
+			comparisonSink = Join(errs...).Error()
+		}
+	})
+
+	b.Run("structured_marshal_json", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			errs := make([]error, zero, comparisonChildren)
+			for index := zero; index < comparisonChildren; index++ {
+				errs = append(errs, comparisonStructured("child "+strconv.Itoa(index)))
+			}
+
+			comparisonSink, _ = Join(errs...).(*StructuredError).MarshalJSON() //nolint:forcetypeassert,errcheck // Join returns *StructuredError
+		}
+	})
+
+	b.Run("stdlib_fmt_errorf", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			err := stderrors.New("leaf")
+			for index := zero; index < comparisonChildren; index++ {
+				err = fmt.Errorf("child %d: user_id=%s retries=%d: %w", index, comparisonUserID, comparisonRetries, err)
+			}
+
+			comparisonSink = err.Error()
+		}
+	})
+
+	b.Run("pkg_errors", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			err := pkgerrors.New("leaf")
+			for index := zero; index < comparisonChildren; index++ {
+				err = pkgerrors.Wrapf(err, "child %d: user_id=%s retries=%d", index, comparisonUserID, comparisonRetries)
+			}
+
+			comparisonSink = err.Error()
+		}
+	})
+}
@@ -0,0 +1,315 @@
+package errors
+
+import (
+	stderrors "errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredErrorMarshalCBORRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	tests := []struct {
+		name string
+		// given
+		attr Attr
+	}{
+		{name: "given_bool_attr_when_round_trip_then_keeps_type", attr: Bool("key", true)},
+		{name: "given_bools_attr_when_round_trip_then_keeps_type", attr: Bools("key", true, false)},
+		{name: "given_duration_attr_when_round_trip_then_keeps_type", attr: Duration("key", time.Second)},
+		{name: "given_durations_attr_when_round_trip_then_keeps_type", attr: Durations("key", time.Second, time.Hour)},
+		{name: "given_int_attr_when_round_trip_then_keeps_type", attr: Int("key", 42)},
+		{name: "given_ints_attr_when_round_trip_then_keeps_type", attr: Ints("key", 1, -2)},
+		{name: "given_int64_attr_when_round_trip_then_keeps_type", attr: Int64("key", math.MaxInt64)},
+		{name: "given_int64s_attr_when_round_trip_then_keeps_type", attr: Int64s("key", math.MinInt64, 7)},
+		{name: "given_uint64_attr_when_round_trip_then_keeps_type", attr: Uint64("key", math.MaxUint64)},
+		{name: "given_uint64s_attr_when_round_trip_then_keeps_type", attr: Uint64s("key", 1, 2)},
+		{name: "given_float64_attr_when_round_trip_then_keeps_type", attr: Float64("key", 3)},
+		{name: "given_float64s_attr_when_round_trip_then_keeps_type", attr: Float64s("key", 1.5, 2)},
+		{name: "given_string_attr_when_round_trip_then_keeps_type", attr: String("key", "value")},
+		{name: "given_strings_attr_when_round_trip_then_keeps_type", attr: Strings("key", "a", "b")},
+		{name: "given_rune_attr_when_round_trip_then_keeps_type", attr: Rune("key", 'é')},
+		{name: "given_complex128_attr_when_round_trip_then_keeps_type", attr: Complex128("key", complex(1.5, -2))},
+		{name: "given_any_attr_when_round_trip_then_keeps_type", attr: Any("key", "value")},
+		{
+			name: "given_object_attr_when_round_trip_then_keeps_nested_types",
+			attr: Object("key", Int64("id", 7), Object("inner", Uint64("count", 1))),
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				err := New("test").WithAttrs(test.attr)
+
+				// when
+				data, errM := err.MarshalCBOR()
+				require.NoError(t, errM)
+
+				var got StructuredError
+
+				errU := got.UnmarshalCBOR(data)
+
+				// then
+				require.NoError(t, errU)
+				require.Len(t, got.Attrs, 1)
+				assert.Equal(t, test.attr.Type, got.Attrs[0].Type)
+				assert.Equal(t, test.attr, got.Attrs[0])
+			},
+		)
+	}
+
+	t.Run(
+		"given_time_attr_when_round_trip_then_keeps_instant", func(t *testing.T) {
+			t.Parallel()
+
+			// given
+			err := New("test").WithAttrs(Time("key", now), Times("keys", now, now.Add(time.Hour)))
+
+			// when
+			data, errM := err.MarshalCBOR()
+			require.NoError(t, errM)
+
+			var got StructuredError
+
+			errU := got.UnmarshalCBOR(data)
+
+			// then
+			require.NoError(t, errU)
+			require.Len(t, got.Attrs, 2)
+			assert.Equal(t, TimeType, got.Attrs[0].Type)
+			assert.True(t, now.Equal(got.Attrs[0].Value.(time.Time)))
+			assert.Equal(t, TimesType, got.Attrs[1].Type)
+			assert.Len(t, got.Attrs[1].Value, 2)
+		},
+	)
+}
+
+func TestStructuredErrorMarshalCBORKeepsNumberTypes(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(Int64("int64", 1<<53+1), Float64("float64", 2), Int64("small", 2))
+
+	// when
+	data, errM := err.MarshalCBOR()
+	require.NoError(t, errM)
+
+	var got StructuredError
+
+	errU := got.UnmarshalCBOR(data)
+
+	// then
+	require.NoError(t, errU)
+	assert.Equal(t, []Attr{Int64("int64", 1<<53+1), Float64("float64", 2), Int64("small", 2)}, got.Attrs)
+	assert.IsType(t, int64(0), got.Attrs[0].Value)
+	assert.IsType(t, float64(0), got.Attrs[1].Value)
+	assert.IsType(t, int64(0), got.Attrs[2].Value)
+}
+
+func TestStructuredErrorMarshalCBORIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithCode("CODE").WithTags("tag").WithAttrs(Object("object", String("b", "1"), String("a", "2")))
+
+	// when
+	first, errF := err.MarshalCBOR()
+	require.NoError(t, errF)
+
+	second, errS := err.Clone().MarshalCBOR()
+	require.NoError(t, errS)
+
+	// then
+	assert.Equal(t, first, second)
+}
+
+func TestStructuredErrorMarshalCBORFields(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("parent").
+		WithCode("INTERNAL").
+		WithTags("tag1", "tag2").
+		WithAttrs(Int64("id", 123456789012)).
+		WithStack([]byte("stack trace")).
+		WithErrors(
+			New("child").WithAttrs(Int("retries", 3)),
+			stderrors.New("std child"),
+		)
+
+	// when
+	data, errM := err.MarshalCBOR()
+	require.NoError(t, errM)
+
+	var got StructuredError
+
+	errU := cbor.Unmarshal(data, &got)
+
+	// then
+	require.NoError(t, errU)
+	assert.Equal(t, "parent", got.Message)
+	assert.Equal(t, "INTERNAL", got.Code)
+	assert.Equal(t, []string{"tag1", "tag2"}, got.Tags)
+	assert.Equal(t, []Attr{Int64("id", 123456789012)}, got.Attrs)
+	assert.Equal(t, []byte("stack trace"), got.Stack)
+	assert.Equal(
+		t, []error{
+			New("child").WithAttrs(Int("retries", 3)),
+			New("std child"),
+		}, got.Errors,
+	)
+}
+
+func TestStructuredErrorMarshalCBORFieldMap(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithTags("tag").WithAttrs(Int("key", 1))
+
+	// when
+	data, errM := err.MarshalCBOR()
+	require.NoError(t, errM)
+
+	var got map[string]any
+
+	errU := cbor.Unmarshal(data, &got)
+
+	// then
+	require.NoError(t, errU)
+	assert.Equal(t, "test", got["message"])
+	assert.Equal(t, []any{"tag"}, got["tags"])
+	assert.Len(t, got["attrs"], 1)
+	assert.NotContains(t, got, "errors")
+	assert.NotContains(t, got, "stack")
+}
+
+func TestStructuredErrorMarshalCBORNil(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var err *StructuredError
+
+	// when
+	data, errM := err.MarshalCBOR()
+	require.NoError(t, errM)
+
+	var got StructuredError
+
+	errU := got.UnmarshalCBOR(data)
+
+	// then
+	require.NoError(t, errU)
+	assert.Equal(t, "!NILVALUE", got.Message)
+}
+
+func TestStructuredErrorMarshalCBORRedactsSensitiveAttrs(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(Sensitive("password", "secret"))
+
+	// when
+	data, errM := err.MarshalCBOR()
+	require.NoError(t, errM)
+
+	var got StructuredError
+
+	errU := got.UnmarshalCBOR(data)
+
+	// then
+	require.NoError(t, errU)
+	assert.Equal(t, []Attr{String("password", "[REDACTED]")}, got.Attrs)
+	assert.Equal(t, "secret", err.Attrs[0].Value)
+}
+
+func TestStructuredErrorMarshalCBORUnsupportedValue(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(Any("key", make(chan int)))
+
+	// when
+	_, got := err.MarshalCBOR()
+
+	// then
+	require.Error(t, got)
+	assert.ErrorIs(t, got, ErrMarshalCBOR)
+}
+
+func TestStructuredErrorUnmarshalCBORInvalid(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var err StructuredError
+
+	// when
+	got := err.UnmarshalCBOR([]byte{0xc1})
+
+	// then
+	require.Error(t, got)
+	assert.ErrorIs(t, got, ErrUnmarshalCBOR)
+}
+
+func TestStructuredErrorMarshalCBORWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	var (
+		data []byte
+		errM error
+	)
+
+	assert.NotPanics(t, func() { data, errM = err.MarshalCBOR() })
+	require.NoError(t, errM)
+
+	var got StructuredError
+
+	errU := got.UnmarshalCBOR(data)
+
+	// then
+	require.NoError(t, errU)
+	assert.Equal(t, []Attr{Any("count", "not an int"), Any("user", "john")}, got.Attrs)
+}
+
+func TestStructuredErrorMarshalCBORWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var err *StructuredError
+
+	// when
+	data, errM := err.MarshalCBOR()
+	require.NoError(t, errM)
+
+	var got StructuredError
+
+	errU := got.UnmarshalCBOR(data)
+
+	// then
+	require.NoError(t, errU)
+	assert.Equal(t, "NULL", got.Message)
+}
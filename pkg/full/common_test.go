@@ -2,9 +2,12 @@ package errors
 
 import (
 	stderrors "errors"
+	"fmt"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMaxDepthMarshal(t *testing.T) { //nolint:paralleltest // SetMaxDepthMarshal is not thread-safe
@@ -518,3 +521,355 @@ func TestOrWithBool(t *testing.T) {
 		)
 	}
 }
+
+func TestSetNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// then
+	assert.Equal(t, "!NILVALUE", NilValue())
+
+	// when
+	SetNilValue("NULL")
+
+	// then
+	assert.Equal(t, "NULL", NilValue())
+
+	// when
+	SetNilValue("")
+
+	// then
+	assert.Equal(t, "!NILVALUE", NilValue())
+}
+
+func TestSetOmitEmpty(t *testing.T) { //nolint:paralleltest // SetOmitEmpty is not thread-safe
+	t.Cleanup(
+		func() {
+			SetOmitEmpty(true)
+		},
+	)
+
+	// then
+	assert.True(t, OmitEmpty())
+	assert.False(t, keepField(0))
+	assert.True(t, keepField(1))
+
+	// when
+	SetOmitEmpty(false)
+
+	// then
+	assert.False(t, OmitEmpty())
+	assert.True(t, keepField(0))
+	assert.True(t, keepField(1))
+}
+
+func TestSetTrimMessages(t *testing.T) { //nolint:paralleltest // SetTrimMessages is not thread-safe
+	t.Cleanup(
+		func() {
+			SetTrimMessages(false)
+		},
+	)
+
+	// then
+	assert.False(t, TrimMessages())
+	assert.Equal(t, "  msg  ", trimmedMessage("  msg  "))
+	assert.Equal(t, "  msg  ", New("  msg  ").Message)
+
+	// when
+	SetTrimMessages(true)
+
+	// then
+	assert.True(t, TrimMessages())
+	assert.Equal(t, "msg", trimmedMessage("  msg  "))
+	assert.Equal(t, "msg", New("  msg  ").Message)
+	assert.Equal(t, "msg 1", Newf("  msg %d  ", 1).Message)
+}
+
+func TestStructuredErrorNormalizedErrors(t *testing.T) { //nolint:paralleltest // SetMaxDepthMarshal is not thread-safe
+	first := New("first")
+	second := New("second")
+	third := New("third")
+
+	tests := []struct {
+		mutate func(err *StructuredError)
+		name   string
+		want   []error
+	}{
+		{
+			name:   "given_unchanged_errors_when_normalized_errors_then_returns_errors",
+			mutate: func(*StructuredError) {},
+			want:   []error{first, second},
+		},
+		{
+			name: "given_appended_errors_when_normalized_errors_then_returns_appended_errors",
+			mutate: func(err *StructuredError) {
+				err.AppendErrors(third)
+			},
+			want: []error{first, second, third},
+		},
+		{
+			name: "given_reassigned_errors_when_normalized_errors_then_returns_new_errors",
+			mutate: func(err *StructuredError) {
+				err.Errors = []error{third}
+			},
+			want: []error{third},
+		},
+		{
+			name: "given_replaced_error_when_normalized_errors_then_returns_new_error",
+			mutate: func(err *StructuredError) {
+				err.Errors[1] = third
+			},
+			want: []error{first, third},
+		},
+		{
+			name: "given_joined_errors_when_normalized_errors_then_flattens",
+			mutate: func(err *StructuredError) {
+				err.Errors = []error{Join(first, third)}
+			},
+			want: []error{first, third},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				// given
+				err := New("parent").WithErrors(first, second)
+				_ = err.normalizedErrors()
+
+				// when
+				test.mutate(err)
+				got := err.normalizedErrors()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+
+	t.Run(
+		"given_max_depth_changed_when_normalized_errors_then_uses_new_max_depth", func(t *testing.T) {
+			t.Cleanup(func() { SetMaxDepthMarshal(100) })
+
+			// given
+			grandchild := New("grandchild")
+			err := New("parent").WithErrors(New("child").WithErrors(grandchild))
+			before := err.normalizedErrors()
+
+			// when
+			SetMaxDepthMarshal(0)
+			got := err.normalizedErrors()
+
+			// then
+			assert.Equal(t, []error{grandchild}, before[0].(*StructuredError).Errors)    //nolint:errorlint,forcetypeassert,errcheck // built above
+			assert.Equal(t, []error{ErrDepthExceeded}, got[0].(*StructuredError).Errors) //nolint:errorlint,forcetypeassert,errcheck // built above
+		},
+	)
+}
+
+func TestStructuredErrorNormalizedErrorsAfterNestedMutation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		mutate       func(child *StructuredError)
+		child        func() *StructuredError
+		name         string
+		wantContains string
+	}{
+		{
+			name: "given_child_with_errors_when_tagged_after_marshal_then_marshals_tag",
+			child: func() *StructuredError {
+				return New("child").WithErrors(New("grandchild"))
+			},
+			mutate: func(child *StructuredError) {
+				child.WithTags("late")
+			},
+			wantContains: "late",
+		},
+		{
+			name: "given_child_without_errors_when_wrapping_after_marshal_then_marshals_errors",
+			child: func() *StructuredError {
+				return New("child")
+			},
+			mutate: func(child *StructuredError) {
+				child.WithErrors(New("late"))
+			},
+			wantContains: "late",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				child := test.child()
+				parent := New("parent").WithErrors(child)
+				_ = parent.Error()
+
+				// when
+				test.mutate(child)
+				got, err := parent.MarshalJSON()
+
+				// then
+				require.NoError(t, err)
+				assert.Contains(t, parent.Error(), test.wantContains)
+				assert.Contains(t, string(got), test.wantContains)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorDeepEqualAfterMarshal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		marshal func(err *StructuredError)
+		name    string
+	}{
+		{
+			name: "given_equal_errors_when_one_is_stringified_then_they_are_still_deep_equal",
+			marshal: func(err *StructuredError) {
+				_ = err.Error()
+			},
+		},
+		{
+			name: "given_equal_errors_when_one_is_marshaled_to_json_then_they_are_still_deep_equal",
+			marshal: func(err *StructuredError) {
+				_, _ = err.MarshalJSON()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				err := New("x").WithErrors(io.EOF)
+				other := New("x").WithErrors(io.EOF)
+
+				// when
+				test.marshal(err)
+
+				// then
+				assert.Equal(t, other, err)
+			},
+		)
+	}
+}
+
+func TestNormalizeErrorsWrappedStructuredError(t *testing.T) {
+	t.Parallel()
+
+	child := New("child").WithErrors(New("grandchild"))
+
+	tests := []struct {
+		name string
+		// given
+		err error
+		// then
+		want []error
+	}{
+		{
+			name: "given_fmt_wrapped_structured_error_when_normalize_errors_then_normalizes_structured_error",
+			err:  fmt.Errorf("wrapped: %w", child),
+			want: []error{&StructuredError{Message: "child", Errors: []error{New("grandchild")}}},
+		},
+		{
+			name: "given_error_with_as_method_when_normalize_errors_then_normalizes_structured_error",
+			err:  asStructured{err: child},
+			want: []error{&StructuredError{Message: "child", Errors: []error{New("grandchild")}}},
+		},
+		{
+			name: "given_fmt_wrapped_leaf_error_when_normalize_errors_then_unwraps_error",
+			err:  fmt.Errorf("wrapped: %w", io.EOF),
+			want: []error{io.EOF},
+		},
+		{
+			name: "given_typed_nil_structured_error_when_normalize_errors_then_adds_it",
+			err:  (*StructuredError)(nil),
+			want: []error{(*StructuredError)(nil)},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				target := &normalizerTarget{errs: make([]error, 0)}
+
+				// when
+				normalizeErrors(0, target, test.err)
+
+				// then
+				assert.Equal(t, test.want, target.errs)
+			},
+		)
+	}
+}
+
+// asStructured is an error that is only found as a StructuredError through its As method.
+type asStructured struct {
+	err *StructuredError
+}
+
+func (receiver asStructured) Error() string {
+	return "as structured"
+}
+
+func (receiver asStructured) As(target any) bool {
+	structured, ok := target.(**StructuredError)
+	if ok {
+		*structured = receiver.err
+	}
+
+	return ok
+}
+
+func TestStackLines(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		stack []byte
+		want  []string
+	}{
+		{
+			name:  "given_empty_stack_when_stack_lines_then_returns_nil",
+			stack: nil,
+			want:  nil,
+		},
+		{
+			name:  "given_multi_line_stack_when_stack_lines_then_returns_lines",
+			stack: []byte("line1\nline2"),
+			want:  []string{"line1", "line2"},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := stackLines(test.stack)
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
@@ -3,6 +3,7 @@ package errors
 import (
 	stderrors "errors"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -345,6 +346,15 @@ func TestCompatibilityIsWithSameInstance(t *testing.T) {
 			},
 			wantMatch: true,
 		},
+		{
+			name: "given_sentinel_in_nested_custom_wrappers_when_using_is_then_matches",
+			buildErr: func() (error, error) {
+				wrapped := New("outer").WithErrors(New("wrapper").WithErrors(io.EOF))
+
+				return wrapped, io.EOF
+			},
+			wantMatch: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -468,6 +478,14 @@ func TestCompatibilityUnwrap(t *testing.T) {
 			},
 			wantUnwrapNil: true,
 		},
+		{
+			name: "given_custom_with_sentinel_when_unwrapping_then_returns_nil",
+			buildErr: func() error {
+				// Only the Unwrap() []error form is implemented, even if the error is not joined
+				return New("wrapper").WithErrors(io.EOF)
+			},
+			wantUnwrapNil: true,
+		},
 		{
 			name: "given_std_error_when_unwrapping_then_returns_nil",
 			buildErr: func() error {
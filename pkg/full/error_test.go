@@ -1,8 +1,13 @@
 package errors
 
 import (
+	"context"
 	stderrors "errors"
+	"fmt"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -177,6 +182,329 @@ func TestStructuredErrorWithTags(t *testing.T) {
 	}
 }
 
+func TestStructuredErrorWithTagsDeduplicates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		initialError *StructuredError
+		tags         []string
+		// then
+		want []string
+	}{
+		{
+			name:         "given_existing_tag_when_with_tags_then_skips_it",
+			initialError: New("test").WithTags("a"),
+			tags:         []string{"a", "b"},
+			want:         []string{"b", "a"},
+		},
+		{
+			name:         "given_repeated_tags_when_with_tags_then_keeps_first_seen",
+			initialError: New("test"),
+			tags:         []string{"a", "b", "a", "b", "c"},
+			want:         []string{"a", "b", "c"},
+		},
+		{
+			name:         "given_only_existing_tags_when_with_tags_then_keeps_order",
+			initialError: New("test").WithTags("x", "a"),
+			tags:         []string{"a", "x"},
+			want:         []string{"x", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.initialError.WithTags(test.tags...)
+
+				// then
+				assert.Equal(t, test.want, got.Tags)
+			},
+		)
+	}
+
+	t.Run(
+		"given_multiple_calls_when_with_tags_then_duplicates_collapse_to_one", func(t *testing.T) {
+			t.Parallel()
+
+			// when
+			got := New("test").WithTags("a").WithTags("a", "b").WithTags("b", "c", "a")
+
+			// then
+			assert.Equal(t, []string{"c", "b", "a"}, got.Tags)
+		},
+	)
+}
+
+func TestStructuredErrorWithTagsIf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		cond bool
+		// then
+		want []string
+	}{
+		{
+			name: "given_true_cond_when_with_tags_if_then_adds_tags",
+			cond: true,
+			want: []string{"retryable", "existing"},
+		},
+		{
+			name: "given_false_cond_when_with_tags_if_then_keeps_tags",
+			cond: false,
+			want: []string{"existing"},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				err := New("test").WithTags("existing")
+
+				// when
+				got := err.WithTagsIf(test.cond, "retryable")
+
+				// then
+				assert.Same(t, err, got)
+				assert.Equal(t, test.want, got.Tags)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorWithAttrsIf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		cond bool
+		// then
+		want []Attr
+	}{
+		{
+			name: "given_true_cond_when_with_attrs_if_then_sets_attrs",
+			cond: true,
+			want: []Attr{Int("retries", 3)},
+		},
+		{
+			name: "given_false_cond_when_with_attrs_if_then_keeps_attrs",
+			cond: false,
+			want: []Attr{String("existing", "value")},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				err := New("test").WithAttrs(String("existing", "value"))
+
+				// when
+				got := err.WithAttrsIf(test.cond, Int("retries", 3))
+
+				// then
+				assert.Same(t, err, got)
+				assert.Equal(t, test.want, got.Attrs)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorWithAttrsMap(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		// given
+		attrs map[string]any
+		// then
+		want []Attr
+	}{
+		{
+			name:  "given_nil_map_when_with_attrs_map_then_keeps_attrs",
+			attrs: nil,
+			want:  []Attr{String("existing", "value")},
+		},
+		{
+			name: "given_map_when_with_attrs_map_then_appends_attrs_sorted_by_key",
+			attrs: map[string]any{
+				"c": "three",
+				"a": "one",
+				"b": "two",
+			},
+			want: []Attr{String("existing", "value"), String("a", "one"), String("b", "two"), String("c", "three")},
+		},
+		{
+			name: "given_map_with_known_scalars_when_with_attrs_map_then_infers_types",
+			attrs: map[string]any{
+				"bool":     true,
+				"duration": time.Second,
+				"float":    1.5,
+				"int":      1,
+				"int64":    int64(2),
+				"string":   "value",
+				"time":     now,
+				"uint64":   uint64(3),
+			},
+			want: []Attr{
+				String("existing", "value"),
+				Bool("bool", true),
+				Duration("duration", time.Second),
+				Float64("float", 1.5),
+				Int("int", 1),
+				Int64("int64", 2),
+				String("string", "value"),
+				Time("time", now),
+				Uint64("uint64", 3),
+			},
+		},
+		{
+			name: "given_map_with_unknown_types_when_with_attrs_map_then_uses_any",
+			attrs: map[string]any{
+				"int32": int32(1),
+				"nil":   nil,
+				"map":   map[string]int{"a": 1},
+			},
+			want: []Attr{
+				String("existing", "value"),
+				Any("int32", int32(1)),
+				Any("map", map[string]int{"a": 1}),
+				Any("nil", nil),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				err := New("test").WithAttrs(String("existing", "value"))
+
+				// when
+				got := err.WithAttrsMap(test.attrs)
+
+				// then
+				assert.Same(t, err, got)
+				assert.Equal(t, test.want, got.Attrs)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorWithTraceID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  *StructuredError
+		name string
+		// then
+		want []Attr
+	}{
+		{
+			name: "given_error_without_trace_id_when_with_trace_id_then_appends_attr",
+			err:  New("test").WithAttrs(String("existing", "value")),
+			want: []Attr{String("existing", "value"), String(TraceIDKey, "abc")},
+		},
+		{
+			name: "given_error_with_trace_id_when_with_trace_id_then_replaces_attr",
+			err:  New("test").WithAttrs(String(TraceIDKey, "old"), String("existing", "value")),
+			want: []Attr{String(TraceIDKey, "abc"), String("existing", "value")},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.WithTraceID("abc")
+
+				// then
+				assert.Same(t, test.err, got)
+				assert.Equal(t, test.want, got.Attrs)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorPropagateTraceID(t *testing.T) {
+	t.Parallel()
+
+	// given
+	untraced := New("untraced")
+	traced := New("traced").WithTraceID("child").WithErrors(New("grandchild"))
+	wrapped := New("wrapped")
+
+	err := New("parent").WithTraceID("parent").WithErrors(
+		untraced,
+		traced,
+		fmt.Errorf("context: %w", wrapped),
+		stderrors.New("plain"),
+	)
+
+	// when
+	got := err.PropagateTraceID()
+
+	// then
+	assert.Same(t, err, got)
+
+	tests := []struct {
+		err  *StructuredError
+		name string
+		want string
+	}{
+		{name: "parent_keeps_its_trace_id", err: err, want: "parent"},
+		{name: "untraced_child_gets_parent_trace_id", err: untraced, want: "parent"},
+		{name: "traced_child_keeps_its_trace_id", err: traced, want: "child"},
+		{name: "grandchild_gets_nearest_trace_id", err: traced.Errors[0].(*StructuredError), want: "child"}, //nolint:errorlint,forcetypeassert,errcheck // built above
+		{name: "wrapped_child_gets_parent_trace_id", err: wrapped, want: "parent"},
+	}
+
+	for _, test := range tests {
+		attr, ok := test.err.GetAttr(TraceIDKey)
+		assert.True(t, ok, test.name)
+		assert.Equal(t, test.want, attr.Value, test.name)
+	}
+}
+
+func TestStructuredErrorPropagateTraceIDWithoutTraceID(t *testing.T) {
+	t.Parallel()
+
+	// given
+	child := New("child")
+	err := New("parent").WithErrors(child)
+
+	// when
+	got := err.PropagateTraceID()
+
+	// then
+	assert.Same(t, err, got)
+	assert.Empty(t, child.Attrs)
+	assert.Nil(t, (*StructuredError)(nil).PropagateTraceID())
+}
+
 func TestStructuredErrorWithErrors(t *testing.T) {
 	t.Parallel()
 
@@ -362,23 +690,38 @@ func TestStructuredErrorPrependErrors(t *testing.T) {
 	tests := []struct {
 		initialError *StructuredError
 		name         string
-		wantFirst    string
 		errs         []error
-		wantErrsLen  int
+		want         []string
 	}{
 		{
 			name:         "given_error_when_prepend_empty_errors_then_no_change",
 			initialError: New("test").WithErrors(stderrors.New("only")),
 			errs:         []error{},
-			wantErrsLen:  1,
-			wantFirst:    "only",
+			want:         []string{"only"},
 		},
 		{
-			name:         "given_error_with_existing_errors_when_prepend_errors_then_appends_existing_to_new_slice",
-			initialError: New("test").WithErrors(stderrors.New("existing")),
+			name:         "given_error_with_existing_errors_when_prepend_errors_then_new_errors_come_first",
+			initialError: New("test").WithErrors(stderrors.New("existing1"), stderrors.New("existing2")),
+			errs:         []error{stderrors.New("prepended1"), stderrors.New("prepended2")},
+			want:         []string{"prepended1", "prepended2", "existing1", "existing2"},
+		},
+		{
+			name:         "given_error_without_errors_when_prepend_errors_then_sets_errors",
+			initialError: New("test"),
 			errs:         []error{stderrors.New("prepended")},
-			wantErrsLen:  1,
-			wantFirst:    "existing",
+			want:         []string{"prepended"},
+		},
+		{
+			name:         "given_nil_errors_when_prepend_errors_then_drops_nils",
+			initialError: New("test").WithErrors(stderrors.New("existing")),
+			errs:         []error{nil, stderrors.New("prepended"), nil},
+			want:         []string{"prepended", "existing"},
+		},
+		{
+			name:         "given_only_nil_errors_when_prepend_errors_then_no_change",
+			initialError: New("test").WithErrors(stderrors.New("existing")),
+			errs:         []error{nil, nil},
+			want:         []string{"existing"},
 		},
 	}
 
@@ -393,12 +736,13 @@ func TestStructuredErrorPrependErrors(t *testing.T) {
 
 				// then
 				assert.NotNil(t, got)
-				assert.Len(t, got.Errors, test.wantErrsLen)
 
-				if test.wantErrsLen > 0 {
-					assert.Equal(t, test.wantFirst, got.Errors[0].Error())
+				messages := make([]string, 0, len(got.Errors))
+				for _, err := range got.Errors {
+					messages = append(messages, err.Error())
 				}
 
+				assert.Equal(t, test.want, messages)
 				assert.Same(t, test.initialError, got) // Should return same instance
 			},
 		)
@@ -418,6 +762,21 @@ func TestStructuredErrorUnwrap(t *testing.T) {
 			err:     New("test"),
 			wantLen: 0,
 		},
+		{
+			name:    "given_nil_error_when_unwrap_then_returns_empty_slice",
+			err:     nil,
+			wantLen: 0,
+		},
+		{
+			name:    "given_error_with_empty_errors_when_unwrap_then_returns_empty_slice",
+			err:     New("test").WithErrors(),
+			wantLen: 0,
+		},
+		{
+			name:    "given_joined_error_when_unwrap_then_returns_all_errors",
+			err:     Join(stderrors.New("err1"), stderrors.New("err2")).(*StructuredError), //nolint:forcetypeassert,errcheck // Join returns *StructuredError
+			wantLen: 2,
+		},
 		{
 			name:    "given_error_with_single_error_when_unwrap_then_returns_single_error",
 			err:     New("test").WithErrors(stderrors.New("child")),
@@ -446,6 +805,10 @@ func TestStructuredErrorUnwrap(t *testing.T) {
 
 				// then
 				assert.Len(t, got, test.wantLen)
+
+				if test.wantLen == 0 {
+					assert.Nil(t, got)
+				}
 			},
 		)
 	}
@@ -534,3 +897,847 @@ func TestStructuredErrorChaining(t *testing.T) {
 		)
 	}
 }
+
+func TestStructuredErrorClone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+	}{
+		{
+			name: "given_nil_error_when_clone_then_returns_nil",
+			err:  nil,
+		},
+		{
+			name: "given_error_with_message_when_clone_then_returns_equal_error",
+			err:  New("test"),
+		},
+		{
+			name: "given_error_with_all_fields_when_clone_then_returns_equal_error",
+			err: New("test").
+				WithTags("tag1", "tag2").
+				WithAttrs(
+					String("key", "value"),
+					Ints("ints", 1, 2),
+					Object("obj", Strings("strings", "a", "b")),
+				).
+				WithErrors(stderrors.New("std"), New("child").WithTags("inner")).
+				WithStack([]byte("stack")),
+		},
+		{
+			name: "given_joined_error_when_clone_then_keeps_joined",
+			err:  Join(New("a"), New("b")).(*StructuredError), //nolint:forcetypeassert,errcheck // test
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.Clone()
+
+				// then
+				assert.Equal(t, test.err, got)
+
+				if test.err != nil {
+					assert.NotSame(t, test.err, got)
+				}
+			},
+		)
+	}
+}
+
+func TestStructuredErrorCloneIsolation(t *testing.T) {
+	t.Parallel()
+
+	// given
+	child := New("child").WithTags("inner")
+	original := New("base").
+		WithTags("tag1", "tag2").
+		WithAttrs(Ints("ints", 1, 2), Object("obj", Strings("strings", "a"))).
+		WithErrors(child).
+		WithStack([]byte("stack"))
+
+	// when
+	clone := original.Clone()
+	clone.Message = "derived"
+	clone.Tags[0] = "changed"
+	clone.Tags = append(clone.Tags, "appended")
+	clone.Attrs[0].Value.([]int)[0] = 100                            //nolint:forcetypeassert,errcheck // test
+	clone.Attrs[1].Value.([]Attr)[0].Value.([]string)[0] = "changed" //nolint:forcetypeassert,errcheck // test
+	clone.Attrs = append(clone.Attrs, String("extra", "value"))
+	clone.Errors[0].(*StructuredError).WithTags("changed") //nolint:forcetypeassert,errcheck // test
+	clone.AppendErrors(stderrors.New("appended"))
+	clone.Stack[0] = 'S'
+
+	// then
+	assert.Equal(t, "base", original.Message)
+	assert.Equal(t, []string{"tag1", "tag2"}, original.Tags)
+	assert.Equal(t, []Attr{Ints("ints", 1, 2), Object("obj", Strings("strings", "a"))}, original.Attrs)
+	assert.Equal(t, []error{child}, original.Errors)
+	assert.Equal(t, []string{"inner"}, child.Tags)
+	assert.Equal(t, []byte("stack"), original.Stack)
+}
+
+func TestStructuredErrorSanitize(t *testing.T) { //nolint:paralleltest // Redact and HideTag are not thread-safe
+	// given
+	Redact("test_sanitize_token")
+	HideTag("test_sanitize_internal")
+	t.Cleanup(
+		func() {
+			delete(sensitiveKeys, "test_sanitize_token")
+			delete(hiddenTags, "test_sanitize_internal")
+		},
+	)
+
+	child := New("child").
+		WithTags("test_sanitize_internal").
+		WithAttrs(String("test_sanitize_token", "abc"), Int("attempt", 2)).
+		WithStack([]byte("child stack"))
+	original := New("base").
+		WithCode("UPSTREAM").
+		WithTags("public", "test_sanitize_internal").
+		WithAttrs(
+			String("user", "john"),
+			Sensitive("password", "secret"),
+			Object("request", String("test_sanitize_token", "abc"), String("path", "/users")),
+		).
+		WithErrors(child, stderrors.New("std")).
+		WithStack([]byte("stack"))
+
+	// when
+	got := original.Sanitize()
+
+	// then
+	assert.True(
+		t,
+		Equal(
+			New("base").
+				WithCode("UPSTREAM").
+				WithTags("public").
+				WithAttrs(String("user", "john"), Object("request", String("path", "/users"))).
+				WithErrors(New("child").WithAttrs(Int("attempt", 2)), stderrors.New("std")),
+			got,
+		),
+	)
+	assert.Nil(t, got.Stack)
+	assert.Nil(t, got.Errors[0].(*StructuredError).Stack) //nolint:forcetypeassert,errcheck // test
+
+	assert.Equal(t, []byte("stack"), original.Stack)
+	assert.Equal(t, []string{"public", "test_sanitize_internal"}, original.Tags)
+	assert.Len(t, original.Attrs, 3)
+	assert.Len(t, original.Attrs[2].Value, 2)
+	assert.Equal(t, []byte("child stack"), child.Stack)
+	assert.Equal(t, []string{"test_sanitize_internal"}, child.Tags)
+	assert.Len(t, child.Attrs, 2)
+}
+
+func TestStructuredErrorSanitizeNil(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var err *StructuredError
+
+	// when
+	got := err.Sanitize()
+
+	// then
+	assert.Nil(t, got)
+}
+
+func TestNewf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		format string
+		args   []any
+		// then
+		want string
+	}{
+		{
+			name:   "given_format_with_args_when_newf_then_formats_message",
+			format: "item %d invalid: %s",
+			args:   []any{42, "missing name"},
+			want:   "item 42 invalid: missing name",
+		},
+		{
+			name:   "given_format_without_args_when_newf_then_keeps_message",
+			format: "plain message",
+			args:   nil,
+			want:   "plain message",
+		},
+		{
+			name:   "given_wrap_verb_when_newf_then_does_not_wrap",
+			format: "failed: %w",
+			args:   []any{stderrors.New("cause")},
+			want:   "failed: %!w(*errors.errorString=&{cause})",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := Newf(test.format, test.args...)
+
+				// then
+				assert.Equal(t, test.want, got.Message)
+				assert.Empty(t, got.Errors)
+			},
+		)
+	}
+}
+
+func TestNewPooled(t *testing.T) {
+	t.Parallel()
+
+	// when
+	got := NewPooled("test")
+	t.Cleanup(func() { Release(got) })
+
+	// then
+	assert.Equal(t, New("test"), got)
+}
+
+func TestRelease(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := NewPooled("test").
+		WithCode("CODE").
+		WithAttrs(String("key", "value")).
+		WithTags("tag").
+		WithErrors(stderrors.New("child")).
+		WithStack([]byte("stack")).
+		CaptureStack()
+	err.joined = true
+
+	// when
+	Release(err)
+
+	// then
+	assert.Equal(t, &StructuredError{}, err)
+	assert.NotPanics(t, func() { Release(nil) })
+}
+
+//nolint:gochecknoglobals // keeps the benchmarked errors on the heap
+var benchmarkSink error
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		err := New("test").WithAttrs(String("key", "value"))
+		benchmarkSink = err
+	}
+}
+
+func BenchmarkNewPooled(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		err := NewPooled("test").WithAttrs(String("key", "value"))
+		benchmarkSink = err
+		Release(err)
+	}
+}
+
+type contextKey string
+
+func TestStructuredErrorWithContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.WithValue(context.Background(), contextKey("request_id"), "req-123")
+	ctx = context.WithValue(ctx, contextKey("user_id"), int64(42))
+
+	tests := []struct {
+		name string
+		// given
+		err  *StructuredError
+		ctx  context.Context //nolint:containedctx // test input
+		keys []any
+		// then
+		want []Attr
+	}{
+		{
+			name: "given_context_with_values_when_with_context_then_appends_typed_attrs",
+			err:  New("test"),
+			ctx:  ctx,
+			keys: []any{contextKey("request_id"), contextKey("user_id")},
+			want: []Attr{String("request_id", "req-123"), Int64("user_id", 42)},
+		},
+		{
+			name: "given_existing_attrs_when_with_context_then_appends_after_them",
+			err:  New("test").WithAttrs(Bool("retry", true)),
+			ctx:  ctx,
+			keys: []any{contextKey("user_id")},
+			want: []Attr{Bool("retry", true), Int64("user_id", 42)},
+		},
+		{
+			name: "given_missing_key_when_with_context_then_skips_it",
+			err:  New("test"),
+			ctx:  ctx,
+			keys: []any{contextKey("trace_id"), contextKey("request_id")},
+			want: []Attr{String("request_id", "req-123")},
+		},
+		{
+			name: "given_nil_context_when_with_context_then_skips_all",
+			err:  New("test"),
+			ctx:  nil,
+			keys: []any{contextKey("request_id")},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.WithContext(test.ctx, test.keys...)
+
+				// then
+				assert.Same(t, test.err, got)
+				assert.Equal(t, test.want, got.Attrs)
+			},
+		)
+	}
+}
+
+func TestSetContextExtractor(t *testing.T) { //nolint:paralleltest // SetContextExtractor is not thread-safe
+	// given
+	SetContextExtractor(
+		func(ctx context.Context) []Attr {
+			if traceID, ok := ctx.Value(contextKey("trace_id")).(string); ok {
+				return []Attr{String("trace_id", traceID)}
+			}
+
+			return nil
+		},
+	)
+	t.Cleanup(func() { SetContextExtractor(nil) })
+
+	ctx := context.WithValue(context.Background(), contextKey("trace_id"), "trace-1")
+	ctx = context.WithValue(ctx, contextKey("request_id"), "req-123")
+
+	// when
+	got := New("test").WithContext(ctx, contextKey("request_id"))
+
+	// then
+	assert.Equal(t, []Attr{String("trace_id", "trace-1"), String("request_id", "req-123")}, got.Attrs)
+}
+
+func TestStructuredErrorWithCode(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test")
+
+	// when
+	got := err.WithCode("NOT_FOUND")
+
+	// then
+	assert.Same(t, err, got)
+	assert.Equal(t, "NOT_FOUND", got.Code)
+}
+
+func TestStructuredErrorWithHTTPStatus(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test")
+
+	// when
+	got := err.WithHTTPStatus(404)
+
+	// then
+	assert.Same(t, err, got)
+	assert.Equal(t, 404, got.HTTPStatus)
+	assert.Equal(t, 404, got.Clone().HTTPStatus)
+}
+
+func TestStructuredErrorTagsCopy(t *testing.T) {
+	t.Parallel()
+
+	t.Run(
+		"given_error_with_tags_when_mutating_copy_then_internal_tags_are_untouched", func(t *testing.T) {
+			t.Parallel()
+
+			// given
+			err := New("test").WithTags("a", "b")
+
+			// when
+			got := err.TagsCopy()
+			got[0] = "changed"
+
+			// then
+			assert.Equal(t, []string{"changed", "b"}, got)
+			assert.Equal(t, []string{"a", "b"}, err.Tags)
+		},
+	)
+
+	t.Run(
+		"given_error_without_tags_when_tags_copy_then_returns_nil", func(t *testing.T) {
+			t.Parallel()
+
+			// when
+			got := New("test").TagsCopy()
+
+			// then
+			assert.Nil(t, got)
+		},
+	)
+
+	t.Run(
+		"given_nil_error_when_tags_copy_then_returns_nil", func(t *testing.T) {
+			t.Parallel()
+
+			// given
+			var err *StructuredError
+
+			// when
+			got := err.TagsCopy()
+
+			// then
+			assert.Nil(t, got)
+		},
+	)
+}
+
+func TestStructuredErrorGetAttr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		key string
+		// then
+		want   Attr
+		wantOk bool
+	}{
+		{
+			name:   "given_nil_error_when_get_attr_then_returns_not_found",
+			err:    nil,
+			key:    "key",
+			want:   Attr{},
+			wantOk: false,
+		},
+		{
+			name:   "given_error_without_key_when_get_attr_then_returns_not_found",
+			err:    New("test").WithAttrs(String("other", "value")),
+			key:    "key",
+			want:   Attr{},
+			wantOk: false,
+		},
+		{
+			name:   "given_error_with_duplicate_keys_when_get_attr_then_returns_first",
+			err:    New("test").WithAttrs(String("key", "first"), String("key", "second")),
+			key:    "key",
+			want:   String("key", "first"),
+			wantOk: true,
+		},
+		{
+			name:   "given_error_with_sensitive_attr_when_get_attr_then_returns_raw_value",
+			err:    New("test").WithAttrs(Sensitive("password", "secret")),
+			key:    "password",
+			want:   Sensitive("password", "secret"),
+			wantOk: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got, ok := test.err.GetAttr(test.key)
+
+				// then
+				assert.Equal(t, test.wantOk, ok)
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	utc := now.UTC().Round(0)
+	sentinel := stderrors.New("sentinel")
+
+	tests := []struct {
+		a    *StructuredError
+		b    *StructuredError
+		name string
+		// then
+		want               bool
+		wantIgnoreTagOrder bool
+	}{
+		{
+			name:               "given_nil_errors_when_equal_then_returns_true",
+			want:               true,
+			wantIgnoreTagOrder: true,
+		},
+		{
+			name:               "given_nil_and_non_nil_errors_when_equal_then_returns_false",
+			a:                  New("test"),
+			want:               false,
+			wantIgnoreTagOrder: false,
+		},
+		{
+			name: "given_equal_errors_when_equal_then_returns_true",
+			a: New("test").WithCode("CODE").WithTags("a", "b").WithAttrs(
+				Time("at", now), Times("times", now), Object("user", Int("id", 1), Strings("roles", "admin")),
+			).WithErrors(New("child").WithAttrs(String("key", "value")), stderrors.New("std"), sentinel),
+			b: New("test").WithCode("CODE").WithTags("a", "b").WithAttrs(
+				Time("at", utc), Times("times", utc), Object("user", Int("id", 1), Strings("roles", "admin")),
+			).WithErrors(New("child").WithAttrs(String("key", "value")), stderrors.New("std"), sentinel),
+			want:               true,
+			wantIgnoreTagOrder: true,
+		},
+		{
+			name:               "given_joined_and_built_errors_with_same_fields_when_equal_then_returns_true",
+			a:                  Join(New("first"), New("second")).(*StructuredError),
+			b:                  (&StructuredError{}).WithErrors(New("first"), New("second")),
+			want:               true,
+			wantIgnoreTagOrder: true,
+		},
+		{
+			name:               "given_different_tag_order_when_equal_then_depends_on_tag_order",
+			a:                  New("test").WithTags("a", "b"),
+			b:                  New("test").WithTags("b", "a"),
+			want:               false,
+			wantIgnoreTagOrder: true,
+		},
+		{
+			name:               "given_different_tags_when_equal_then_returns_false",
+			a:                  New("test").WithTags("a", "b"),
+			b:                  New("test").WithTags("a", "c"),
+			want:               false,
+			wantIgnoreTagOrder: false,
+		},
+		{
+			name:               "given_different_attr_value_when_equal_then_returns_false",
+			a:                  New("test").WithAttrs(Int("count", 1)),
+			b:                  New("test").WithAttrs(Int("count", 2)),
+			want:               false,
+			wantIgnoreTagOrder: false,
+		},
+		{
+			name:               "given_different_attr_type_when_equal_then_returns_false",
+			a:                  New("test").WithAttrs(Int("count", 1)),
+			b:                  New("test").WithAttrs(Int64("count", 1)),
+			want:               false,
+			wantIgnoreTagOrder: false,
+		},
+		{
+			name:               "given_different_nested_attr_value_when_equal_then_returns_false",
+			a:                  New("test").WithErrors(New("child").WithAttrs(Object("user", Int("id", 1)))),
+			b:                  New("test").WithErrors(New("child").WithAttrs(Object("user", Int("id", 2)))),
+			want:               false,
+			wantIgnoreTagOrder: false,
+		},
+		{
+			name:               "given_different_std_error_types_when_equal_then_returns_false",
+			a:                  New("test").WithErrors(stderrors.New("std")),
+			b:                  New("test").WithErrors(New("std")),
+			want:               false,
+			wantIgnoreTagOrder: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := Equal(test.a, test.b)
+				gotIgnoreTagOrder := EqualIgnoringTagOrder(test.a, test.b)
+
+				// then
+				assert.Equal(t, test.want, got)
+				assert.Equal(t, test.want, Equal(test.b, test.a))
+				assert.Equal(t, test.wantIgnoreTagOrder, gotIgnoreTagOrder)
+			},
+		)
+	}
+}
+
+func TestNewSafe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		want *StructuredError
+	}{
+		{
+			name: "given_nil_error_when_new_safe_then_guards_empty_error",
+			err:  nil,
+			want: &StructuredError{},
+		},
+		{
+			name: "given_error_when_new_safe_then_guards_it",
+			err:  New("test").WithCode("CODE"),
+			want: New("test").WithCode("CODE"),
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := NewSafe(test.err)
+
+				// then
+				assert.Equal(t, test.want, got.Snapshot())
+			},
+		)
+	}
+}
+
+func TestSafeErrorBuilders(t *testing.T) {
+	t.Parallel()
+
+	// given
+	safe := NewSafe(New("test").WithAttrs(String("first", "value")))
+
+	// when
+	got := safe.
+		WithCode("CODE").
+		AddAttrs(Int("second", 2)).
+		WithTags("tag").
+		AppendErrors(stderrors.New("child")).
+		Update(func(err *StructuredError) { err.Message = "updated" })
+
+	// then
+	assert.Same(t, safe, got)
+
+	snapshot := safe.Snapshot()
+	assert.Equal(t, "updated", snapshot.Message)
+	assert.Equal(t, "CODE", snapshot.Code)
+	assert.Equal(t, []Attr{String("first", "value"), Int("second", 2)}, snapshot.Attrs)
+	assert.Equal(t, []string{"tag"}, snapshot.Tags)
+	assert.Len(t, snapshot.Errors, 1)
+	assert.Equal(t, snapshot.Error(), safe.Error())
+
+	snapshot.Message = "changed"
+	assert.Equal(t, "updated", safe.Snapshot().Message)
+}
+
+// TestSafeErrorConcurrent is meant to run with -race, as make test does.
+func TestSafeErrorConcurrent(t *testing.T) {
+	t.Parallel()
+
+	// given
+	const goroutines = 50
+
+	safe := NewSafe(New("aggregated"))
+
+	var waitGroup sync.WaitGroup
+
+	waitGroup.Add(goroutines)
+
+	// when
+	for index := 0; index < goroutines; index++ {
+		go func(index int) {
+			defer waitGroup.Done()
+
+			safe.
+				AddAttrs(Int("worker_"+strconv.Itoa(index), index)).
+				WithTags("tag_" + strconv.Itoa(index)).
+				AppendErrors(stderrors.New("failure " + strconv.Itoa(index)))
+
+			_ = safe.Error()
+			_ = safe.Snapshot()
+		}(index)
+	}
+
+	waitGroup.Wait()
+
+	// then
+	got := safe.Snapshot()
+	assert.Len(t, got.Attrs, goroutines)
+	assert.Len(t, got.Tags, goroutines)
+	assert.Len(t, got.Errors, goroutines)
+
+	for index := 0; index < goroutines; index++ {
+		attr, ok := got.GetAttr("worker_" + strconv.Itoa(index))
+		assert.True(t, ok)
+		assert.Equal(t, index, attr.Value)
+	}
+}
+
+func TestRegistryNewFromCode(t *testing.T) {
+	t.Parallel()
+
+	// given
+	registry := NewRegistry()
+	registry.Register("NOT_FOUND", "resource not found")
+	registry.Register("CONFLICT", "resource already exists")
+
+	tests := []struct {
+		name string
+		// given
+		code string
+		// then
+		want *StructuredError
+	}{
+		{
+			name: "given_registered_code_when_new_from_code_then_default_message",
+			code: "NOT_FOUND",
+			want: &StructuredError{Message: "resource not found", Code: "NOT_FOUND"},
+		},
+		{
+			name: "given_other_registered_code_when_new_from_code_then_its_default_message",
+			code: "CONFLICT",
+			want: &StructuredError{Message: "resource already exists", Code: "CONFLICT"},
+		},
+		{
+			name: "given_unregistered_code_when_new_from_code_then_code_as_message",
+			code: "UNKNOWN",
+			want: &StructuredError{Message: "UNKNOWN", Code: "UNKNOWN"},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := registry.NewFromCode(test.code)
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestRegistryRegisterReplacesDefaultMessage(t *testing.T) {
+	t.Parallel()
+
+	// given
+	registry := NewRegistry()
+	registry.Register("NOT_FOUND", "resource not found")
+
+	// when
+	sentinel := registry.Register("NOT_FOUND", "user not found")
+
+	// then
+	assert.Equal(t, &StructuredError{Message: "user not found", Code: "NOT_FOUND"}, sentinel)
+	assert.Equal(t, "user not found", registry.NewFromCode("NOT_FOUND").Message)
+}
+
+func TestRegistryWithTrimMessages(t *testing.T) { //nolint:paralleltest // SetTrimMessages is not thread-safe
+	t.Cleanup(func() { SetTrimMessages(false) })
+
+	// given
+	SetTrimMessages(true)
+
+	registry := NewRegistry()
+
+	// when
+	sentinel := registry.Register("NOT_FOUND", "  resource not found  ")
+	got := registry.NewFromCode("NOT_FOUND")
+
+	// then
+	assert.Equal(t, &StructuredError{Message: "resource not found", Code: "NOT_FOUND"}, sentinel)
+	assert.Equal(t, &StructuredError{Message: "resource not found", Code: "NOT_FOUND"}, got)
+}
+
+func TestRegistrySentinelIs(t *testing.T) {
+	t.Parallel()
+
+	// given
+	registry := NewRegistry()
+	errNotFound := registry.Register("NOT_FOUND", "resource not found")
+	errConflict := registry.Register("CONFLICT", "resource already exists")
+
+	tests := []struct {
+		name string
+		// given
+		err      error
+		sentinel error
+		// then
+		want bool
+	}{
+		{
+			name:     "given_error_from_code_when_is_sentinel_then_true",
+			err:      registry.NewFromCode("NOT_FOUND"),
+			sentinel: errNotFound,
+			want:     true,
+		},
+		{
+			name:     "given_enriched_error_from_code_when_is_sentinel_then_true",
+			err:      registry.NewFromCode("NOT_FOUND").WithAttrs(String("user_id", "123")).WithTags("db"),
+			sentinel: errNotFound,
+			want:     true,
+		},
+		{
+			name:     "given_wrapped_error_from_code_when_is_sentinel_then_true",
+			err:      fmt.Errorf("loading user: %w", registry.NewFromCode("NOT_FOUND")),
+			sentinel: errNotFound,
+			want:     true,
+		},
+		{
+			name:     "given_joined_error_from_code_when_is_sentinel_then_true",
+			err:      New("request failed").WithErrors(stderrors.New("timeout"), registry.NewFromCode("CONFLICT")),
+			sentinel: errConflict,
+			want:     true,
+		},
+		{
+			name:     "given_error_from_other_code_when_is_sentinel_then_false",
+			err:      registry.NewFromCode("CONFLICT"),
+			sentinel: errNotFound,
+			want:     false,
+		},
+		{
+			name:     "given_error_without_code_when_is_sentinel_then_false",
+			err:      New("resource not found"),
+			sentinel: errNotFound,
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := Is(test.err, test.sentinel)
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/rs/zerolog"
+
+	"go.uber.org/zap"
+)
+
+// The examples of this file only use the generated formats,
+// and leave out timestamps and stack traces so their output is deterministic.
+
+func ExampleNew() {
+	err := New("something went wrong")
+
+	fmt.Println(err)
+	// Output: (message=something went wrong)
+}
+
+func ExampleStructuredError_WithAttrs() {
+	err := New("failed to load user").WithAttrs(String("user_id", "123"), Int("retries", 3))
+
+	for _, attr := range err.Attrs {
+		fmt.Println(attr.String())
+	}
+	// Output:
+	// (user_id=123)
+	// (retries=3)
+}
+
+func ExampleStructuredError_MarshalJSON() {
+	err := New("failed to load user").WithAttrs(String("user_id", "123"), Int("retries", 3))
+
+	got, _ := err.MarshalJSON()
+
+	fmt.Println(string(got))
+	// Output: {"message":"failed to load user","attrs":[{"value":"123","key":"user_id","type":16},{"value":3,"key":"retries","type":8}]}
+}
+
+func ExampleStructuredError_MarshalXML() {
+	err := New("failed to load user").WithAttrs(String("user_id", "123"), Int("retries", 3))
+
+	got, _ := xml.Marshal(err)
+
+	fmt.Println(string(got))
+	// Output: <error><message>failed to load user</message><attrs><attr key="user_id">123</attr><attr key="retries">3</attr></attrs></error>
+}
+
+func ExampleStructuredError_MarshalLogfmt() {
+	err := New("failed to load user").WithAttrs(String("user_id", "123"), Int("retries", 3))
+
+	fmt.Println(err.MarshalLogfmt())
+	// Output: message="failed to load user" attrs.user_id=123 attrs.retries=3
+}
+
+func ExampleStructuredError_LogValue() {
+	err := New("failed to load user").WithAttrs(String("user_id", "123"), Int("retries", 3))
+
+	// The time is removed, so the output does not change between runs
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+
+			return attr
+		},
+	}))
+
+	logger.Error("request failed", slog.Any("error", err))
+	// Output: {"level":"ERROR","msg":"request failed","error":{"message":"failed to load user","attrs":{"user_id":"123","retries":3}}}
+}
+
+func ExampleStructuredError_MarshalZerologObject() {
+	err := New("failed to load user").WithAttrs(String("user_id", "123"), Int("retries", 3))
+
+	logger := zerolog.New(os.Stdout)
+
+	logger.Error().Object("error", err).Msg("request failed")
+	// Output: {"level":"error","error":{"message":"failed to load user","attrs":{"user_id":"123","retries":3}},"message":"request failed"}
+}
+
+func ExampleStructuredError_MarshalLogObject() {
+	err := New("failed to load user").WithAttrs(String("user_id", "123"), Int("retries", 3))
+
+	// The example logger writes to stdout without timestamps
+	logger := zap.NewExample()
+
+	logger.Error("request failed", zap.Object("error", err))
+	// Output: {"level":"error","msg":"request failed","error":{"message":"failed to load user","attrs":{"user_id":"123","retries":3}}}
+}
@@ -0,0 +1,240 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	stderrors "errors"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// gobError is the gob representation of a StructuredError.
+	gobError struct {
+		Message    string
+		Code       string
+		HTTPStatus int
+		Tags       []string
+		Attrs      []gobAttr
+		Errors     []*gobError
+		Stack      []byte
+		Frames     []StackFrame
+		Joined     bool
+	}
+
+	// gobAttr is the gob representation of an Attr.
+	// Object values are stored in Attrs instead of Value, so they don't need to be registered.
+	gobAttr struct {
+		Value any
+		Key   string
+		Attrs []gobAttr
+		Type  Type
+	}
+)
+
+var (
+	// ErrGobEncode is returned when encoding to gob fails.
+	ErrGobEncode = New("failed to encode gob")
+
+	// ErrGobDecode is returned when decoding from gob fails.
+	ErrGobDecode = New("failed to decode gob")
+)
+
+//nolint:gochecknoglobals // needed to register the gob types only once
+var (
+	gobRegisterOnce sync.Once
+)
+
+//nolint:errcheck // this is for interface assertion
+var (
+	_ gob.GobEncoder = (*StructuredError)(nil)
+	_ gob.GobDecoder = (*StructuredError)(nil)
+)
+
+// GobEncode implements gob.GobEncoder.
+//
+// It encodes every field of the StructuredError, including whether it was created via Join or JoinIf,
+// and its parsed stack frames. The program counters captured by CaptureStack are not encoded,
+// as they are only meaningful in the process that captured them.
+//
+// The values of the Attrs created via the XXXType helpers are gob-safe, since their concrete types
+// are registered with gob.Register. AnyType values must be of a type gob can encode,
+// and types other than the predeclared ones must be registered with gob.Register on both sides.
+// Sensitive Attrs are encoded with the value "[REDACTED]".
+//
+// Errors that are not a *StructuredError are encoded with their Error() message only,
+// and nil errors with the message nilValue.
+func (receiver *StructuredError) GobEncode() ([]byte, error) {
+	gobRegisterOnce.Do(registerGobTypes)
+
+	var bytesBuffer bytes.Buffer
+
+	err := gob.NewEncoder(&bytesBuffer).Encode(receiver.asGob())
+	if err != nil {
+		return nil, JoinIf(err, ErrGobEncode)
+	}
+
+	return bytesBuffer.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder.
+//
+// It takes a byte slice produced by GobEncode and decodes it into the StructuredError.
+func (receiver *StructuredError) GobDecode(data []byte) error {
+	gobRegisterOnce.Do(registerGobTypes)
+
+	var structured gobError
+
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&structured)
+	if err != nil {
+		return JoinIf(err, ErrGobDecode)
+	}
+
+	structured.fillStructuredError(receiver)
+
+	return nil
+}
+
+// asGob converts the StructuredError into a gobError.
+func (receiver *StructuredError) asGob() *gobError {
+	if receiver == nil {
+		return &gobError{Message: nilValue}
+	}
+
+	structured := &gobError{
+		Message:    trimmedMessage(receiver.Message),
+		Code:       receiver.Code,
+		HTTPStatus: receiver.HTTPStatus,
+		Tags:       receiver.Tags,
+		Attrs:      attrsToGob(withoutOmittedAttrs(receiver.Attrs)),
+		Stack:      receiver.Stack,
+		Frames:     receiver.frames,
+		Joined:     receiver.joined,
+	}
+
+	if len(receiver.Errors) > zero {
+		structured.Errors = make([]*gobError, zero, len(receiver.Errors))
+
+		for _, err := range receiver.Errors {
+			structured.Errors = append(structured.Errors, errorToGob(err))
+		}
+	}
+
+	return structured
+}
+
+// fillStructuredError takes a gobError and fills a StructuredError with the decoded data.
+func (receiver *gobError) fillStructuredError(structured *StructuredError) {
+	structured.Message = receiver.Message
+	structured.Code = receiver.Code
+	structured.HTTPStatus = receiver.HTTPStatus
+	structured.Tags = receiver.Tags
+	structured.Attrs = gobToAttrs(receiver.Attrs)
+	structured.Stack = receiver.Stack
+	structured.frames = receiver.Frames
+	structured.joined = receiver.Joined
+
+	if len(receiver.Errors) > zero {
+		structured.Errors = make([]error, zero, len(receiver.Errors))
+
+		for _, err := range receiver.Errors {
+			_structured := &StructuredError{}
+
+			err.fillStructuredError(_structured)
+
+			structured.Errors = append(structured.Errors, _structured)
+		}
+	}
+}
+
+// errorToGob converts an error into a gobError.
+//
+// If the error is nil, the gobError has the message nilValue.
+//
+// If the error is a *StructuredError, it converts the *StructuredError.
+//
+// If the error is not a *StructuredError, the gobError has the error's Error() message,
+// or nilValue if the message is empty.
+func errorToGob(err error) *gobError {
+	var value *StructuredError
+	switch {
+	case err == nil:
+		return &gobError{Message: nilValue}
+	case stderrors.As(err, &value):
+		return value.asGob()
+	default:
+		errStr := strings.TrimSpace(err.Error())
+
+		return &gobError{Message: cmpOr(errStr, nilValue)}
+	}
+}
+
+// attrsToGob converts a slice of Attr into a slice of gobAttr.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func attrsToGob(attrs []Attr) []gobAttr {
+	if len(attrs) == zero {
+		return nil
+	}
+
+	result := make([]gobAttr, zero, len(attrs))
+
+	for index := range attrs {
+		attr := attrs[index].redacted().resolved()
+
+		if attr.Type == ObjectType {
+			objectAttrs := attrsToGob(attr.Value.([]Attr))
+
+			result = append(result, gobAttr{Key: attr.Key, Attrs: objectAttrs, Type: attr.Type})
+
+			continue
+		}
+
+		result = append(result, gobAttr{Value: attr.Value, Key: attr.Key, Type: attr.Type})
+	}
+
+	return result
+}
+
+// gobToAttrs converts a slice of gobAttr back into a slice of Attr.
+func gobToAttrs(attrs []gobAttr) []Attr {
+	if len(attrs) == zero {
+		return nil
+	}
+
+	result := make([]Attr, zero, len(attrs))
+
+	for _, attr := range attrs {
+		if attr.Type == ObjectType {
+			result = append(result, Attr{Value: gobToAttrs(attr.Attrs), Key: attr.Key, Type: attr.Type})
+
+			continue
+		}
+
+		result = append(result, Attr{Value: attr.Value, Key: attr.Key, Type: attr.Type})
+	}
+
+	return result
+}
+
+// registerGobTypes registers the concrete types of the Attr values created via the XXXType helpers.
+// The predeclared types, like int or string, are registered by gob itself.
+func registerGobTypes() {
+	gob.Register([]bool{})
+	gob.Register(time.Time{})
+	gob.Register([]time.Time{})
+	gob.Register(time.Duration(zero))
+	gob.Register([]time.Duration{})
+	gob.Register([]int{})
+	gob.Register([]int64{})
+	gob.Register([]uint64{})
+	gob.Register([]float64{})
+	gob.Register([]string{})
+	gob.Register(net.IP{})
+	gob.Register(&url.URL{})
+	gob.Register(json.RawMessage{})
+}
@@ -0,0 +1,215 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/gob"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredErrorGobRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	// given
+	attrs := []Attr{
+		Any("any", "value"),
+		Object("object", Int64("id", 7), Object("inner", Strings("names", "a", "b"))),
+		Bool("bool", true),
+		Bools("bools", true, false),
+		Time("time", now),
+		Times("times", now, now.Add(time.Hour)),
+		Duration("duration", time.Second),
+		Durations("durations", time.Second, time.Minute),
+		Int("int", 42),
+		Ints("ints", 1, 2),
+		Int64("int64", 1<<40),
+		Int64s("int64s", -1, 1<<40),
+		Uint64("uint64", 1<<63),
+		Uint64s("uint64s", 1, 1<<63),
+		Float64("float64", 1.5),
+		Float64s("float64s", 1.5, 2),
+		String("string", "value"),
+		Strings("strings", "a", "b"),
+		Rune("rune", 'é'),
+		Complex128("complex128", complex(1.5, -2)),
+	}
+
+	joined := Join(
+		New("first").WithCode("NOT_FOUND").WithTags("tag").WithAttrs(attrs...),
+		New("second").WithStack([]byte("stack trace")).WithErrors(New("nested"), stderrors.New("std")),
+	)
+
+	var buffer bytes.Buffer
+
+	// when
+	errE := gob.NewEncoder(&buffer).Encode(joined)
+	require.NoError(t, errE)
+
+	var got *StructuredError
+
+	errD := gob.NewDecoder(&buffer).Decode(&got)
+
+	// then
+	require.NoError(t, errD)
+	assert.True(t, got.joined)
+	assert.Equal(t, joined.Error(), got.Error())
+	require.Len(t, got.Errors, 2)
+
+	first := got.Errors[0].(*StructuredError) //nolint:forcetypeassert,errcheck // decoded errors are always *StructuredError
+	assert.Equal(t, "NOT_FOUND", first.Code)
+	assert.Equal(t, []string{"tag"}, first.Tags)
+	assert.Equal(t, attrs, first.Attrs)
+
+	second := got.Errors[1].(*StructuredError) //nolint:forcetypeassert,errcheck // decoded errors are always *StructuredError
+	assert.Equal(t, []byte("stack trace"), second.Stack)
+	assert.Equal(t, []error{New("nested"), New("std")}, second.Errors)
+}
+
+func TestStructuredErrorGobFrames(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").CaptureStack()
+
+	// when
+	data, errE := err.GobEncode()
+	require.NoError(t, errE)
+
+	var got StructuredError
+
+	errD := got.GobDecode(data)
+
+	// then
+	require.NoError(t, errD)
+	assert.Equal(t, err.Frames(), got.Frames())
+	assert.Nil(t, got.StackTrace())
+}
+
+func TestStructuredErrorGobNil(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var err *StructuredError
+
+	// when
+	data, errE := err.GobEncode()
+	require.NoError(t, errE)
+
+	var got StructuredError
+
+	errD := got.GobDecode(data)
+
+	// then
+	require.NoError(t, errD)
+	assert.Equal(t, "!NILVALUE", got.Message)
+}
+
+func TestStructuredErrorGobRedactsSensitiveAttrs(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(Sensitive("password", "secret"))
+
+	// when
+	data, errE := err.GobEncode()
+	require.NoError(t, errE)
+
+	var got StructuredError
+
+	errD := got.GobDecode(data)
+
+	// then
+	require.NoError(t, errD)
+	assert.Equal(t, []Attr{String("password", "[REDACTED]")}, got.Attrs)
+	assert.Equal(t, "secret", err.Attrs[0].Value)
+}
+
+func TestStructuredErrorGobEncodeUnregisteredType(t *testing.T) {
+	t.Parallel()
+
+	// given
+	type unregistered struct {
+		Field string
+	}
+
+	err := New("test").WithAttrs(Any("key", unregistered{Field: "value"}))
+
+	// when
+	_, got := err.GobEncode()
+
+	// then
+	require.Error(t, got)
+	assert.ErrorIs(t, got, ErrGobEncode)
+}
+
+func TestStructuredErrorGobDecodeInvalid(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var err StructuredError
+
+	// when
+	got := err.GobDecode([]byte("invalid"))
+
+	// then
+	require.Error(t, got)
+	assert.ErrorIs(t, got, ErrGobDecode)
+}
+
+func TestStructuredErrorGobEncodeWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	var (
+		data []byte
+		errE error
+	)
+
+	assert.NotPanics(t, func() { data, errE = err.GobEncode() })
+	require.NoError(t, errE)
+
+	var got StructuredError
+
+	errD := got.GobDecode(data)
+
+	// then
+	require.NoError(t, errD)
+	assert.Equal(t, []Attr{Any("count", "not an int"), Any("user", "john")}, got.Attrs)
+}
+
+func TestStructuredErrorGobEncodeWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var err *StructuredError
+
+	// when
+	data, errE := err.GobEncode()
+	require.NoError(t, errE)
+
+	var got StructuredError
+
+	errD := got.GobDecode(data)
+
+	// then
+	require.NoError(t, errD)
+	assert.Equal(t, "NULL", got.Message)
+}
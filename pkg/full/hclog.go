@@ -0,0 +1,173 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type (
+	// HclogFieldsMarshaler is the equivalent of zapcore.ObjectMarshaler or zerolog.LogObjectMarshaler.
+	//
+	// This is not hclog official interface, but it is here to help you somehow is you need to do type assertions.
+	HclogFieldsMarshaler interface {
+		MarshalHclogFields() []any
+	}
+)
+
+const (
+	hclogPrefix    = "err"
+	hclogSeparator = "."
+)
+
+// MarshalHclogFields marshals the StructuredError into a flat list of key/value pairs.
+// Every key is prefixed with "err.".
+//
+// If the receiver is nil, it has a single pair with the key "err.message" and the value nilValue.
+//
+// Otherwise, it will have the following keys:
+//   - err.message
+//   - err.code, if not empty
+//   - err.http_status, if not zero
+//   - err.tags
+//   - err.attrs.<key>, object attrs are flattened with dotted keys
+//   - err.errors.<index>.<key>, nested errors are flattened with indexed keys
+//   - err.stack.
+//
+// Usage must be like:
+//
+//	var _err *errors.StructuredError
+//
+//	if !errors.As(err, &_err) {
+//	  log.Fatal("what!?", err)
+//	}
+//
+//	logger := hclog.New(&hclog.LoggerOptions{JSONFormat: true})
+//	logger.Error("message", _err.MarshalHclogFields()...)
+func (receiver *StructuredError) MarshalHclogFields() []any {
+	return receiver.asHclog(nil, hclogPrefix+hclogSeparator)
+}
+
+// asHclog is the actual implementation for MarshalHclogFields.
+// It appends the key/value pairs of the receiver, prefixed with the given prefix, to the given fields.
+func (receiver *StructuredError) asHclog(fields []any, prefix string) []any {
+	if receiver == nil {
+		return append(fields, prefix+messageKey, nilValue)
+	}
+
+	fields = append(fields, prefix+messageKey, cmpOr(trimmedMessage(receiver.Message), nilValue))
+
+	if receiver.Code != emptyString {
+		fields = append(fields, prefix+codeKey, receiver.Code)
+	}
+
+	if receiver.HTTPStatus != zero {
+		fields = append(fields, prefix+httpStatusKey, receiver.HTTPStatus)
+	}
+
+	if keepField(len(receiver.Tags)) {
+		tags := make([]string, zero, len(receiver.Tags))
+		for _, tag := range receiver.Tags {
+			tags = append(tags, strings.TrimSpace(tag))
+		}
+
+		fields = append(fields, prefix+tagsKey, tags)
+	}
+
+	for _, attr := range withoutOmittedAttrs(receiver.Attrs) {
+		fields = attr.asHclog(fields, prefix+attrsKey+hclogSeparator)
+	}
+
+	if len(receiver.Errors) > zero {
+		normalized := receiver.normalizedErrors()
+
+		for index, err := range normalized {
+			fields = errorToHclog(fields, prefix+errorsKey+hclogSeparator+strconv.Itoa(index)+hclogSeparator, err)
+		}
+	}
+
+	if keepField(len(receiver.Stack)) {
+		fields = append(fields, prefix+stackKey, string(receiver.Stack))
+	}
+
+	return fields
+}
+
+// MarshalHclogFields marshals the Attr into a flat list of key/value pairs.
+// If the receiver is nil, it has a single pair with the key nilValue and the value nilValue.
+//
+// Otherwise, it will have a single pair with the key receiver.Key and the value receiver.Value,
+// or one pair per nested Attr with dotted keys if the receiver is an object.
+func (receiver *Attr) MarshalHclogFields() []any {
+	return receiver.asHclog(nil, emptyString)
+}
+
+// asHclog is the actual implementation for MarshalHclogFields.
+// It appends the key/value pairs of the receiver, prefixed with the given prefix, to the given fields.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) asHclog(fields []any, prefix string) []any {
+	if receiver == nil {
+		return append(fields, prefix+nilValue, nilValue)
+	}
+
+	receiver = receiver.redacted()
+
+	switch receiver.Type { //nolint:exhaustive // just objects, strings, IPs, URLs and raw JSON need specific assert
+	case ObjectType:
+		for _, attr := range receiver.Value.([]Attr) {
+			fields = attr.asHclog(fields, prefix+receiver.Key+hclogSeparator)
+		}
+
+		return fields
+	case StringsType:
+		values := receiver.Value.([]string)
+
+		result := make([]string, zero, len(values))
+		for _, value := range values {
+			result = append(result, strings.TrimSpace(value))
+		}
+
+		return append(fields, prefix+receiver.Key, result)
+	case IPType:
+		return append(fields, prefix+receiver.Key, ipToString(receiver.Value.(net.IP)))
+	case URLType:
+		return append(fields, prefix+receiver.Key, urlToString(receiver.Value.(*url.URL)))
+	case JSONRawType:
+		return append(fields, prefix+receiver.Key, jsonRawToString(receiver.Value.(json.RawMessage)))
+	case LogValuerType:
+		return append(fields, prefix+receiver.Key, stringerToString(receiver.Value.(fmt.Stringer)))
+	case RuneType:
+		return append(fields, prefix+receiver.Key, runeToString(receiver.Value.(rune)))
+	case Complex128Type:
+		return append(fields, prefix+receiver.Key, complexToString(receiver.Value.(complex128)))
+	default:
+		return append(fields, prefix+receiver.Key, receiver.Value)
+	}
+}
+
+// errorToHclog appends the key/value pairs of the given error, prefixed with the given prefix, to the given fields.
+//
+// If the error is nil, it appends a single pair with the key "message" and the value nilValue.
+//
+// If the error is a *StructuredError, it appends the pairs of the *StructuredError.
+//
+// If the error is not a *StructuredError, it appends a single pair with the key "message"
+// and the value of the error's Error() method, or nilValue if the error message is empty.
+func errorToHclog(fields []any, prefix string, err error) []any {
+	var value *StructuredError
+	switch {
+	case err == nil:
+		return append(fields, prefix+messageKey, nilValue)
+	case stderrors.As(err, &value):
+		return value.asHclog(fields, prefix)
+	default:
+		errStr := strings.TrimSpace(err.Error())
+
+		return append(fields, prefix+messageKey, cmpOr(errStr, nilValue))
+	}
+}
@@ -0,0 +1,214 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredErrorMarshalHclogFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		want []any
+	}{
+		{
+			name: "given_nil_error_when_marshal_hclog_fields_then_returns_nil_message",
+			err:  nil,
+			want: []any{"err.message", "!NILVALUE"},
+		},
+		{
+			name: "given_error_with_empty_message_when_marshal_hclog_fields_then_returns_nil_message",
+			err:  New(""),
+			want: []any{"err.message", "!NILVALUE"},
+		},
+		{
+			name: "given_error_with_code_when_marshal_hclog_fields_then_returns_code",
+			err:  New("test").WithCode("NOT_FOUND"),
+			want: []any{"err.message", "test", "err.code", "NOT_FOUND"},
+		},
+		{
+			name: "given_error_with_http_status_when_marshal_hclog_fields_then_returns_http_status",
+			err:  New("test").WithHTTPStatus(404),
+			want: []any{"err.message", "test", "err.http_status", 404},
+		},
+		{
+			name: "given_error_with_tags_when_marshal_hclog_fields_then_returns_trimmed_tags",
+			err:  New("test").WithTags("tag1", " tag2 "),
+			want: []any{"err.message", "test", "err.tags", []string{"tag1", "tag2"}},
+		},
+		{
+			name: "given_error_with_attrs_when_marshal_hclog_fields_then_returns_attrs_keys",
+			err:  New("test").WithAttrs(String("request_id", "123"), Int("code", 500)),
+			want: []any{"err.message", "test", "err.attrs.request_id", "123", "err.attrs.code", 500},
+		},
+		{
+			name: "given_error_with_object_attr_when_marshal_hclog_fields_then_flattens_with_dotted_keys",
+			err:  New("test").WithAttrs(Object("user", String("id", "7"), Strings("roles", " admin "))),
+			want: []any{"err.message", "test", "err.attrs.user.id", "7", "err.attrs.user.roles", []string{"admin"}},
+		},
+		{
+			name: "given_error_with_nested_errors_when_marshal_hclog_fields_then_flattens_with_indexed_keys",
+			err: New("parent").WithErrors(
+				stderrors.New(" child "),
+				New("structured").WithTags("inner").WithErrors(stderrors.New("leaf")),
+				nil,
+			),
+			want: []any{
+				"err.message", "parent",
+				"err.errors.0.message", "child",
+				"err.errors.1.message", "structured",
+				"err.errors.1.tags", []string{"inner"},
+				"err.errors.1.errors.0.message", "leaf",
+				"err.errors.2.message", "!NILVALUE",
+			},
+		},
+		{
+			name: "given_error_with_stack_when_marshal_hclog_fields_then_returns_stack",
+			err:  New("test").WithStack([]byte("stack")),
+			want: []any{"err.message", "test", "err.stack", "stack"},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.MarshalHclogFields()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestAttrMarshalHclogFields(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attr *Attr
+		name string
+		want []any
+	}{
+		{
+			name: "given_nil_attr_when_marshal_hclog_fields_then_returns_nil_value",
+			attr: nil,
+			want: []any{"!NILVALUE", "!NILVALUE"},
+		},
+		{
+			name: "given_bool_attr_when_marshal_hclog_fields_then_returns_key_value",
+			attr: &Attr{Type: BoolType, Key: "flag", Value: true},
+			want: []any{"flag", true},
+		},
+		{
+			name: "given_object_attr_when_marshal_hclog_fields_then_returns_dotted_keys",
+			attr: &Attr{Type: ObjectType, Key: "obj", Value: []Attr{Int("a", 1)}},
+			want: []any{"obj.a", 1},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.attr.MarshalHclogFields()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestHclogFieldsMarshalerInterface(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var marshaler any = New("test")
+
+	// when
+	_, ok := marshaler.(HclogFieldsMarshaler)
+
+	// then
+	assert.True(t, ok)
+}
+
+func TestStructuredErrorMarshalHclogFieldsIntegration(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var buffer bytes.Buffer
+
+	logger := hclog.New(&hclog.LoggerOptions{Output: &buffer, JSONFormat: true})
+	err := New("operation failed").
+		WithTags("database").
+		WithAttrs(Int("code", 500)).
+		WithErrors(stderrors.New("timeout"))
+
+	// when
+	logger.Error("error occurred", err.MarshalHclogFields()...)
+
+	// then
+	var got map[string]any
+
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &got))
+	assert.Equal(t, "operation failed", got["err.message"])
+	assert.Equal(t, []any{"database"}, got["err.tags"])
+	assert.InDelta(t, 500, got["err.attrs.code"], 0)
+	assert.Equal(t, "timeout", got["err.errors.0.message"])
+}
+
+func TestStructuredErrorMarshalHclogFieldsWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	var got []any
+
+	assert.NotPanics(t, func() { got = err.MarshalHclogFields() })
+
+	// then
+	assert.Contains(t, got, "not an int")
+	assert.Contains(t, got, "john")
+}
+
+func TestStructuredErrorMarshalHclogFieldsWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var err *StructuredError
+
+	// when
+	got := err.MarshalHclogFields()
+
+	// then
+	assert.Contains(t, got, "NULL")
+	assert.NotContains(t, got, "!NILVALUE")
+}
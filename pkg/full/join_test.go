@@ -345,3 +345,309 @@ func TestJoinWithStructuredErrors(t *testing.T) {
 		)
 	}
 }
+
+func TestStructuredErrorFlatten(t *testing.T) {
+	t.Parallel()
+
+	errA := stderrors.New("a")
+	errB := stderrors.New("b")
+	errC := stderrors.New("c")
+	errD := stderrors.New("d")
+	wrapped := New("wrapped").WithErrors(Join(errA, errB))
+	join := func(errs ...error) *StructuredError {
+		return Join(errs...).(*StructuredError) //nolint:forcetypeassert,errcheck // Join returns *StructuredError
+	}
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		want    []error
+		wantAll []error
+	}{
+		{
+			name:    "given_nil_error_when_flatten_then_returns_nil",
+			err:     nil,
+			want:    nil,
+			wantAll: nil,
+		},
+		{
+			name:    "given_nested_join_when_flatten_then_has_three_direct_children",
+			err:     join(Join(errA, errB), errC),
+			want:    []error{errA, errB, errC},
+			wantAll: []error{errA, errB, errC},
+		},
+		{
+			name:    "given_deeply_nested_join_when_flatten_then_flattens_one_level",
+			err:     join(Join(errA, Join(errB, errC)), errD),
+			want:    []error{errA, Join(errB, errC), errD},
+			wantAll: []error{errA, errB, errC, errD},
+		},
+		{
+			name:    "given_join_with_non_joined_child_when_flatten_then_keeps_child",
+			err:     join(wrapped, errC),
+			want:    []error{wrapped, errC},
+			wantAll: []error{wrapped, errC},
+		},
+		{
+			name:    "given_non_joined_error_when_flatten_then_keeps_errors",
+			err:     New("parent").WithErrors(Join(errA, errB), errC),
+			want:    []error{Join(errA, errB), errC},
+			wantAll: []error{Join(errA, errB), errC},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.Clone().Flatten()
+				gotAll := test.err.Clone().FlattenAll()
+
+				// then
+				if test.err == nil {
+					assert.Nil(t, got)
+					assert.Nil(t, gotAll)
+
+					return
+				}
+
+				assert.Equal(t, test.want, got.Errors)
+				assert.Equal(t, test.wantAll, gotAll.Errors)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorFlattenDoesNotMutateNestedErrors(t *testing.T) {
+	t.Parallel()
+
+	// given
+	nested := Join(stderrors.New("a"), Join(stderrors.New("b"), stderrors.New("c")))
+	err := Join(nested, stderrors.New("d")).(*StructuredError) //nolint:forcetypeassert,errcheck // always structured
+
+	// when
+	got := err.FlattenAll()
+
+	// then
+	assert.Same(t, err, got)
+	assert.Len(t, got.Errors, 4)
+	assert.Len(t, nested.(*StructuredError).Errors, 2) //nolint:forcetypeassert,errcheck // Join returns *StructuredError
+}
+
+func TestValidationBuilder(t *testing.T) {
+	t.Parallel()
+
+	// given
+	validation := NewValidation()
+
+	// when
+	validation.AddField("email", "must be a valid email")
+	validation.AddField("age", "must be positive").WithCode("invalid_age")
+	validation.AddField("name", "is required")
+
+	err := validation.Err()
+
+	// then
+	require.Error(t, err)
+
+	structured, ok := err.(*StructuredError) //nolint:errorlint // Err returns *StructuredError
+	require.True(t, ok)
+	assert.True(t, structured.joined)
+	require.Len(t, structured.Errors, 3)
+
+	for index, field := range []string{"email", "age", "name"} {
+		child, ok := structured.Errors[index].(*StructuredError) //nolint:errorlint // AddField appends *StructuredError
+		require.True(t, ok)
+
+		attr, found := child.GetAttr("field")
+		require.True(t, found)
+		assert.Equal(t, String("field", field), attr)
+		assert.Equal(t, []string{"validation"}, child.Tags)
+	}
+
+	assert.Equal(
+		t,
+		map[string]string{"email": "must be a valid email", "age": "must be positive", "name": "is required"},
+		validation.Fields(),
+	)
+	assert.Equal(t, "invalid_age", structured.Errors[1].(*StructuredError).Code) //nolint:forcetypeassert,errcheck,errorlint // checked above
+}
+
+func TestValidationBuilderEmpty(t *testing.T) {
+	t.Parallel()
+
+	// given
+	validation := NewValidation()
+
+	// when
+	err := validation.Err()
+
+	// then
+	assert.NoError(t, err)
+	assert.Empty(t, validation.Fields())
+}
+
+func TestValidationBuilderFieldsLastWins(t *testing.T) {
+	t.Parallel()
+
+	// given
+	validation := NewValidation()
+	validation.AddField("email", "is required")
+	validation.AddField("email", "must be a valid email")
+
+	// when
+	fields := validation.Fields()
+
+	// then
+	assert.Equal(t, map[string]string{"email": "must be a valid email"}, fields)
+
+	structured, ok := validation.Err().(*StructuredError) //nolint:errorlint // Err returns *StructuredError
+	require.True(t, ok)
+	assert.Len(t, structured.Errors, 2)
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	child := stderrors.New("child")
+
+	tests := []struct {
+		name string
+		// given
+		a *StructuredError
+		b *StructuredError
+		// then
+		want *StructuredError
+	}{
+		{
+			name: "given_nil_errors_when_merge_then_returns_nil",
+			a:    nil,
+			b:    nil,
+			want: nil,
+		},
+		{
+			name: "given_nil_first_error_when_merge_then_returns_copy_of_second",
+			a:    nil,
+			b:    New("second").WithTags("tag"),
+			want: New("second").WithTags("tag"),
+		},
+		{
+			name: "given_nil_second_error_when_merge_then_returns_copy_of_first",
+			a:    New("first").WithAttrs(Int("code", 1)),
+			b:    nil,
+			want: New("first").WithAttrs(Int("code", 1)),
+		},
+		{
+			name: "given_empty_first_message_when_merge_then_uses_second_message",
+			a:    New("").WithTags("a"),
+			b:    New("second"),
+			want: New("second").WithTags("a"),
+		},
+		{
+			name: "given_errors_with_shared_tags_when_merge_then_dedupes_tags",
+			a:    New("first").WithTags("a", "b"),
+			b:    New("second").WithTags("b", "c", "a"),
+			want: New("first").WithTags("a", "b", "c"),
+		},
+		{
+			name: "given_errors_with_attrs_and_children_when_merge_then_appends_them",
+			a:    New("first").WithAttrs(String("k1", "v1")).WithErrors(child),
+			b:    New("second").WithAttrs(String("k2", "v2")).WithErrors(child).WithStack([]byte("stack")),
+			want: New("first").
+				WithAttrs(String("k1", "v1"), String("k2", "v2")).
+				WithErrors(child, child).
+				WithStack([]byte("stack")),
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := Merge(test.a, test.b)
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestMergeAll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		errs []*StructuredError
+		// then
+		want *StructuredError
+	}{
+		{
+			name: "given_no_errors_when_merge_all_then_returns_nil",
+			errs: nil,
+			want: nil,
+		},
+		{
+			name: "given_only_nil_errors_when_merge_all_then_returns_nil",
+			errs: []*StructuredError{nil, nil},
+			want: nil,
+		},
+		{
+			name: "given_many_errors_when_merge_all_then_skips_nil_and_dedupes_tags",
+			errs: []*StructuredError{
+				nil,
+				New("").WithTags("validation"),
+				New("invalid name").WithTags("validation", "name").WithAttrs(String("field", "name")),
+				nil,
+				New("invalid age").WithTags("age", "validation").WithAttrs(String("field", "age")),
+			},
+			want: New("invalid name").
+				WithTags("validation", "name", "age").
+				WithAttrs(String("field", "name"), String("field", "age")),
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := MergeAll(test.errs...)
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestMergeDoesNotMutateArguments(t *testing.T) {
+	t.Parallel()
+
+	// given
+	a := New("first").WithTags("a").WithAttrs(Ints("ints", 1))
+	b := New("second").WithTags("b")
+
+	// when
+	merged := Merge(a, b)
+	require.NotNil(t, merged)
+
+	merged.Tags[0] = "changed"
+	merged.Attrs[0].Value.([]int)[0] = 100 //nolint:forcetypeassert,errcheck // test
+
+	// then
+	assert.Equal(t, []string{"a"}, a.Tags)
+	assert.Equal(t, []string{"b"}, b.Tags)
+	assert.Equal(t, []Attr{Ints("ints", 1)}, a.Attrs)
+}
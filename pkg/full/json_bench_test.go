@@ -0,0 +1,52 @@
+package errors
+
+import (
+	stderrors "errors"
+	"testing"
+)
+
+func BenchmarkStructuredErrorMarshalJSON(b *testing.B) {
+	err := New("test").
+		WithAttrs(String("key", "value"), Int("count", 42)).
+		WithTags("tag").
+		WithErrors(stderrors.New("child"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = err.MarshalJSON()
+	}
+}
+
+func BenchmarkStructuredErrorMarshalJSONNested(b *testing.B) {
+	err := New("parent").
+		WithAttrs(Object("request", String("id", "123"), Int("retries", 3))).
+		WithErrors(
+			New("child").WithAttrs(Bool("temporary", true)).WithErrors(stderrors.New("leaf")),
+			Join(stderrors.New("first"), stderrors.New("second")),
+		)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = err.MarshalJSON()
+	}
+}
+
+func BenchmarkStructuredErrorUnmarshalJSON(b *testing.B) {
+	data := []byte(
+		`{"message":"parent","tags":["tag"],"attrs":[{"value":"value","key":"key","type":16}],` +
+			`"errors":[{"message":"child"}]}`,
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var err StructuredError
+
+		_ = err.UnmarshalJSON(data)
+	}
+}
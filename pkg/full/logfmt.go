@@ -0,0 +1,279 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MarshalLogfmt marshals the StructuredError into a logfmt line.
+//
+// If the receiver is nil, the line has a single pair with the key "message" and the value nilValue.
+//
+// Otherwise, it will have the following keys:
+//   - message
+//   - code, if not empty
+//   - http_status, if not zero
+//   - tags.<index>
+//   - attrs.<key>, slices use indexed keys and objects use dotted keys
+//   - errors.<index>.<key>, nested errors use indexed prefixes
+//   - stack.
+//
+// Values that are empty or contain spaces, quotes, equal signs or control characters
+// are quoted, escaping any embedded quote.
+func (receiver *StructuredError) MarshalLogfmt() string {
+	var stringsBuilder strings.Builder
+
+	receiver.asLogfmt(&stringsBuilder, emptyString)
+
+	return stringsBuilder.String()
+}
+
+// asLogfmt is the actual implementation for MarshalLogfmt.
+// It writes the pairs of the receiver, with keys prefixed with the given prefix, to the provided strings.Builder.
+func (receiver *StructuredError) asLogfmt(stringsBuilder *strings.Builder, prefix string) {
+	if receiver == nil {
+		pairToLogfmt(stringsBuilder, prefix+messageKey, nilValue)
+
+		return
+	}
+
+	pairToLogfmt(stringsBuilder, prefix+messageKey, cmpOr(trimmedMessage(receiver.Message), nilValue))
+
+	if receiver.Code != emptyString {
+		pairToLogfmt(stringsBuilder, prefix+codeKey, receiver.Code)
+	}
+
+	if receiver.HTTPStatus != zero {
+		pairToLogfmt(stringsBuilder, prefix+httpStatusKey, strconv.Itoa(receiver.HTTPStatus))
+	}
+
+	if len(receiver.Tags) > zero {
+		sliceToLogfmt(stringsBuilder, prefix+tagsKey, receiver.Tags)
+	}
+
+	for _, attr := range withoutOmittedAttrs(receiver.Attrs) {
+		attr.asLogfmt(stringsBuilder, prefix+attrsKey+dot)
+	}
+
+	if len(receiver.Errors) > zero {
+		normalized := receiver.normalizedErrors()
+
+		sliceToLogfmt(stringsBuilder, prefix+errorsKey, normalized)
+	}
+
+	if keepField(len(receiver.Stack)) {
+		pairToLogfmt(stringsBuilder, prefix+stackKey, string(receiver.Stack))
+	}
+}
+
+// MarshalLogfmt marshals the Attr into a logfmt line.
+//
+// If the receiver is nil, the line has a single pair with the key nilValue and the value nilValue.
+//
+// Otherwise, it will have a single pair with the key receiver.Key and the value receiver.Value,
+// or one pair per item with indexed keys for slices and dotted keys for objects.
+func (receiver *Attr) MarshalLogfmt() string {
+	var stringsBuilder strings.Builder
+
+	receiver.asLogfmt(&stringsBuilder, emptyString)
+
+	return stringsBuilder.String()
+}
+
+// asLogfmt is the actual implementation for MarshalLogfmt.
+// It writes the pairs of the receiver, with keys prefixed with the given prefix, to the provided strings.Builder.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) asLogfmt(stringsBuilder *strings.Builder, prefix string) {
+	if receiver == nil {
+		pairToLogfmt(stringsBuilder, prefix+nilValue, nilValue)
+
+		return
+	}
+
+	receiver = receiver.redacted()
+
+	key := prefix + receiver.Key
+
+	switch receiver.Type {
+	case AnyType:
+		pairToLogfmt(stringsBuilder, key, fmt.Sprintf(verboseFormat, receiver.Value))
+	case ObjectType:
+		for _, attr := range receiver.Value.([]Attr) {
+			attr.asLogfmt(stringsBuilder, key+dot)
+		}
+	case BoolType:
+		pairToLogfmt(stringsBuilder, key, strconv.FormatBool(receiver.Value.(bool)))
+	case BoolsType:
+		sliceToLogfmt(stringsBuilder, key, receiver.Value.([]bool))
+	case TimeType:
+		pairToLogfmt(stringsBuilder, key, receiver.Value.(time.Time).Format(time.RFC3339Nano))
+	case TimesType:
+		sliceToLogfmt(stringsBuilder, key, receiver.Value.([]time.Time))
+	case DurationType:
+		pairToLogfmt(stringsBuilder, key, receiver.Value.(time.Duration).String())
+	case DurationsType:
+		sliceToLogfmt(stringsBuilder, key, receiver.Value.([]time.Duration))
+	case IntType:
+		pairToLogfmt(stringsBuilder, key, strconv.Itoa(receiver.Value.(int)))
+	case IntsType:
+		sliceToLogfmt(stringsBuilder, key, receiver.Value.([]int))
+	case Int64Type:
+		pairToLogfmt(stringsBuilder, key, strconv.FormatInt(receiver.Value.(int64), ten))
+	case Int64sType:
+		sliceToLogfmt(stringsBuilder, key, receiver.Value.([]int64))
+	case Uint64Type:
+		pairToLogfmt(stringsBuilder, key, strconv.FormatUint(receiver.Value.(uint64), ten))
+	case Uint64sType:
+		sliceToLogfmt(stringsBuilder, key, receiver.Value.([]uint64))
+	case Float64Type:
+		pairToLogfmt(stringsBuilder, key, strconv.FormatFloat(receiver.Value.(float64), 'f', -1, sixtyFour))
+	case Float64sType:
+		sliceToLogfmt(stringsBuilder, key, receiver.Value.([]float64))
+	case StringType:
+		pairToLogfmt(stringsBuilder, key, receiver.Value.(string))
+	case StringsType:
+		sliceToLogfmt(stringsBuilder, key, receiver.Value.([]string))
+	case IPType:
+		pairToLogfmt(stringsBuilder, key, ipToString(receiver.Value.(net.IP)))
+	case URLType:
+		pairToLogfmt(stringsBuilder, key, urlToString(receiver.Value.(*url.URL)))
+	case JSONRawType:
+		pairToLogfmt(stringsBuilder, key, jsonRawToString(receiver.Value.(json.RawMessage)))
+	case LogValuerType:
+		pairToLogfmt(stringsBuilder, key, stringerToString(receiver.Value.(fmt.Stringer)))
+	case RuneType:
+		pairToLogfmt(stringsBuilder, key, runeToString(receiver.Value.(rune)))
+	case Complex128Type:
+		pairToLogfmt(stringsBuilder, key, complexToString(receiver.Value.(complex128)))
+	default:
+		pairToLogfmt(stringsBuilder, key, fmt.Sprintf(verboseFormat, receiver.Value))
+	}
+}
+
+// pairToLogfmt writes a key=value pair to the provided strings.Builder,
+// separated by a space from any previous pair.
+//
+// Parameters:
+//
+//	stringsBuilder - the strings.Builder to write to
+//	key - the key of the pair, any space, quote or equal sign is replaced by an underscore
+//	value - the value of the pair, quoted if needed
+func pairToLogfmt(stringsBuilder *strings.Builder, key, value string) {
+	if stringsBuilder.Len() > zero {
+		stringsBuilder.WriteString(space)
+	}
+
+	stringsBuilder.WriteString(strings.Map(keyRuneToLogfmt, key))
+	stringsBuilder.WriteString(equals)
+
+	if needsLogfmtQuote(value) {
+		stringsBuilder.WriteString(strconv.Quote(value))
+
+		return
+	}
+
+	stringsBuilder.WriteString(value)
+}
+
+// keyRuneToLogfmt replaces the runes that are not allowed in a logfmt key by an underscore.
+func keyRuneToLogfmt(r rune) rune {
+	if r <= ' ' || r == '=' || r == '"' {
+		return '_'
+	}
+
+	return r
+}
+
+// needsLogfmtQuote reports whether the given value must be quoted to be a valid logfmt value.
+func needsLogfmtQuote(value string) bool {
+	if value == emptyString {
+		return true
+	}
+
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// errorToLogfmt writes the pairs of the given error, with keys prefixed with the given prefix,
+// to the provided strings.Builder.
+//
+// If the error is nil, it writes a single pair with the key "message" and the value nilValue.
+// If the error is a StructuredError, it writes the same pairs as the StructuredError.
+// If the error is not a StructuredError, it writes a single pair with the key "message"
+// and the value of the error's Error() method.
+func errorToLogfmt(stringsBuilder *strings.Builder, prefix string, err error) {
+	var value *StructuredError
+	switch {
+	case err == nil:
+		pairToLogfmt(stringsBuilder, prefix+messageKey, nilValue)
+	case stderrors.As(err, &value):
+		value.asLogfmt(stringsBuilder, prefix)
+	default:
+		errStr := strings.TrimSpace(err.Error())
+		pairToLogfmt(stringsBuilder, prefix+messageKey, cmpOr(errStr, nilValue))
+	}
+}
+
+// sliceToLogfmt writes one pair per item of the given slice to the provided strings.Builder.
+// The key of each pair is the given key followed by the item index.
+//
+// Errors are written with errorToLogfmt, so their pairs are prefixed with the indexed key.
+func sliceToLogfmt[T any](stringsBuilder *strings.Builder, key string, slice []T) {
+	key += dot
+
+	switch values := any(slice).(type) {
+	case []error:
+		for index, value := range values {
+			errorToLogfmt(stringsBuilder, key+strconv.Itoa(index)+dot, value)
+		}
+	case []bool:
+		for index, value := range values {
+			pairToLogfmt(stringsBuilder, key+strconv.Itoa(index), strconv.FormatBool(value))
+		}
+	case []time.Time:
+		for index, value := range values {
+			pairToLogfmt(stringsBuilder, key+strconv.Itoa(index), value.Format(time.RFC3339Nano))
+		}
+	case []time.Duration:
+		for index, value := range values {
+			pairToLogfmt(stringsBuilder, key+strconv.Itoa(index), value.String())
+		}
+	case []int:
+		for index, value := range values {
+			pairToLogfmt(stringsBuilder, key+strconv.Itoa(index), strconv.Itoa(value))
+		}
+	case []int64:
+		for index, value := range values {
+			pairToLogfmt(stringsBuilder, key+strconv.Itoa(index), strconv.FormatInt(value, ten))
+		}
+	case []uint64:
+		for index, value := range values {
+			pairToLogfmt(stringsBuilder, key+strconv.Itoa(index), strconv.FormatUint(value, ten))
+		}
+	case []float64:
+		for index, value := range values {
+			pairToLogfmt(stringsBuilder, key+strconv.Itoa(index), strconv.FormatFloat(value, 'f', -1, sixtyFour))
+		}
+	case []string:
+		for index, value := range values {
+			pairToLogfmt(stringsBuilder, key+strconv.Itoa(index), strings.TrimSpace(value))
+		}
+	default:
+		for index, value := range slice {
+			pairToLogfmt(stringsBuilder, key+strconv.Itoa(index), fmt.Sprintf(verboseFormat, value))
+		}
+	}
+}
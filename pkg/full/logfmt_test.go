@@ -0,0 +1,246 @@
+package errors
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructuredErrorMarshalLogfmt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		want string
+	}{
+		{
+			name: "given_nil_error_when_marshal_logfmt_then_returns_nil_message",
+			err:  nil,
+			want: `message=!NILVALUE`,
+		},
+		{
+			name: "given_error_with_single_word_message_when_marshal_logfmt_then_returns_unquoted_message",
+			err:  New("failed"),
+			want: `message=failed`,
+		},
+		{
+			name: "given_error_with_spaces_in_message_when_marshal_logfmt_then_returns_quoted_message",
+			err:  New("something went wrong"),
+			want: `message="something went wrong"`,
+		},
+		{
+			name: "given_error_with_code_when_marshal_logfmt_then_returns_code",
+			err:  New("failed").WithCode("NOT_FOUND"),
+			want: `message=failed code=NOT_FOUND`,
+		},
+		{
+			name: "given_error_with_http_status_when_marshal_logfmt_then_returns_http_status",
+			err:  New("failed").WithCode("NOT_FOUND").WithHTTPStatus(404),
+			want: `message=failed code=NOT_FOUND http_status=404`,
+		},
+		{
+			name: "given_error_with_quotes_and_equals_when_marshal_logfmt_then_escapes_them",
+			err:  New(`bad "value" a=b`),
+			want: `message="bad \"value\" a=b"`,
+		},
+		{
+			name: "given_error_with_equals_only_when_marshal_logfmt_then_quotes_value",
+			err:  New("a=b"),
+			want: `message="a=b"`,
+		},
+		{
+			name: "given_error_with_backslash_when_marshal_logfmt_then_escapes_it",
+			err:  New(`C:\tmp`),
+			want: `message="C:\\tmp"`,
+		},
+		{
+			name: "given_error_with_tags_when_marshal_logfmt_then_returns_indexed_tags",
+			err:  New("test").WithTags("a", " b "),
+			want: `message=test tags.0=a tags.1=b`,
+		},
+		{
+			name: "given_error_with_attrs_when_marshal_logfmt_then_returns_prefixed_attrs",
+			err: New("test").WithAttrs(
+				String("request_id", "123"),
+				Int("code", 500),
+				String("path", "/a b"),
+				String("empty", ""),
+			),
+			want: `message=test attrs.request_id=123 attrs.code=500 attrs.path="/a b" attrs.empty=""`,
+		},
+		{
+			name: "given_error_with_nested_errors_when_marshal_logfmt_then_returns_indexed_prefixes",
+			err: New("parent").WithErrors(
+				stderrors.New("child error"),
+				New("structured").WithTags("inner").WithErrors(stderrors.New("leaf")),
+				nil,
+			),
+			want: `message=parent errors.0.message="child error" errors.1.message=structured ` +
+				`errors.1.tags.0=inner errors.1.errors.0.message=leaf errors.2.message=!NILVALUE`,
+		},
+		{
+			name: "given_error_with_stack_when_marshal_logfmt_then_returns_escaped_stack",
+			err:  New("test").WithStack([]byte("line1\nline2")),
+			want: `message=test stack="line1\nline2"`,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.MarshalLogfmt()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestAttrMarshalLogfmt(t *testing.T) {
+	t.Parallel()
+
+	testTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		attr *Attr
+		name string
+		want string
+	}{
+		{
+			name: "given_nil_attr_when_marshal_logfmt_then_returns_nil_value",
+			attr: nil,
+			want: `!NILVALUE=!NILVALUE`,
+		},
+		{
+			name: "given_key_with_spaces_when_marshal_logfmt_then_replaces_them",
+			attr: &Attr{Type: StringType, Key: "my key=x", Value: "v"},
+			want: `my_key_x=v`,
+		},
+		{
+			name: "given_bool_attr_when_marshal_logfmt_then_returns_value",
+			attr: &Attr{Type: BoolType, Key: "flag", Value: true},
+			want: `flag=true`,
+		},
+		{
+			name: "given_time_attr_when_marshal_logfmt_then_returns_rfc3339_value",
+			attr: &Attr{Type: TimeType, Key: "at", Value: testTime},
+			want: `at=2024-01-02T03:04:05Z`,
+		},
+		{
+			name: "given_float64s_attr_when_marshal_logfmt_then_returns_indexed_values",
+			attr: &Attr{Type: Float64sType, Key: "ratios", Value: []float64{0.5, 1}},
+			want: `ratios.0=0.5 ratios.1=1`,
+		},
+		{
+			name: "given_strings_attr_when_marshal_logfmt_then_returns_quoted_values",
+			attr: &Attr{Type: StringsType, Key: "names", Value: []string{"a b", "c"}},
+			want: `names.0="a b" names.1=c`,
+		},
+		{
+			name: "given_object_attr_when_marshal_logfmt_then_returns_dotted_keys",
+			attr: &Attr{Type: ObjectType, Key: "user", Value: []Attr{Int("id", 7), Durations("waits", time.Second)}},
+			want: `user.id=7 user.waits.0=1s`,
+		},
+		{
+			name: "given_any_attr_when_marshal_logfmt_then_returns_verbose_value",
+			attr: &Attr{Type: AnyType, Key: "any", Value: struct{ A int }{A: 1}},
+			want: `any={A:1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.attr.MarshalLogfmt()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestNeedsLogfmtQuote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{name: "given_plain_value_when_needs_logfmt_quote_then_returns_false", value: "plain", want: false},
+		{name: "given_empty_value_when_needs_logfmt_quote_then_returns_true", value: "", want: true},
+		{name: "given_space_when_needs_logfmt_quote_then_returns_true", value: "a b", want: true},
+		{name: "given_quote_when_needs_logfmt_quote_then_returns_true", value: `a"b`, want: true},
+		{name: "given_equals_when_needs_logfmt_quote_then_returns_true", value: "a=b", want: true},
+		{name: "given_tab_when_needs_logfmt_quote_then_returns_true", value: "a\tb", want: true},
+		{name: "given_unicode_value_when_needs_logfmt_quote_then_returns_false", value: "ñandú", want: false},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := needsLogfmtQuote(test.value)
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorMarshalLogfmtWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	var got string
+
+	assert.NotPanics(t, func() { got = err.MarshalLogfmt() })
+
+	// then
+	assert.Contains(t, got, `count="not an int"`)
+	assert.Contains(t, got, "user=john")
+}
+
+func TestStructuredErrorMarshalLogfmtWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var err *StructuredError
+
+	// when
+	got := err.MarshalLogfmt()
+
+	// then
+	assert.Equal(t, "message=NULL", got)
+}
@@ -32,6 +32,16 @@ func TestStructuredErrorMarshalLogrusFields(t *testing.T) {
 			err:      New(""),
 			wantKeys: []string{"message"},
 		},
+		{
+			name:     "given_error_with_code_when_marshal_logrus_fields_then_returns_fields_with_code",
+			err:      New("test").WithCode("NOT_FOUND"),
+			wantKeys: []string{"message", "code"},
+		},
+		{
+			name:     "given_error_with_http_status_when_marshal_logrus_fields_then_returns_fields_with_http_status",
+			err:      New("test").WithHTTPStatus(404),
+			wantKeys: []string{"message", "http_status"},
+		},
 		{
 			name:     "given_error_with_tags_when_marshal_logrus_fields_then_returns_fields_with_tags",
 			err:      New("test").WithTags("tag1", "tag2"),
@@ -326,3 +336,40 @@ func TestStructuredErrorMarshalLogrusFieldsWithComplexData(t *testing.T) {
 		)
 	}
 }
+
+func TestStructuredErrorMarshalLogrusFieldsWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	var got logrus.Fields
+
+	assert.NotPanics(t, func() { got = err.MarshalLogrusFields() })
+
+	// then
+	assert.Equal(t, map[string]any{"count": "not an int", "user": "john"}, got["attrs"])
+}
+
+func TestStructuredErrorMarshalLogrusFieldsWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var err *StructuredError
+
+	// when
+	got := err.MarshalLogrusFields()
+
+	// then
+	assert.Equal(t, logrus.Fields{"message": "NULL"}, got)
+}
@@ -34,6 +34,16 @@ func TestStructuredErrorAsMap(t *testing.T) {
 			err:      New(""),
 			wantKeys: []string{"message"},
 		},
+		{
+			name:     "given_error_with_code_when_as_map_then_returns_map_with_code",
+			err:      New("test").WithCode("NOT_FOUND"),
+			wantKeys: []string{"message", "code"},
+		},
+		{
+			name:     "given_error_with_http_status_when_as_map_then_returns_map_with_http_status",
+			err:      New("test").WithHTTPStatus(404),
+			wantKeys: []string{"message", "http_status"},
+		},
 		{
 			name:     "given_error_with_attrs_when_as_map_then_returns_map_with_attrs",
 			err:      New("test").WithAttrs(String("key", "value")),
@@ -745,3 +755,103 @@ func TestAsMapIntegration(t *testing.T) {
 		)
 	}
 }
+
+func TestStructuredErrorToMap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		want map[string]any
+	}{
+		{
+			name: "given_nil_error_when_to_map_then_returns_nil_message",
+			err:  nil,
+			want: map[string]any{"message": "!NILVALUE"},
+		},
+		{
+			name: "given_complex_error_when_to_map_then_returns_nested_maps",
+			err: New("parent error").
+				WithAttrs(String("request_id", "123"), Int("status", 500)).
+				WithTags("api", "error").
+				WithErrors(
+					stderrors.New("database connection failed"),
+					New("timeout").
+						WithAttrs(Int("timeout_ms", 5000)).
+						WithErrors(New("dial failed").WithTags("network")),
+				).
+				WithStack([]byte("main.go:10\nhandler.go:25")),
+			want: map[string]any{
+				"message": "parent error",
+				"tags":    []string{"api", "error"},
+				"attrs":   map[string]any{"request_id": "123", "status": 500},
+				"errors": []map[string]any{
+					{"message": "database connection failed"},
+					{
+						"message": "timeout",
+						"attrs":   map[string]any{"timeout_ms": 5000},
+						"errors": []map[string]any{
+							{"message": "dial failed", "tags": []string{"network"}},
+						},
+					},
+				},
+				"stack": []string{"main.go:10", "handler.go:25"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.ToMap()
+
+				// then
+				assert.Equal(t, test.want, got)
+				assert.Equal(t, test.err.AsMap(), got)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorAsMapWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	var got map[string]any
+
+	assert.NotPanics(t, func() { got = err.AsMap() })
+
+	// then
+	assert.Equal(t, map[string]any{"count": "not an int", "user": "john"}, got["attrs"])
+}
+
+func TestStructuredErrorAsMapWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var err *StructuredError
+
+	// when
+	got := err.AsMap()
+
+	// then
+	assert.Equal(t, map[string]any{"message": "NULL"}, got)
+}
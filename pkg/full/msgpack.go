@@ -0,0 +1,343 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type (
+	// msgpackError is the stable MessagePack representation of a StructuredError.
+	msgpackError struct {
+		Message    string          `msgpack:"message"`
+		Code       string          `msgpack:"code,omitempty"`
+		HTTPStatus int             `msgpack:"http_status,omitempty"`
+		Tags       []string        `msgpack:"tags,omitempty"`
+		Attrs      []msgpackAttr   `msgpack:"attrs,omitempty"`
+		Errors     []*msgpackError `msgpack:"errors,omitempty"`
+		Stack      []byte          `msgpack:"stack,omitempty"`
+	}
+
+	// msgpackAttr is the MessagePack representation of an Attr.
+	// Value keeps the encoded value, so it can be decoded into the concrete type given by Type.
+	msgpackAttr struct {
+		Value msgpack.RawMessage `msgpack:"value"`
+		Key   string             `msgpack:"key"`
+		Type  Type               `msgpack:"type"`
+	}
+)
+
+var (
+	// ErrMarshalMsgpack is returned when marshaling to MessagePack fails.
+	ErrMarshalMsgpack = New("failed to marshal MessagePack")
+
+	// ErrUnmarshalMsgpack is returned when unmarshaling from MessagePack fails.
+	ErrUnmarshalMsgpack = New("failed to unmarshal MessagePack")
+)
+
+//nolint:errcheck // this is for interface assertion
+var (
+	_ msgpack.Marshaler   = (*StructuredError)(nil)
+	_ msgpack.Unmarshaler = (*StructuredError)(nil)
+)
+
+// MarshalMsgpack implements msgpack.Marshaler.
+//
+// It marshals the StructuredError into a MessagePack map with the following keys:
+//   - message
+//   - code
+//   - tags
+//   - attrs
+//   - errors
+//   - stack.
+//
+// Attrs are encoded as {"value","key","type"} maps, with the value encoded with its concrete type,
+// so UnmarshalMsgpack restores an Int64Type Attr as an int64 and not as a float64.
+// Errors that are not a *StructuredError are encoded with their Error() message only.
+//
+// If the receiver is nil, it will have a single "message" key with the value nilValue.
+func (receiver *StructuredError) MarshalMsgpack() ([]byte, error) {
+	structured, err := receiver.asMsgpack()
+	if err != nil {
+		return nil, JoinIf(err, ErrMarshalMsgpack)
+	}
+
+	data, err := msgpack.Marshal(structured)
+	if err != nil {
+		return nil, JoinIf(err, ErrMarshalMsgpack)
+	}
+
+	return data, nil
+}
+
+// UnmarshalMsgpack implements msgpack.Unmarshaler.
+//
+// It takes a byte slice produced by MarshalMsgpack and unmarshals it into the StructuredError.
+// Attr values are decoded into the concrete type given by their Type, AnyType values are
+// decoded as the msgpack package does for an any value.
+func (receiver *StructuredError) UnmarshalMsgpack(data []byte) error {
+	var structured msgpackError
+
+	err := msgpack.Unmarshal(data, &structured)
+	if err != nil {
+		return JoinIf(err, ErrUnmarshalMsgpack)
+	}
+
+	err = structured.fillStructuredError(receiver)
+	if err != nil {
+		return JoinIf(err, ErrUnmarshalMsgpack)
+	}
+
+	return nil
+}
+
+// asMsgpack is the actual implementation for MarshalMsgpack.
+func (receiver *StructuredError) asMsgpack() (*msgpackError, error) {
+	if receiver == nil {
+		return &msgpackError{Message: nilValue}, nil
+	}
+
+	structured := &msgpackError{
+		Message:    trimmedMessage(receiver.Message),
+		Code:       receiver.Code,
+		HTTPStatus: receiver.HTTPStatus,
+		Tags:       receiver.Tags,
+		Stack:      receiver.Stack,
+	}
+
+	if len(receiver.Attrs) > zero {
+		attrs, err := attrsToMsgpack(withoutOmittedAttrs(receiver.Attrs))
+		if err != nil {
+			return nil, err
+		}
+
+		structured.Attrs = attrs
+	}
+
+	if len(receiver.Errors) > zero {
+		normalized := receiver.normalizedErrors()
+
+		structured.Errors = make([]*msgpackError, zero, len(normalized))
+
+		for _, err := range normalized {
+			_structured, errM := errorToMsgpack(err)
+			if errM != nil {
+				return nil, errM
+			}
+
+			structured.Errors = append(structured.Errors, _structured)
+		}
+	}
+
+	return structured, nil
+}
+
+// asMsgpack converts the Attr into a msgpackAttr, encoding its value with its concrete type.
+// If the Attr is sensitive, the value is "[REDACTED]".
+// LogValuers are encoded as the string of their resolved slog.Value, and complex numbers as their string form.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) asMsgpack() (msgpackAttr, error) {
+	receiver = receiver.redacted().resolved()
+
+	attr := msgpackAttr{Key: receiver.Key, Type: receiver.Type}
+
+	value := receiver.Value
+
+	if receiver.Type == ObjectType {
+		attrs, err := attrsToMsgpack(receiver.Value.([]Attr))
+		if err != nil {
+			return attr, err
+		}
+
+		value = attrs
+	}
+
+	if receiver.Type == Complex128Type {
+		value = complexToString(receiver.Value.(complex128))
+	}
+
+	data, err := msgpack.Marshal(value)
+	if err != nil {
+		return attr, err //nolint:wrapcheck // wrapped by MarshalMsgpack
+	}
+
+	attr.Value = data
+
+	return attr, nil
+}
+
+// attr converts the msgpackAttr back into an Attr, decoding its value into the concrete type given by Type.
+func (receiver *msgpackAttr) attr() (Attr, error) {
+	attr := Attr{Key: receiver.Key, Type: receiver.Type}
+
+	var err error
+
+	switch receiver.Type {
+	case ObjectType:
+		var attrs []msgpackAttr
+
+		attrs, err = msgpackToValue[[]msgpackAttr](receiver.Value)
+		if err == nil {
+			attr.Value, err = msgpackToAttrs(attrs)
+		}
+	case BoolType:
+		attr.Value, err = msgpackToValue[bool](receiver.Value)
+	case BoolsType:
+		attr.Value, err = msgpackToValue[[]bool](receiver.Value)
+	case TimeType:
+		attr.Value, err = msgpackToValue[time.Time](receiver.Value)
+	case TimesType:
+		attr.Value, err = msgpackToValue[[]time.Time](receiver.Value)
+	case DurationType:
+		attr.Value, err = msgpackToValue[time.Duration](receiver.Value)
+	case DurationsType:
+		attr.Value, err = msgpackToValue[[]time.Duration](receiver.Value)
+	case IntType:
+		attr.Value, err = msgpackToValue[int](receiver.Value)
+	case IntsType:
+		attr.Value, err = msgpackToValue[[]int](receiver.Value)
+	case Int64Type:
+		attr.Value, err = msgpackToValue[int64](receiver.Value)
+	case Int64sType:
+		attr.Value, err = msgpackToValue[[]int64](receiver.Value)
+	case Uint64Type:
+		attr.Value, err = msgpackToValue[uint64](receiver.Value)
+	case Uint64sType:
+		attr.Value, err = msgpackToValue[[]uint64](receiver.Value)
+	case Float64Type:
+		attr.Value, err = msgpackToValue[float64](receiver.Value)
+	case Float64sType:
+		attr.Value, err = msgpackToValue[[]float64](receiver.Value)
+	case StringType:
+		attr.Value, err = msgpackToValue[string](receiver.Value)
+	case StringsType:
+		attr.Value, err = msgpackToValue[[]string](receiver.Value)
+	case IPType:
+		attr.Value, err = msgpackToValue[net.IP](receiver.Value)
+	case URLType:
+		attr.Value, err = msgpackToValue[*url.URL](receiver.Value)
+	case JSONRawType:
+		attr.Value, err = msgpackToValue[json.RawMessage](receiver.Value)
+	case LogValuerType:
+		attr.Type = StringType
+		attr.Value, err = msgpackToValue[string](receiver.Value)
+	case RuneType:
+		attr.Value, err = msgpackToValue[rune](receiver.Value)
+	case Complex128Type:
+		var value string
+
+		value, err = msgpackToValue[string](receiver.Value)
+		if err == nil {
+			attr.Value, err = strconv.ParseComplex(value, complexBitSize)
+		}
+	default:
+		attr.Value, err = msgpackToValue[any](receiver.Value)
+	}
+
+	return attr, err
+}
+
+// fillStructuredError takes a msgpackError and fills a StructuredError with the unmarshalled data.
+func (receiver *msgpackError) fillStructuredError(structured *StructuredError) error {
+	structured.Message = receiver.Message
+	structured.Code = receiver.Code
+	structured.HTTPStatus = receiver.HTTPStatus
+	structured.Tags = receiver.Tags
+	structured.Stack = receiver.Stack
+
+	if len(receiver.Attrs) > zero {
+		attrs, err := msgpackToAttrs(receiver.Attrs)
+		if err != nil {
+			return err
+		}
+
+		structured.Attrs = attrs
+	}
+
+	if len(receiver.Errors) > zero {
+		structured.Errors = make([]error, zero, len(receiver.Errors))
+
+		for _, err := range receiver.Errors {
+			_structured := &StructuredError{}
+
+			errF := err.fillStructuredError(_structured)
+			if errF != nil {
+				return errF
+			}
+
+			structured.Errors = append(structured.Errors, _structured)
+		}
+	}
+
+	return nil
+}
+
+// errorToMsgpack converts an error into a msgpackError.
+//
+// If the error is nil, the msgpackError has the message nilValue.
+//
+// If the error is a *StructuredError, it converts the *StructuredError.
+//
+// If the error is not a *StructuredError, the msgpackError has the error's Error() message,
+// or nilValue if the message is empty.
+func errorToMsgpack(err error) (*msgpackError, error) {
+	var value *StructuredError
+	switch {
+	case err == nil:
+		return &msgpackError{Message: nilValue}, nil
+	case stderrors.As(err, &value):
+		return value.asMsgpack()
+	default:
+		errStr := strings.TrimSpace(err.Error())
+
+		return &msgpackError{Message: cmpOr(errStr, nilValue)}, nil
+	}
+}
+
+// attrsToMsgpack converts a slice of Attr into a slice of msgpackAttr.
+func attrsToMsgpack(attrs []Attr) ([]msgpackAttr, error) {
+	result := make([]msgpackAttr, zero, len(attrs))
+
+	for index := range attrs {
+		attr, err := attrs[index].asMsgpack()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, attr)
+	}
+
+	return result, nil
+}
+
+// msgpackToAttrs converts a slice of msgpackAttr back into a slice of Attr.
+func msgpackToAttrs(attrs []msgpackAttr) ([]Attr, error) {
+	result := make([]Attr, zero, len(attrs))
+
+	for index := range attrs {
+		attr, err := attrs[index].attr()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, attr)
+	}
+
+	return result, nil
+}
+
+// msgpackToValue decodes a MessagePack encoded value into a value of type T.
+func msgpackToValue[T any](data []byte) (T, error) {
+	var value T
+
+	err := msgpack.Unmarshal(data, &value)
+
+	return value, err //nolint:wrapcheck // wrapped by UnmarshalMsgpack
+}
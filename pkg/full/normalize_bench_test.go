@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	benchmarkChildren = 100
+	benchmarkDepth    = 5
+	benchmarkBranches = 3
+)
+
+// wideJoinedError returns a StructuredError wrapping a joined error with benchmarkChildren children,
+// half of them being joined errors themselves.
+func wideJoinedError() *StructuredError {
+	errs := make([]error, zero, benchmarkChildren)
+
+	for index := zero; index < benchmarkChildren; index++ {
+		if index%2 == zero {
+			errs = append(errs, New("child "+strconv.Itoa(index)))
+
+			continue
+		}
+
+		errs = append(errs, Join(New("left "+strconv.Itoa(index)), New("right "+strconv.Itoa(index))))
+	}
+
+	return New("parent").WithErrors(Join(errs...))
+}
+
+// nestedError returns a StructuredError tree benchmarkDepth levels deep,
+// where every error but the leaves wraps benchmarkBranches children.
+func nestedError(depth int) *StructuredError {
+	err := New("level " + strconv.Itoa(depth))
+	if depth == zero {
+		return err
+	}
+
+	errs := make([]error, zero, benchmarkBranches)
+	for index := zero; index < benchmarkBranches; index++ {
+		errs = append(errs, nestedError(depth-one))
+	}
+
+	return err.WithErrors(errs...)
+}
+
+func BenchmarkNormalizeErrors(b *testing.B) {
+	err := wideJoinedError()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		target := normalizerTarget{
+			errs: make([]error, zero, len(err.Errors)),
+		}
+		normalizeErrors(zero, &target, err.Errors...)
+	}
+}
+
+func BenchmarkNormalizeErrorsMarshalJSON(b *testing.B) {
+	err := wideJoinedError()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = err.MarshalJSON()
+	}
+}
+
+func BenchmarkNormalizeErrorsNested(b *testing.B) {
+	err := nestedError(benchmarkDepth)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = err.normalizedErrors()
+	}
+}
+
+func TestNormalizeErrorsWideJoin(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := wideJoinedError()
+	target := normalizerTarget{
+		errs: make([]error, zero, len(err.Errors)),
+	}
+
+	// when
+	normalizeErrors(zero, &target, err.Errors...)
+
+	// then
+	assert.Len(t, target.errs, benchmarkChildren+benchmarkChildren/2)
+	assert.Equal(t, New("child 0"), target.errs[0])
+	assert.Equal(t, New("left 1"), target.errs[1])
+	assert.Equal(t, New("right 1"), target.errs[2])
+}
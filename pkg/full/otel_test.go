@@ -0,0 +1,279 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRecordSpanError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		name string
+		// then
+		wantRecorded    bool
+		wantDescription string
+		wantAttrs       []attribute.KeyValue
+	}{
+		{
+			name:         "given_nil_error_when_record_span_error_then_records_nothing",
+			err:          nil,
+			wantRecorded: false,
+		},
+		{
+			name:            "given_standard_error_when_record_span_error_then_records_status_and_exception",
+			err:             stderrors.New("  standard error  "),
+			wantRecorded:    true,
+			wantDescription: "standard error",
+			wantAttrs:       nil,
+		},
+		{
+			name:            "given_structured_error_with_empty_message_when_record_span_error_then_uses_nil_value",
+			err:             New(""),
+			wantRecorded:    true,
+			wantDescription: "!NILVALUE",
+			wantAttrs:       nil,
+		},
+		{
+			name: "given_structured_error_when_record_span_error_then_records_tags_and_attrs",
+			err: New("structured").
+				WithTags("database", " critical ").
+				WithAttrs(String("host", "localhost"), Int("port", 5432)),
+			wantRecorded:    true,
+			wantDescription: "structured",
+			wantAttrs: []attribute.KeyValue{
+				attribute.StringSlice("tags", []string{"database", "critical"}),
+				attribute.String("host", "localhost"),
+				attribute.Int("port", 5432),
+			},
+		},
+		{
+			name: "given_wrapped_structured_error_when_record_span_error_then_records_inner_attrs",
+			err: fmt.Errorf(
+				"wrapped: %w",
+				New("inner").WithAttrs(Bool("retry", true)),
+			),
+			wantRecorded:    true,
+			wantDescription: "inner",
+			wantAttrs:       []attribute.KeyValue{attribute.Bool("retry", true)},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				recorder := tracetest.NewSpanRecorder()
+				provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+				_, span := provider.Tracer("test").Start(context.Background(), "operation")
+
+				// when
+				RecordSpanError(span, test.err)
+				span.End()
+
+				// then
+				spans := recorder.Ended()
+				require.Len(t, spans, 1)
+
+				if !test.wantRecorded {
+					assert.Equal(t, codes.Unset, spans[0].Status().Code)
+					assert.Empty(t, spans[0].Events())
+
+					return
+				}
+
+				assert.Equal(t, codes.Error, spans[0].Status().Code)
+				assert.Equal(t, test.wantDescription, spans[0].Status().Description)
+				assert.Equal(t, test.wantAttrs, spans[0].Attributes())
+
+				events := spans[0].Events()
+				require.Len(t, events, 1)
+				assert.Equal(t, "exception", events[0].Name)
+				assert.Contains(t, events[0].Attributes, attribute.String("exception.message", test.err.Error()))
+			},
+		)
+	}
+}
+
+func TestRecordSpanErrorWithNilSpan(t *testing.T) {
+	t.Parallel()
+
+	// when / then
+	assert.NotPanics(t, func() { RecordSpanError(nil, New("test")) })
+}
+
+func TestStructuredErrorOtelAttributes(t *testing.T) {
+	t.Parallel()
+
+	testTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		err  *StructuredError
+		name string
+		want []attribute.KeyValue
+	}{
+		{
+			name: "given_nil_error_when_otel_attributes_then_returns_empty",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "given_error_without_tags_and_attrs_when_otel_attributes_then_returns_empty",
+			err:  New("test"),
+			want: []attribute.KeyValue{},
+		},
+		{
+			name: "given_error_with_code_when_otel_attributes_then_returns_code",
+			err:  New("test").WithCode("NOT_FOUND"),
+			want: []attribute.KeyValue{attribute.String("code", "NOT_FOUND")},
+		},
+		{
+			name: "given_error_with_http_status_when_otel_attributes_then_returns_http_status",
+			err:  New("test").WithHTTPStatus(404),
+			want: []attribute.KeyValue{attribute.Int("http_status", 404)},
+		},
+		{
+			name: "given_error_with_native_attrs_when_otel_attributes_then_returns_native_types",
+			err: New("test").WithAttrs(
+				String("string", "value"),
+				Int("int", 1),
+				Int64("int64", 2),
+				Bool("bool", true),
+				Float64("float64", 1.5),
+				Strings("strings", "a", "b"),
+				Ints("ints", 1, 2),
+				Int64s("int64s", 3, 4),
+				Bools("bools", true, false),
+				Float64s("float64s", 0.5),
+			),
+			want: []attribute.KeyValue{
+				attribute.String("string", "value"),
+				attribute.Int("int", 1),
+				attribute.Int64("int64", 2),
+				attribute.Bool("bool", true),
+				attribute.Float64("float64", 1.5),
+				attribute.StringSlice("strings", []string{"a", "b"}),
+				attribute.IntSlice("ints", []int{1, 2}),
+				attribute.Int64Slice("int64s", []int64{3, 4}),
+				attribute.BoolSlice("bools", []bool{true, false}),
+				attribute.Float64Slice("float64s", []float64{0.5}),
+			},
+		},
+		{
+			name: "given_error_with_other_attrs_when_otel_attributes_then_returns_stringified_values",
+			err: New("test").WithAttrs(
+				Time("time", testTime),
+				Times("times", testTime),
+				Duration("duration", time.Second),
+				Durations("durations", time.Minute),
+				Uint64("uint64", 7),
+				Uint64s("uint64s", 8, 9),
+				Any("any", struct{ A int }{A: 1}),
+			),
+			want: []attribute.KeyValue{
+				attribute.String("time", "2024-01-02T03:04:05Z"),
+				attribute.StringSlice("times", []string{"2024-01-02T03:04:05Z"}),
+				attribute.String("duration", "1s"),
+				attribute.StringSlice("durations", []string{"1m0s"}),
+				attribute.String("uint64", "7"),
+				attribute.StringSlice("uint64s", []string{"8", "9"}),
+				attribute.String("any", "{A:1}"),
+			},
+		},
+		{
+			name: "given_error_with_object_attr_when_otel_attributes_then_flattens_with_dotted_keys",
+			err: New("test").WithAttrs(
+				Object("request", String("id", "123"), Object("user", Int("id", 7))),
+			),
+			want: []attribute.KeyValue{
+				attribute.String("request.id", "123"),
+				attribute.Int("request.user.id", 7),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.OtelAttributes()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestAttrAsOtel(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var attr *Attr
+
+	// when
+	got := attr.asOtel(nil, "prefix.")
+
+	// then
+	assert.Equal(t, []attribute.KeyValue{attribute.String("prefix.!NILVALUE", "!NILVALUE")}, got)
+}
+
+func TestStructuredErrorOtelAttributesWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	var got []attribute.KeyValue
+
+	assert.NotPanics(t, func() { got = err.OtelAttributes() })
+
+	// then
+	assert.Contains(t, fmt.Sprint(got), "not an int")
+	assert.Contains(t, fmt.Sprint(got), "john")
+}
+
+func TestRecordSpanErrorWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	_, span := provider.Tracer("test").Start(context.Background(), "operation")
+
+	// when
+	RecordSpanError(span, New(""))
+	span.End()
+
+	// then
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "NULL", spans[0].Status().Description)
+}
@@ -0,0 +1,109 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+type (
+	problemJSON struct {
+		Extensions *problemExtensions `json:"extensions,omitempty"`
+		Type       string             `json:"type,omitempty"`
+		Title      string             `json:"title,omitempty"`
+		Detail     string             `json:"detail"`
+		Status     int                `json:"status"`
+	}
+
+	problemExtensions struct {
+		Tags   []string          `json:"tags,omitempty"`
+		Errors []json.RawMessage `json:"errors,omitempty"`
+	}
+)
+
+const (
+	// ProblemJSONContentType is the media type defined by RFC 7807 for problem details.
+	ProblemJSONContentType = "application/problem+json"
+
+	minProblemStatus = 100
+	maxProblemStatus = 599
+)
+
+var (
+	// ErrMarshalProblemJSON is returned when the problem details marshaling fails.
+	ErrMarshalProblemJSON = New("failed to marshal problem JSON")
+)
+
+// MarshalProblemJSON marshals the StructuredError into a RFC 7807 problem details document.
+// It returns an error if the status is not a valid HTTP status code or if the marshaling fails.
+//
+// The returned []byte will have the following members:
+//   - type, the Code, if any
+//   - title, the http.StatusText of the status, if any
+//   - status
+//   - detail, the Message
+//   - extensions, with the Tags under "tags" and the Errors under "errors".
+//
+// Without a Code, the "type" member is omitted, which RFC 7807 defines as "about:blank".
+//
+// Usage must be like:
+//
+//	body, err := _err.MarshalProblemJSON(http.StatusBadGateway)
+//	if err != nil {
+//	  log.Fatal("what!?", err)
+//	}
+//
+//	w.Header().Set("Content-Type", errors.ProblemJSONContentType)
+//	w.WriteHeader(http.StatusBadGateway)
+//	w.Write(body)
+func (receiver *StructuredError) MarshalProblemJSON(status int) ([]byte, error) {
+	if status < minProblemStatus || status > maxProblemStatus {
+		return nil, JoinIf(New("invalid HTTP status").WithAttrs(Int("status", status)), ErrMarshalProblemJSON)
+	}
+
+	data, err := json.Marshal(receiver.asProblemJSON(status))
+	if err != nil {
+		return nil, JoinIf(err, ErrMarshalProblemJSON)
+	}
+
+	return data, nil
+}
+
+// asProblemJSON is the actual implementation for MarshalProblemJSON.
+func (receiver *StructuredError) asProblemJSON(status int) problemJSON {
+	problem := problemJSON{
+		Title:  http.StatusText(status),
+		Detail: nilValue,
+		Status: status,
+	}
+
+	if receiver == nil {
+		return problem
+	}
+
+	problem.Type = receiver.Code
+	problem.Detail = cmpOr(trimmedMessage(receiver.Message), nilValue)
+
+	if len(receiver.Tags) == zero && len(receiver.Errors) == zero {
+		return problem
+	}
+
+	problem.Extensions = &problemExtensions{
+		Tags: receiver.Tags,
+	}
+
+	if len(receiver.Errors) > zero {
+		normalized := receiver.normalizedErrors()
+
+		problem.Extensions.Errors = make([]json.RawMessage, zero, len(normalized))
+		for _, err := range normalized {
+			var bytesBuffer bytes.Buffer
+
+			errorToJSON(&jsonWriter{Buffer: &bytesBuffer}, err)
+
+			problem.Extensions.Errors = append(problem.Extensions.Errors, bytesBuffer.Bytes())
+		}
+	}
+
+	return problem
+}
@@ -0,0 +1,137 @@
+package errors
+
+import (
+	stderrors "errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredErrorMarshalProblemJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err    *StructuredError
+		status int
+		// then
+		want string
+	}{
+		{
+			name:   "given_nil_error_when_marshal_problem_json_then_returns_nil_detail",
+			err:    nil,
+			status: http.StatusInternalServerError,
+			want:   `{"title":"Internal Server Error","detail":"!NILVALUE","status":500}`,
+		},
+		{
+			name:   "given_error_with_empty_message_when_marshal_problem_json_then_returns_nil_detail",
+			err:    New(""),
+			status: http.StatusBadRequest,
+			want:   `{"title":"Bad Request","detail":"!NILVALUE","status":400}`,
+		},
+		{
+			name:   "given_error_with_message_when_marshal_problem_json_then_returns_detail",
+			err:    New(`upstream "billing" failed`),
+			status: http.StatusBadGateway,
+			want:   `{"title":"Bad Gateway","detail":"upstream \"billing\" failed","status":502}`,
+		},
+		{
+			name:   "given_error_with_code_when_marshal_problem_json_then_returns_type",
+			err:    New("user not found").WithCode("NOT_FOUND"),
+			status: http.StatusNotFound,
+			want:   `{"type":"NOT_FOUND","title":"Not Found","detail":"user not found","status":404}`,
+		},
+		{
+			name:   "given_unknown_status_when_marshal_problem_json_then_omits_title",
+			err:    New("test"),
+			status: 599,
+			want:   `{"detail":"test","status":599}`,
+		},
+		{
+			name:   "given_error_with_tags_when_marshal_problem_json_then_returns_tags_extension",
+			err:    New("test").WithTags("database", "critical"),
+			status: http.StatusServiceUnavailable,
+			want: `{"extensions":{"tags":["database","critical"]},` +
+				`"title":"Service Unavailable","detail":"test","status":503}`,
+		},
+		{
+			name: "given_error_with_nested_errors_when_marshal_problem_json_then_returns_errors_extension",
+			err: New("parent").WithErrors(
+				stderrors.New("child"),
+				New("structured").WithTags("inner"),
+				nil,
+			),
+			status: http.StatusBadGateway,
+			want: `{"extensions":{"errors":[{"message":"child"},{"message":"structured","tags":["inner"]},` +
+				`{"message":"!NILVALUE"}]},"title":"Bad Gateway","detail":"parent","status":502}`,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got, err := test.err.MarshalProblemJSON(test.status)
+
+				// then
+				require.NoError(t, err)
+				assert.JSONEq(t, test.want, string(got))
+			},
+		)
+	}
+}
+
+func TestStructuredErrorMarshalProblemJSONInvalidStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "given_zero_status_when_marshal_problem_json_then_returns_error", status: 0},
+		{name: "given_status_below_range_when_marshal_problem_json_then_returns_error", status: 99},
+		{name: "given_status_above_range_when_marshal_problem_json_then_returns_error", status: 600},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got, err := New("test").MarshalProblemJSON(test.status)
+
+				// then
+				require.ErrorIs(t, err, ErrMarshalProblemJSON)
+				assert.Nil(t, got)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorMarshalProblemJSONWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var err *StructuredError
+
+	// when
+	got, errM := err.MarshalProblemJSON(http.StatusInternalServerError)
+
+	// then
+	require.NoError(t, errM)
+	assert.JSONEq(t, `{"title":"Internal Server Error","detail":"NULL","status":500}`, string(got))
+}
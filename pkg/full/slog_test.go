@@ -1,14 +1,25 @@
 package errors
 
 import (
+	"bytes"
+	"encoding/json"
 	stderrors "errors"
 	"log/slog"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+type userValuer struct {
+	name string
+}
+
+func (v userValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("name", v.name))
+}
+
 func TestStructuredErrorLogValue(t *testing.T) {
 	t.Parallel()
 
@@ -122,6 +133,239 @@ func TestStructuredErrorLogValueAttributes(t *testing.T) {
 	}
 }
 
+func TestStructuredErrorLogValueOmitsEmptyAttrs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err       *StructuredError
+		name      string
+		wantAttrs []string
+	}{
+		{
+			name:      "given_empty_omit_empty_attr_when_log_value_then_drops_it",
+			err:       New("test").WithAttrs(String("user_id", "").OmitEmpty(), String("request_id", "42")),
+			wantAttrs: []string{"request_id"},
+		},
+		{
+			name:      "given_non_empty_omit_empty_attr_when_log_value_then_keeps_it",
+			err:       New("test").WithAttrs(String("user_id", "7").OmitEmpty(), String("request_id", "42")),
+			wantAttrs: []string{"user_id", "request_id"},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.LogValue()
+
+				// then
+				var keys []string
+
+				for _, attr := range got.Group() {
+					if attr.Key != slogKeys.Attrs {
+						continue
+					}
+
+					for _, child := range attr.Value.Group() {
+						keys = append(keys, child.Key)
+					}
+				}
+
+				assert.Equal(t, test.wantAttrs, keys)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorLogValueWithTrimMessages(t *testing.T) { //nolint:paralleltest // SetTrimMessages is not thread-safe
+	// given
+	SetTrimMessages(true)
+	t.Cleanup(func() { SetTrimMessages(false) })
+
+	err := &StructuredError{Message: "  padded  ", Errors: []error{stderrors.New("  plain  ")}}
+
+	// when
+	got := err.LogValue()
+
+	// then
+	assert.Equal(t, "[message=padded errors=[0=[message=plain]]]", got.String())
+}
+
+func TestSlogKeys(t *testing.T) { //nolint:paralleltest // SetSlogKeys is not thread-safe
+	// when
+	got := SlogKeys()
+
+	// then
+	assert.Equal(
+		t,
+		KeyConfig{
+			Message: "message", Code: "code", HTTPStatus: "http_status", Attrs: "attrs", Errors: "errors",
+			Tags: "tags", Stack: "stack", Frames: "frames", Caller: "caller", Joined: "joined",
+		},
+		got,
+	)
+}
+
+func TestStructuredErrorLogValueWithJoined(t *testing.T) { //nolint:paralleltest // SetSlogKeys is not thread-safe
+	tests := []struct {
+		name string
+		// given
+		err  error
+		keys KeyConfig
+		// then
+		wantKey    string
+		wantJoined bool
+	}{
+		{
+			name:       "given_joined_error_when_log_value_then_has_joined_attribute",
+			err:        Join(New("first"), New("second")),
+			wantKey:    "joined",
+			wantJoined: true,
+		},
+		{
+			name:       "given_joined_error_and_custom_key_when_log_value_then_uses_custom_key",
+			err:        Join(New("first"), New("second")),
+			keys:       KeyConfig{Joined: "err_joined"},
+			wantKey:    "err_joined",
+			wantJoined: true,
+		},
+		{
+			name:       "given_error_with_errors_when_log_value_then_has_no_joined_attribute",
+			err:        New("parent").WithErrors(New("first"), New("second")),
+			wantKey:    "joined",
+			wantJoined: false,
+		},
+	}
+
+	for _, tt := range tests { //nolint:paralleltest // SetSlogKeys is not thread-safe
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				// given
+				SetSlogKeys(test.keys)
+				t.Cleanup(func() { SetSlogKeys(KeyConfig{}) })
+
+				structured, ok := test.err.(*StructuredError)
+				require.True(t, ok)
+
+				// when
+				got := structured.LogValue()
+
+				// then
+				var joined *slog.Attr
+
+				for _, attr := range got.Group() {
+					if attr.Key == test.wantKey {
+						joined = &attr
+					}
+				}
+
+				if !test.wantJoined {
+					assert.Nil(t, joined)
+
+					return
+				}
+
+				if assert.NotNil(t, joined) {
+					assert.True(t, joined.Value.Bool())
+				}
+			},
+		)
+	}
+}
+
+func TestSetSlogKeys(t *testing.T) { //nolint:paralleltest // SetSlogKeys is not thread-safe
+	tests := []struct {
+		name string
+		// given
+		keys KeyConfig
+		err  *StructuredError
+		// then
+		wantKeys      []string
+		wantChildKeys []string
+	}{
+		{
+			name: "given_custom_keys_when_log_value_then_uses_custom_keys",
+			keys: KeyConfig{
+				Message:    "err_msg",
+				Code:       "err_code",
+				HTTPStatus: "err_status",
+				Attrs:      "err_attrs",
+				Errors:     "err_errors",
+				Tags:       "err_tags",
+				Stack:      "err_stack",
+			},
+			err: New("test").
+				WithCode("NOT_FOUND").
+				WithHTTPStatus(404).
+				WithTags("tag").
+				WithAttrs(String("key", "value")).
+				WithErrors(stderrors.New("child")).
+				WithStack([]byte("stack")),
+			wantKeys: []string{
+				"err_msg", "err_code", "err_status", "err_tags", "err_attrs", "err_errors", "err_stack",
+			},
+			wantChildKeys: []string{"err_msg"},
+		},
+		{
+			name:          "given_partial_keys_when_log_value_then_uses_defaults_for_empty_keys",
+			keys:          KeyConfig{Message: "err_msg"},
+			err:           New("test").WithTags("tag").WithErrors(New("child").WithTags("inner")),
+			wantKeys:      []string{"err_msg", "tags", "errors"},
+			wantChildKeys: []string{"err_msg", "tags"},
+		},
+		{
+			name:          "given_empty_keys_when_log_value_then_uses_default_keys",
+			keys:          KeyConfig{},
+			err:           New("test").WithTags("tag").WithErrors(stderrors.New("child")),
+			wantKeys:      []string{"message", "tags", "errors"},
+			wantChildKeys: []string{"message"},
+		},
+	}
+
+	for _, tt := range tests { //nolint:paralleltest // SetSlogKeys is not thread-safe
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				// given
+				SetSlogKeys(test.keys)
+				t.Cleanup(func() { SetSlogKeys(KeyConfig{}) })
+
+				// when
+				got := test.err.LogValue()
+
+				// then
+				attrs := got.Group()
+				gotKeys := make([]string, 0, len(attrs))
+
+				var children []slog.Attr
+
+				for _, attr := range attrs {
+					gotKeys = append(gotKeys, attr.Key)
+
+					if attr.Key == SlogKeys().Errors {
+						children = attr.Value.Group()
+					}
+				}
+
+				assert.Equal(t, test.wantKeys, gotKeys)
+				assert.Len(t, children, 1)
+
+				gotChildKeys := make([]string, 0, len(test.wantChildKeys))
+				for _, attr := range children[0].Value.Group() {
+					gotChildKeys = append(gotChildKeys, attr.Key)
+				}
+
+				assert.Equal(t, test.wantChildKeys, gotChildKeys)
+			},
+		)
+	}
+}
+
 func TestAttrLogValue(t *testing.T) {
 	t.Parallel()
 
@@ -696,3 +940,231 @@ func TestSliceToSlogWithTypedSlices(t *testing.T) {
 		)
 	}
 }
+
+func TestStructuredErrorLogValueRedactsSensitiveAttrs(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var buffer bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+	err := New("test").WithAttrs(Sensitive("password", "secret"), String("user", "john"))
+
+	// when
+	logger.Error("failed", slog.Any("error", err))
+
+	// then
+	assert.Contains(t, buffer.String(), `"password":"[REDACTED]"`)
+	assert.Contains(t, buffer.String(), `"user":"john"`)
+	assert.NotContains(t, buffer.String(), "secret")
+	assert.Equal(t, "secret", err.Attrs[0].Value)
+}
+
+func TestStructuredErrorLogValueWithoutOmitEmpty(t *testing.T) { //nolint:paralleltest // SetOmitEmpty is not thread-safe
+	// given
+	SetOmitEmpty(false)
+	t.Cleanup(func() { SetOmitEmpty(true) })
+
+	var buffer bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+	err := New("test")
+
+	// when
+	value := err.LogValue()
+
+	logger.Error("failed", slog.Any("error", err))
+
+	// then
+	group := value.Group()
+	require.Len(t, group, 5)
+
+	for index, key := range []string{"message", "tags", "attrs", "errors", "stack"} {
+		assert.Equal(t, key, group[index].Key)
+	}
+
+	assert.Contains(t, buffer.String(), `"error":{"message":"test","tags":[],"attrs":[],"errors":[],"stack":[]}`)
+}
+
+func TestStructuredErrorLogValueWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var buffer bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	assert.NotPanics(t, func() { logger.Error("failed", slog.Any("error", err)) })
+
+	// then
+	assert.Contains(t, buffer.String(), `"count":"not an int"`)
+	assert.Contains(t, buffer.String(), `"user":"john"`)
+}
+
+func TestStructuredErrorLogValueWithJSONRawAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var buffer bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+	err := New("test").WithAttrs(JSONRaw("upstream", []byte(`{"id":1}`)))
+
+	// when
+	logger.Error("failed", slog.Any("error", err))
+
+	// then
+	assert.Contains(t, buffer.String(), `"upstream":"{\"id\":1}"`)
+}
+
+func TestStructuredErrorLogValueWithObjectBuilder(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var built, variadic bytes.Buffer
+
+	builtErr := New("test").WithAttrs(
+		NewObject("user").
+			Str("name", "john").
+			Int("age", 30).
+			Object(NewObject("address").Str("city", "NYC")).
+			Build(),
+	)
+	variadicErr := New("test").WithAttrs(
+		Object(
+			"user",
+			String("name", "john"),
+			Int("age", 30),
+			Object("address", String("city", "NYC")),
+		),
+	)
+
+	options := &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return attr
+		},
+	}
+
+	// when
+	slog.New(slog.NewJSONHandler(&built, options)).Error("failed", slog.Any("error", builtErr))
+	slog.New(slog.NewJSONHandler(&variadic, options)).Error("failed", slog.Any("error", variadicErr))
+
+	// then
+	assert.Contains(t, built.String(), `"user":{"name":"john","age":30,"address":{"city":"NYC"}}`)
+	assert.Equal(t, variadic.String(), built.String())
+}
+
+func TestLogValuer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value slog.LogValuer
+		name  string
+		want  string
+	}{
+		{
+			name:  "given_custom_log_valuer_when_stringified_then_returns_resolved_value",
+			value: userValuer{name: "john"},
+			want:  "[name=john]",
+		},
+		{
+			name:  "given_nil_log_valuer_when_stringified_then_returns_nil_value",
+			value: nil,
+			want:  nilValue,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// when
+			got := LogValuer("user", test.value)
+
+			// then
+			assert.Equal(t, LogValuerType, got.Type)
+			assert.Equal(t, "(user="+test.want+")", got.String())
+		})
+	}
+}
+
+func TestStructuredErrorLogValueWithLogValuer(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var buffer bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+	err := New("test").WithAttrs(
+		LogValuer("user", userValuer{name: "john"}),
+		LogValuer("missing", nil),
+	)
+
+	// when
+	logger.Error("failed", slog.Any("error", err))
+
+	// then
+	assert.Contains(t, buffer.String(), `"user":{"name":"john"}`)
+	assert.Contains(t, buffer.String(), `"missing":"!NILVALUE"`)
+}
+
+func TestStructuredErrorMarshalJSONWithLogValuer(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(
+		LogValuer("user", userValuer{name: "john"}),
+		LogValuer("missing", nil),
+	)
+
+	// when
+	got, marshalErr := json.Marshal(err)
+
+	// then
+	assert.NoError(t, marshalErr)
+	assert.Contains(t, string(got), `{"value":"[name=john]","key":"user","type":21}`)
+	assert.Contains(t, string(got), `{"value":"!NILVALUE","key":"missing","type":21}`)
+
+	// when
+	var decoded StructuredError
+
+	unmarshalErr := json.Unmarshal(got, &decoded)
+
+	// then
+	assert.NoError(t, unmarshalErr)
+	assert.Equal(t, String("user", "[name=john]"), decoded.Attrs[0])
+}
+
+func TestStructuredErrorLogValueWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var (
+		err    *StructuredError
+		buffer bytes.Buffer
+	)
+
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+
+	// when
+	logger.Error("failed", slog.Any("error", err))
+
+	// then
+	assert.Contains(t, buffer.String(), `"message":"NULL"`)
+}
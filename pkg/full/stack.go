@@ -0,0 +1,318 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+const (
+	goroutinePrefix = "goroutine "
+	createdByPrefix = "created by "
+	inGoroutine     = " in goroutine "
+	offsetPrefix    = " +"
+	runtimePrefix   = "runtime."
+
+	// captureCallerSkip is the number of frames to skip to reach the caller of
+	// WithCaller or WithCallerSkip: captureCaller and the exported method.
+	captureCallerSkip = 2
+
+	// captureStackSkip is the number of frames to skip to reach the caller of
+	// CaptureStack or CaptureStackSkip: runtime.Callers, captureStack and the exported method.
+	captureStackSkip = 3
+
+	// maxCapturedFrames is the maximum number of frames captured by CaptureStack.
+	maxCapturedFrames = 64
+
+	// fromPanicSkip is the number of frames of debug.Stack removed by FromPanic
+	// to reach its caller: debug.Stack and FromPanic.
+	fromPanicSkip = 2
+
+	// panicTag is the tag, and the message of wrapped errors, of the errors built by FromPanic.
+	panicTag = "panic"
+)
+
+// FromPanic returns a StructuredError built from the given value returned by recover,
+// tagged "panic" and with the stack of the panicking goroutine, as returned by debug.Stack.
+//
+// If the recovered value is an error, it is wrapped by an error with the "panic" message,
+// so it is reachable via Is and As. Otherwise, the message is the value formatted with fmt.Sprint.
+// If the recovered value is nil, FromPanic returns nil.
+//
+// FromPanic should be called in the deferred function that recovers, so the stack starts at it:
+//
+//	defer func() {
+//		if err := errors.FromPanic(recover()); err != nil {
+//			...
+//		}
+//	}()
+func FromPanic(recovered any) *StructuredError {
+	if recovered == nil {
+		return nil
+	}
+
+	var err *StructuredError
+
+	if value, ok := recovered.(error); ok {
+		err = New(panicTag).WithErrors(value)
+	} else {
+		err = New(fmt.Sprint(recovered))
+	}
+
+	return err.WithTags(panicTag).WithStackSkip(debug.Stack(), fromPanicSkip)
+}
+
+// WithParsedStack parses the given goroutine stack, as returned by debug.Stack,
+// into frames and sets them on the receiver, returning it for chaining.
+//
+// Unlike WithStack, the raw bytes are not kept, so marshalers emit the frames
+// instead of an opaque blob. Lines that are not part of a frame are ignored.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithParsedStack(stack []byte) *StructuredError {
+	receiver.frames = parseStack(stack)
+	receiver.pcs = nil
+
+	return receiver
+}
+
+// WithStackSkip works like WithStack but removes the first skipFrames frames of the given goroutine stack,
+// as returned by debug.Stack, before setting it on the receiver, returning it for chaining.
+//
+// Each frame is a function line and its tab-indented "file:line" location line.
+// The "goroutine N [status]:" header and any line that is not part of a frame are kept.
+// A skipFrames of zero or less is the same as WithStack.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithStackSkip(stack []byte, skipFrames int) *StructuredError {
+	receiver.Stack = skipStackFrames(stack, skipFrames)
+
+	return receiver
+}
+
+// CaptureStack captures the stack of the calling goroutine as frames, with the caller at the top,
+// and sets them on the receiver, returning it for chaining.
+//
+// Trailing runtime frames, such as runtime.main and runtime.goexit, are trimmed.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) CaptureStack() *StructuredError {
+	receiver.pcs, receiver.frames = captureStack(zero)
+
+	return receiver
+}
+
+// CaptureStackSkip works like CaptureStack but skips the given number of frames above the caller,
+// so helpers that build errors can keep their own frames out of the stack.
+// A skip of zero is the same as CaptureStack.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) CaptureStackSkip(skip int) *StructuredError {
+	receiver.pcs, receiver.frames = captureStack(skip)
+
+	return receiver
+}
+
+// WithCaller records the "file:line" of its call site on the receiver, and returns it for chaining.
+//
+// It captures a single frame via runtime.Caller, so it is far cheaper than a full stack
+// when only the call site is needed. The caller is marshaled as the "caller" field by
+// MarshalJSON, LogValue and MarshalZerologObject.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithCaller() *StructuredError {
+	receiver.caller = captureCaller(zero)
+
+	return receiver
+}
+
+// WithCallerSkip works like WithCaller but skips the given number of frames above the caller,
+// so helpers that build errors can record the call site of their own caller.
+// A skip of zero is the same as WithCaller.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithCallerSkip(skip int) *StructuredError {
+	receiver.caller = captureCaller(skip)
+
+	return receiver
+}
+
+// Caller returns the "file:line" recorded with WithCaller or WithCallerSkip.
+// If no caller was recorded, it returns an empty string.
+func (receiver *StructuredError) Caller() string {
+	return receiver.caller
+}
+
+// captureCaller returns the "file:line" of the caller of the exported method,
+// skipping the given number of frames above it, or an empty string if there is no such frame.
+func captureCaller(skip int) string {
+	if skip < zero {
+		skip = zero
+	}
+
+	_, file, line, ok := runtime.Caller(captureCallerSkip + skip)
+	if !ok {
+		return emptyString
+	}
+
+	return file + colon + strconv.Itoa(line)
+}
+
+// Frames returns the stack frames set with WithParsedStack, CaptureStack or CaptureStackSkip.
+// If no parsed stack was set, it returns nil.
+func (receiver *StructuredError) Frames() []StackFrame {
+	return receiver.frames
+}
+
+// StackTrace returns the program counters of the stack captured with CaptureStack or CaptureStackSkip,
+// with the caller at the top.
+//
+// It mirrors the StackTrace method of github.com/pkg/errors, so integrations such as Sentry
+// can extract the frames, which can be resolved with runtime.CallersFrames.
+//
+// It returns nil if the stack was set with WithStack or WithParsedStack,
+// since raw debug.Stack bytes carry no program counters.
+func (receiver *StructuredError) StackTrace() []uintptr {
+	return receiver.pcs
+}
+
+// captureStack returns the program counters and the frames of the calling goroutine,
+// skipping the given number of frames above the caller of the exported method,
+// up to maxCapturedFrames frames.
+func captureStack(skip int) ([]uintptr, []StackFrame) {
+	if skip < zero {
+		skip = zero
+	}
+
+	pcs := make([]uintptr, maxCapturedFrames)
+	length := runtime.Callers(captureStackSkip+skip, pcs)
+	pcs = pcs[:length]
+
+	frames := make([]StackFrame, zero, length)
+	callersFrames := runtime.CallersFrames(pcs)
+
+	for {
+		frame, more := callersFrames.Next()
+		if frame.Function != emptyString {
+			frames = append(frames, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	for len(frames) > zero && strings.HasPrefix(frames[len(frames)-one].Function, runtimePrefix) {
+		frames = frames[:len(frames)-one]
+	}
+
+	return pcs, frames
+}
+
+// parseStack parses the output of debug.Stack into a slice of StackFrame.
+//
+// The expected format is a "goroutine N [status]:" header followed by pairs of lines,
+// the function call and its tab-indented "file:line +0xoffset" location.
+func parseStack(stack []byte) []StackFrame {
+	lines := strings.Split(string(stack), newLine)
+	frames := make([]StackFrame, zero, len(lines)>>one)
+
+	for index := zero; index < len(lines)-one; index++ {
+		function := lines[index]
+		if function == emptyString || strings.HasPrefix(function, tab) || strings.HasPrefix(function, goroutinePrefix) {
+			continue
+		}
+
+		location := lines[index+one]
+		if !strings.HasPrefix(location, tab) {
+			continue
+		}
+
+		file, line, ok := parseStackLocation(location)
+		if !ok {
+			continue
+		}
+
+		frames = append(frames, StackFrame{
+			Function: parseStackFunction(function),
+			File:     file,
+			Line:     line,
+		})
+		index++
+	}
+
+	return frames
+}
+
+// skipStackFrames removes the first skip frames, function and location line pairs,
+// from the output of debug.Stack, keeping the rest of the lines unchanged.
+func skipStackFrames(stack []byte, skip int) []byte {
+	if skip <= zero || len(stack) == zero {
+		return stack
+	}
+
+	lines := strings.SplitAfter(string(stack), newLine)
+	result := make([]byte, zero, len(stack))
+	skipped := zero
+
+	for index := zero; index < len(lines); index++ {
+		function := strings.TrimSuffix(lines[index], newLine)
+
+		isFrame := function != emptyString &&
+			!strings.HasPrefix(function, tab) &&
+			!strings.HasPrefix(function, goroutinePrefix) &&
+			index+one < len(lines) &&
+			strings.HasPrefix(lines[index+one], tab)
+
+		if isFrame && skipped < skip {
+			skipped++
+			index++
+
+			continue
+		}
+
+		result = append(result, lines[index]...)
+	}
+
+	return result
+}
+
+// parseStackFunction strips the call arguments, or the "created by" decoration,
+// from a function line of a goroutine stack.
+func parseStackFunction(function string) string {
+	if strings.HasPrefix(function, createdByPrefix) {
+		function = strings.TrimPrefix(function, createdByPrefix)
+
+		if index := strings.Index(function, inGoroutine); index >= zero {
+			function = function[:index]
+		}
+
+		return function
+	}
+
+	if strings.HasSuffix(function, parenthesisClose) {
+		if index := strings.LastIndex(function, parenthesisOpen); index > zero {
+			function = function[:index]
+		}
+	}
+
+	return function
+}
+
+// parseStackLocation parses a "\tfile:line +0xoffset" location line of a goroutine stack.
+// It returns false if the line has no valid line number.
+func parseStackLocation(location string) (string, int, bool) {
+	location = strings.TrimPrefix(location, tab)
+
+	if index := strings.LastIndex(location, offsetPrefix); index >= zero {
+		location = location[:index]
+	}
+
+	index := strings.LastIndex(location, colon)
+	if index < zero {
+		return emptyString, zero, false
+	}
+
+	line, err := strconv.Atoi(location[index+one:])
+	if err != nil {
+		return emptyString, zero, false
+	}
+
+	return location[:index], line, true
+}
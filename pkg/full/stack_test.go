@@ -0,0 +1,583 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredErrorWithParsedStack(t *testing.T) {
+	t.Parallel()
+
+	// given
+	_, file, line, ok := runtime.Caller(0)
+	require.True(t, ok)
+
+	stack := debug.Stack()
+
+	// when
+	err := New("test").WithParsedStack(stack)
+
+	// then
+	frames := err.Frames()
+	require.NotEmpty(t, frames)
+	assert.Equal(t, "runtime/debug.Stack", frames[0].Function)
+	assert.True(t, strings.HasSuffix(frames[0].File, "runtime/debug/stack.go"))
+	assert.Positive(t, frames[0].Line)
+
+	require.GreaterOrEqual(t, len(frames), 2)
+	assert.True(t, strings.HasSuffix(frames[1].Function, ".TestStructuredErrorWithParsedStack"))
+	assert.Equal(t, file, frames[1].File)
+	assert.Equal(t, line+3, frames[1].Line)
+
+	assert.Empty(t, err.Stack)
+}
+
+func TestStructuredErrorFrames(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test")
+
+	// when
+	got := err.Frames()
+
+	// then
+	assert.Nil(t, got)
+}
+
+func TestParseStack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		stack string
+		// then
+		want []StackFrame
+	}{
+		{
+			name:  "given_empty_stack_when_parse_stack_then_returns_empty",
+			stack: "",
+			want:  []StackFrame{},
+		},
+		{
+			name: "given_goroutine_stack_when_parse_stack_then_returns_frames",
+			stack: "goroutine 1 [running]:\n" +
+				"runtime/debug.Stack()\n" +
+				"\t/usr/local/go/src/runtime/debug/stack.go:24 +0x5e\n" +
+				"main.(*Server).handle(0xc000010000, {0x1, 0x2})\n" +
+				"\t/app/server.go:42 +0x1d\n" +
+				"main.main()\n" +
+				"\t/app/main.go:8 +0x1d\n",
+			want: []StackFrame{
+				{Function: "runtime/debug.Stack", File: "/usr/local/go/src/runtime/debug/stack.go", Line: 24},
+				{Function: "main.(*Server).handle", File: "/app/server.go", Line: 42},
+				{Function: "main.main", File: "/app/main.go", Line: 8},
+			},
+		},
+		{
+			name: "given_created_by_frame_when_parse_stack_then_strips_decoration",
+			stack: "goroutine 7 [running]:\n" +
+				"main.worker(...)\n" +
+				"\t/app/worker.go:10\n" +
+				"created by main.main in goroutine 1\n" +
+				"\t/app/main.go:5 +0x25\n",
+			want: []StackFrame{
+				{Function: "main.worker", File: "/app/worker.go", Line: 10},
+				{Function: "main.main", File: "/app/main.go", Line: 5},
+			},
+		},
+		{
+			name: "given_malformed_lines_when_parse_stack_then_skips_them",
+			stack: "not a frame\n" +
+				"main.main()\n" +
+				"\t/app/main.go:nope +0x1d\n" +
+				"main.other()\n" +
+				"\t/app/other.go:3 +0x1d\n" +
+				"main.dangling()",
+			want: []StackFrame{
+				{Function: "main.other", File: "/app/other.go", Line: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := parseStack([]byte(test.stack))
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorWithStackSkip(t *testing.T) {
+	t.Parallel()
+
+	stack := "goroutine 1 [running]:\n" +
+		"runtime/debug.Stack()\n" +
+		"\t/usr/local/go/src/runtime/debug/stack.go:24 +0x5e\n" +
+		"main.newError()\n" +
+		"\t/app/errors.go:12 +0x1d\n" +
+		"main.(*Server).handle(0xc000010000, {0x1, 0x2})\n" +
+		"\t/app/server.go:42 +0x1d\n" +
+		"main.main()\n" +
+		"\t/app/main.go:8 +0x1d\n"
+
+	tests := []struct {
+		name string
+		// given
+		skipFrames int
+		// then
+		want string
+	}{
+		{
+			name:       "given_zero_skip_when_with_stack_skip_then_keeps_stack",
+			skipFrames: 0,
+			want:       stack,
+		},
+		{
+			name:       "given_negative_skip_when_with_stack_skip_then_keeps_stack",
+			skipFrames: -1,
+			want:       stack,
+		},
+		{
+			name:       "given_skip_when_with_stack_skip_then_drops_first_frames",
+			skipFrames: 2,
+			want: "goroutine 1 [running]:\n" +
+				"main.(*Server).handle(0xc000010000, {0x1, 0x2})\n" +
+				"\t/app/server.go:42 +0x1d\n" +
+				"main.main()\n" +
+				"\t/app/main.go:8 +0x1d\n",
+		},
+		{
+			name:       "given_skip_greater_than_frames_when_with_stack_skip_then_keeps_header",
+			skipFrames: 10,
+			want:       "goroutine 1 [running]:\n",
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := New("test").WithStackSkip([]byte(stack), test.skipFrames)
+
+				// then
+				assert.Equal(t, test.want, string(got.Stack))
+			},
+		)
+	}
+
+	t.Run(
+		"given_debug_stack_when_with_stack_skip_then_drops_debug_stack_frame", func(t *testing.T) {
+			t.Parallel()
+
+			// when
+			got := New("test").WithStackSkip(debug.Stack(), 1)
+
+			// then
+			frames := parseStack(got.Stack)
+			require.NotEmpty(t, frames)
+			assert.NotEqual(t, "runtime/debug.Stack", frames[0].Function)
+			assert.Contains(t, frames[0].Function, "TestStructuredErrorWithStackSkip")
+		},
+	)
+}
+
+func TestStructuredErrorMarshalJSONWithFrames(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithParsedStack([]byte("main.main()\n\t/app/main.go:8 +0x1d\n"))
+
+	// when
+	got, _err := json.Marshal(err)
+
+	// then
+	require.NoError(t, _err)
+	assert.JSONEq(
+		t,
+		`{"message":"test","frames":[{"function":"main.main","file":"/app/main.go","line":8}]}`,
+		string(got),
+	)
+
+	var unmarshaled StructuredError
+
+	require.NoError(t, json.Unmarshal(got, &unmarshaled))
+	assert.Equal(t, err.Frames(), unmarshaled.Frames())
+}
+
+func TestStructuredErrorLogValueWithFrames(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var buffer bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+	err := New("test").WithParsedStack([]byte("main.main()\n\t/app/main.go:8 +0x1d\n"))
+
+	// when
+	logger.Error("failed", slog.Any("error", err))
+
+	// then
+	var got map[string]any
+
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &got))
+	assert.Equal(
+		t,
+		map[string]any{
+			"message": "test",
+			"frames": map[string]any{
+				"0": map[string]any{"function": "main.main", "file": "/app/main.go", "line": float64(8)},
+			},
+		},
+		got["error"],
+	)
+}
+
+func TestStructuredErrorCaptureStack(t *testing.T) {
+	t.Parallel()
+
+	// given
+	_, file, line, ok := runtime.Caller(0)
+	require.True(t, ok)
+
+	// when
+	err := New("test").CaptureStack()
+
+	// then
+	frames := err.Frames()
+	require.NotEmpty(t, frames)
+	assert.True(t, strings.HasSuffix(frames[0].Function, ".TestStructuredErrorCaptureStack"))
+	assert.Equal(t, file, frames[0].File)
+	assert.Equal(t, line+4, frames[0].Line)
+	assert.False(t, strings.HasPrefix(frames[len(frames)-1].Function, "runtime."))
+}
+
+func TestStructuredErrorCaptureStackSkip(t *testing.T) {
+	t.Parallel()
+
+	// given
+	_, file, line, ok := runtime.Caller(0)
+	require.True(t, ok)
+
+	helper := func(skip int) *StructuredError {
+		return New("test").CaptureStackSkip(skip)
+	}
+
+	tests := []struct {
+		name string
+		// given
+		skip int
+		// then
+		wantFunction string
+		wantLine     int
+	}{
+		{
+			name:         "given_zero_skip_when_capture_stack_skip_then_top_frame_is_caller",
+			skip:         0,
+			wantFunction: ".TestStructuredErrorCaptureStackSkip.func1",
+			wantLine:     line + 4,
+		},
+		{
+			name:         "given_negative_skip_when_capture_stack_skip_then_top_frame_is_caller",
+			skip:         -1,
+			wantFunction: ".TestStructuredErrorCaptureStackSkip.func1",
+			wantLine:     line + 4,
+		},
+		{
+			name:         "given_one_skip_when_capture_stack_skip_then_top_frame_is_caller_of_helper",
+			skip:         1,
+			wantFunction: ".TestStructuredErrorCaptureStackSkip.func2",
+			wantLine:     line + 42,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				err := helper(test.skip)
+
+				// then
+				frames := err.Frames()
+				require.NotEmpty(t, frames)
+				assert.True(t, strings.HasSuffix(frames[0].Function, test.wantFunction), frames[0].Function)
+				assert.Equal(t, file, frames[0].File)
+				assert.Equal(t, test.wantLine, frames[0].Line)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorWithCaller(t *testing.T) {
+	t.Parallel()
+
+	// given
+	_, file, line, ok := runtime.Caller(0)
+	require.True(t, ok)
+
+	// when
+	err := New("test").WithCaller()
+
+	// then
+	assert.Equal(t, file+":"+strconv.Itoa(line+4), err.Caller())
+	assert.Empty(t, err.Frames())
+}
+
+func TestStructuredErrorWithCallerSkip(t *testing.T) {
+	t.Parallel()
+
+	// given
+	_, file, line, ok := runtime.Caller(0)
+	require.True(t, ok)
+
+	helper := func(skip int) *StructuredError {
+		return New("test").WithCallerSkip(skip)
+	}
+
+	tests := []struct {
+		name string
+		// given
+		skip int
+		// then
+		wantLine int
+	}{
+		{
+			name:     "given_zero_skip_when_with_caller_skip_then_caller_is_helper",
+			skip:     0,
+			wantLine: line + 4,
+		},
+		{
+			name:     "given_negative_skip_when_with_caller_skip_then_caller_is_helper",
+			skip:     -1,
+			wantLine: line + 4,
+		},
+		{
+			name:     "given_one_skip_when_with_caller_skip_then_caller_is_caller_of_helper",
+			skip:     1,
+			wantLine: line + 38,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				err := helper(test.skip)
+
+				// then
+				assert.Equal(t, file+":"+strconv.Itoa(test.wantLine), err.Caller())
+			},
+		)
+	}
+}
+
+func TestStructuredErrorCallerWithoutCapture(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test")
+
+	// when
+	got := err.Caller()
+
+	// then
+	assert.Empty(t, got)
+}
+
+func TestStructuredErrorMarshalJSONWithCaller(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithCaller()
+
+	// when
+	got, _err := json.Marshal(err)
+
+	// then
+	require.NoError(t, _err)
+	assert.JSONEq(t, `{"message":"test","caller":"`+err.Caller()+`"}`, string(got))
+
+	var unmarshaled StructuredError
+
+	require.NoError(t, json.Unmarshal(got, &unmarshaled))
+	assert.Equal(t, err.Caller(), unmarshaled.Caller())
+}
+
+func TestStructuredErrorLogValueWithCaller(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var buffer bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+	err := New("test").WithCaller()
+
+	// when
+	logger.Error("failed", slog.Any("error", err))
+
+	// then
+	var got map[string]any
+
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &got))
+	assert.Equal(t, map[string]any{"message": "test", "caller": err.Caller()}, got["error"])
+}
+
+func TestStructuredErrorStackTrace(t *testing.T) {
+	t.Parallel()
+
+	// given
+	_, file, line, ok := runtime.Caller(0)
+	require.True(t, ok)
+
+	// when
+	err := New("test").CaptureStack()
+
+	// then
+	pcs := err.StackTrace()
+	require.NotEmpty(t, pcs)
+
+	frame, _ := runtime.CallersFrames(pcs).Next()
+	assert.True(t, strings.HasSuffix(frame.Function, ".TestStructuredErrorStackTrace"))
+	assert.Equal(t, file, frame.File)
+	assert.Equal(t, line+4, frame.Line)
+}
+
+func TestStructuredErrorStackTraceWithoutCapture(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+	}{
+		{
+			name: "given_error_without_stack_when_stack_trace_then_returns_nil",
+			err:  New("test"),
+		},
+		{
+			name: "given_error_with_raw_stack_when_stack_trace_then_returns_nil",
+			err:  New("test").WithStack(debug.Stack()),
+		},
+		{
+			name: "given_error_with_parsed_stack_after_capture_when_stack_trace_then_returns_nil",
+			err:  New("test").CaptureStack().WithParsedStack(debug.Stack()),
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.StackTrace()
+
+				// then
+				assert.Nil(t, got)
+			},
+		)
+	}
+}
+
+func TestFromPanic(t *testing.T) {
+	t.Parallel()
+
+	recoverFrom := func(value any) (err *StructuredError) {
+		defer func() {
+			err = FromPanic(recover())
+		}()
+
+		panic(value)
+	}
+
+	tests := []struct {
+		name string
+		// given
+		recovered any
+		// then
+		wantMessage string
+		wantErrors  []error
+	}{
+		{
+			name:        "given_string_panic_when_from_panic_then_uses_value_as_message",
+			recovered:   "something went wrong",
+			wantMessage: "something went wrong",
+		},
+		{
+			name:        "given_non_string_panic_when_from_panic_then_formats_value_as_message",
+			recovered:   42,
+			wantMessage: "42",
+		},
+		{
+			name:        "given_error_panic_when_from_panic_then_wraps_error",
+			recovered:   io.EOF,
+			wantMessage: "panic",
+			wantErrors:  []error{io.EOF},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				err := recoverFrom(test.recovered)
+
+				// then
+				require.NotNil(t, err)
+				assert.Equal(t, test.wantMessage, err.Message)
+				assert.Equal(t, test.wantErrors, err.Errors)
+				assert.Equal(t, []string{"panic"}, err.Tags)
+				assert.True(t, bytes.HasPrefix(err.Stack, []byte("goroutine ")))
+				assert.NotContains(t, string(err.Stack), "runtime/debug.Stack")
+				assert.Contains(t, string(err.Stack), "panic(")
+				assert.Contains(t, string(err.Stack), ".TestFromPanic.func")
+
+				if test.wantErrors != nil {
+					assert.ErrorIs(t, err, test.recovered.(error)) //nolint:forcetypeassert,errcheck // only errors are wrapped
+				}
+			},
+		)
+	}
+}
+
+func TestFromPanicNil(t *testing.T) {
+	t.Parallel()
+
+	// when
+	err := FromPanic(nil)
+
+	// then
+	assert.Nil(t, err)
+}
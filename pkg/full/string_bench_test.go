@@ -0,0 +1,52 @@
+package errors
+
+import (
+	stderrors "errors"
+	"testing"
+)
+
+func BenchmarkStructuredErrorError(b *testing.B) {
+	err := New("test").
+		WithAttrs(String("key", "value"), Int("count", 42)).
+		WithTags("tag").
+		WithErrors(stderrors.New("child"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = err.Error()
+	}
+}
+
+func BenchmarkStructuredErrorErrorNested(b *testing.B) {
+	err := New("parent").
+		WithAttrs(Object("request", String("id", "123"), Int("retries", 3))).
+		WithErrors(
+			New("child").WithAttrs(Bool("temporary", true)).WithErrors(stderrors.New("leaf")),
+			Join(stderrors.New("first"), stderrors.New("second")),
+		)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_ = err.Error()
+	}
+}
+
+func BenchmarkStructuredErrorErrorParallel(b *testing.B) {
+	err := New("test").
+		WithAttrs(String("key", "value"), Int("count", 42)).
+		WithTags("tag").
+		WithErrors(stderrors.New("child"))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = err.Error()
+		}
+	})
+}
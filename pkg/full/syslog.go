@@ -0,0 +1,282 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	syslogSDName          = "error"
+	syslogMaxParamNameLen = 32
+)
+
+//nolint:gochecknoglobals // needed to avoid use of specific instance of StructuredError
+var (
+	// syslogEnterpriseNumber defaults to 32473, the private enterprise number reserved for documentation by RFC 5612.
+	syslogEnterpriseNumber = "32473"
+)
+
+// SyslogEnterpriseNumber returns the private enterprise number used in the SD-ID written by MarshalSyslogSD.
+func SyslogEnterpriseNumber() string {
+	return syslogEnterpriseNumber
+}
+
+// SetSyslogEnterpriseNumber sets the private enterprise number used in the SD-ID written by MarshalSyslogSD,
+// so the structured data element is written as [error@<number> ...].
+//
+// The default value is 32473, the private enterprise number reserved for documentation by RFC 5612.
+//
+// SetSyslogEnterpriseNumber is not thread-safe. It should be called before any
+// StructuredError is marshaled.
+func SetSyslogEnterpriseNumber(number uint32) {
+	syslogEnterpriseNumber = strconv.FormatUint(uint64(number), ten)
+}
+
+// MarshalSyslogSD marshals the StructuredError into an RFC 5424 structured data element,
+// with the SD-ID error@<enterprise number>, like:
+//
+//	[error@32473 message="failed" code="NOT_FOUND" tag="a" tag="b" request_id="123"]
+//
+// If the receiver is nil, the element has a single "message" param with the value nilValue.
+//
+// Otherwise, it will have the following params:
+//   - message
+//   - code, if not empty
+//   - tag, repeated for every tag
+//   - <key> for every attr, slices repeat the param and objects use dotted names
+//   - errors.<index>.<name>, nested errors use indexed prefixes
+//   - stack.
+//
+// Param values escape '"', '\' and ']' with a backslash, as RFC 5424 requires.
+// Param names replace '=', ' ', ']', '"' and non-printable characters by an underscore,
+// and are truncated to 32 characters.
+func (receiver *StructuredError) MarshalSyslogSD() string {
+	var stringsBuilder strings.Builder
+
+	stringsBuilder.WriteString(bracketOpen)
+	stringsBuilder.WriteString(syslogSDName)
+	stringsBuilder.WriteString("@")
+	stringsBuilder.WriteString(syslogEnterpriseNumber)
+
+	receiver.asSyslogSD(&stringsBuilder, emptyString)
+
+	stringsBuilder.WriteString(bracketClose)
+
+	return stringsBuilder.String()
+}
+
+// asSyslogSD is the actual implementation for MarshalSyslogSD.
+// It writes the params of the receiver, with names prefixed with the given prefix, to the provided strings.Builder.
+func (receiver *StructuredError) asSyslogSD(stringsBuilder *strings.Builder, prefix string) {
+	if receiver == nil {
+		paramToSyslogSD(stringsBuilder, prefix+messageKey, nilValue)
+
+		return
+	}
+
+	paramToSyslogSD(stringsBuilder, prefix+messageKey, cmpOr(trimmedMessage(receiver.Message), nilValue))
+
+	if receiver.Code != emptyString {
+		paramToSyslogSD(stringsBuilder, prefix+codeKey, receiver.Code)
+	}
+
+	if receiver.HTTPStatus != zero {
+		paramToSyslogSD(stringsBuilder, prefix+httpStatusKey, strconv.Itoa(receiver.HTTPStatus))
+	}
+
+	for _, tag := range receiver.Tags {
+		paramToSyslogSD(stringsBuilder, prefix+tagKey, strings.TrimSpace(tag))
+	}
+
+	for _, attr := range withoutOmittedAttrs(receiver.Attrs) {
+		attr.asSyslogSD(stringsBuilder, prefix)
+	}
+
+	if len(receiver.Errors) > zero {
+		normalized := receiver.normalizedErrors()
+
+		for index, err := range normalized {
+			errorToSyslogSD(stringsBuilder, prefix+errorsKey+dot+strconv.Itoa(index)+dot, err)
+		}
+	}
+
+	if keepField(len(receiver.Stack)) {
+		paramToSyslogSD(stringsBuilder, prefix+stackKey, string(receiver.Stack))
+	}
+}
+
+// asSyslogSD writes the params of the receiver, with names prefixed with the given prefix,
+// to the provided strings.Builder.
+//
+// Slices write one param per item with the same name, and objects write their attrs with dotted names.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) asSyslogSD(stringsBuilder *strings.Builder, prefix string) {
+	receiver = receiver.redacted()
+
+	name := prefix + receiver.Key
+
+	switch receiver.Type {
+	case AnyType:
+		paramToSyslogSD(stringsBuilder, name, fmt.Sprintf(verboseFormat, receiver.Value))
+	case ObjectType:
+		for _, attr := range receiver.Value.([]Attr) {
+			attr.asSyslogSD(stringsBuilder, name+dot)
+		}
+	case BoolType:
+		paramToSyslogSD(stringsBuilder, name, strconv.FormatBool(receiver.Value.(bool)))
+	case BoolsType:
+		sliceToSyslogSD(stringsBuilder, name, receiver.Value.([]bool))
+	case TimeType:
+		paramToSyslogSD(stringsBuilder, name, receiver.Value.(time.Time).Format(time.RFC3339Nano))
+	case TimesType:
+		sliceToSyslogSD(stringsBuilder, name, receiver.Value.([]time.Time))
+	case DurationType:
+		paramToSyslogSD(stringsBuilder, name, receiver.Value.(time.Duration).String())
+	case DurationsType:
+		sliceToSyslogSD(stringsBuilder, name, receiver.Value.([]time.Duration))
+	case IntType:
+		paramToSyslogSD(stringsBuilder, name, strconv.Itoa(receiver.Value.(int)))
+	case IntsType:
+		sliceToSyslogSD(stringsBuilder, name, receiver.Value.([]int))
+	case Int64Type:
+		paramToSyslogSD(stringsBuilder, name, strconv.FormatInt(receiver.Value.(int64), ten))
+	case Int64sType:
+		sliceToSyslogSD(stringsBuilder, name, receiver.Value.([]int64))
+	case Uint64Type:
+		paramToSyslogSD(stringsBuilder, name, strconv.FormatUint(receiver.Value.(uint64), ten))
+	case Uint64sType:
+		sliceToSyslogSD(stringsBuilder, name, receiver.Value.([]uint64))
+	case Float64Type:
+		paramToSyslogSD(stringsBuilder, name, strconv.FormatFloat(receiver.Value.(float64), 'f', -1, sixtyFour))
+	case Float64sType:
+		sliceToSyslogSD(stringsBuilder, name, receiver.Value.([]float64))
+	case StringType:
+		paramToSyslogSD(stringsBuilder, name, receiver.Value.(string))
+	case StringsType:
+		sliceToSyslogSD(stringsBuilder, name, receiver.Value.([]string))
+	case IPType:
+		paramToSyslogSD(stringsBuilder, name, ipToString(receiver.Value.(net.IP)))
+	case URLType:
+		paramToSyslogSD(stringsBuilder, name, urlToString(receiver.Value.(*url.URL)))
+	case JSONRawType:
+		paramToSyslogSD(stringsBuilder, name, jsonRawToString(receiver.Value.(json.RawMessage)))
+	case LogValuerType:
+		paramToSyslogSD(stringsBuilder, name, stringerToString(receiver.Value.(fmt.Stringer)))
+	case RuneType:
+		paramToSyslogSD(stringsBuilder, name, runeToString(receiver.Value.(rune)))
+	case Complex128Type:
+		paramToSyslogSD(stringsBuilder, name, complexToString(receiver.Value.(complex128)))
+	default:
+		paramToSyslogSD(stringsBuilder, name, fmt.Sprintf(verboseFormat, receiver.Value))
+	}
+}
+
+// paramToSyslogSD writes a name="value" SD-PARAM to the provided strings.Builder,
+// preceded by a space.
+//
+// Parameters:
+//
+//	stringsBuilder - the strings.Builder to write to
+//	name - the name of the param, sanitized and truncated to 32 characters
+//	value - the value of the param, with '"', '\' and ']' escaped
+func paramToSyslogSD(stringsBuilder *strings.Builder, name, value string) {
+	name = strings.Map(nameRuneToSyslogSD, name)
+	if len(name) > syslogMaxParamNameLen {
+		name = name[:syslogMaxParamNameLen]
+	}
+
+	stringsBuilder.WriteString(space)
+	stringsBuilder.WriteString(cmpOr(name, "_"))
+	stringsBuilder.WriteString(equals)
+	stringsBuilder.WriteString(quote)
+
+	for _, r := range value {
+		if r == '"' || r == '\\' || r == ']' {
+			stringsBuilder.WriteRune('\\')
+		}
+
+		stringsBuilder.WriteRune(r)
+	}
+
+	stringsBuilder.WriteString(quote)
+}
+
+// nameRuneToSyslogSD replaces the runes that are not allowed in an SD-NAME by an underscore.
+// An SD-NAME only allows printable US-ASCII characters other than '=', ' ', ']' and '"'.
+func nameRuneToSyslogSD(r rune) rune {
+	if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+		return '_'
+	}
+
+	return r
+}
+
+// errorToSyslogSD writes the params of the given error, with names prefixed with the given prefix,
+// to the provided strings.Builder.
+//
+// If the error is nil, it writes a single param with the name "message" and the value nilValue.
+// If the error is a StructuredError, it writes the same params as the StructuredError.
+// If the error is not a StructuredError, it writes a single param with the name "message"
+// and the value of the error's Error() method.
+func errorToSyslogSD(stringsBuilder *strings.Builder, prefix string, err error) {
+	var value *StructuredError
+	switch {
+	case err == nil:
+		paramToSyslogSD(stringsBuilder, prefix+messageKey, nilValue)
+	case stderrors.As(err, &value):
+		value.asSyslogSD(stringsBuilder, prefix)
+	default:
+		errStr := strings.TrimSpace(err.Error())
+		paramToSyslogSD(stringsBuilder, prefix+messageKey, cmpOr(errStr, nilValue))
+	}
+}
+
+// sliceToSyslogSD writes one param per item of the given slice, all with the given name,
+// to the provided strings.Builder.
+func sliceToSyslogSD[T any](stringsBuilder *strings.Builder, name string, slice []T) {
+	switch values := any(slice).(type) {
+	case []bool:
+		for _, value := range values {
+			paramToSyslogSD(stringsBuilder, name, strconv.FormatBool(value))
+		}
+	case []time.Time:
+		for _, value := range values {
+			paramToSyslogSD(stringsBuilder, name, value.Format(time.RFC3339Nano))
+		}
+	case []time.Duration:
+		for _, value := range values {
+			paramToSyslogSD(stringsBuilder, name, value.String())
+		}
+	case []int:
+		for _, value := range values {
+			paramToSyslogSD(stringsBuilder, name, strconv.Itoa(value))
+		}
+	case []int64:
+		for _, value := range values {
+			paramToSyslogSD(stringsBuilder, name, strconv.FormatInt(value, ten))
+		}
+	case []uint64:
+		for _, value := range values {
+			paramToSyslogSD(stringsBuilder, name, strconv.FormatUint(value, ten))
+		}
+	case []float64:
+		for _, value := range values {
+			paramToSyslogSD(stringsBuilder, name, strconv.FormatFloat(value, 'f', -1, sixtyFour))
+		}
+	case []string:
+		for _, value := range values {
+			paramToSyslogSD(stringsBuilder, name, strings.TrimSpace(value))
+		}
+	default:
+		for _, value := range slice {
+			paramToSyslogSD(stringsBuilder, name, fmt.Sprintf(verboseFormat, value))
+		}
+	}
+}
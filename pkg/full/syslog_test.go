@@ -0,0 +1,146 @@
+package errors
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructuredErrorMarshalSyslogSD(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		want string
+	}{
+		{
+			name: "given_nil_error_when_marshal_syslog_sd_then_returns_nil_message",
+			err:  nil,
+			want: `[error@32473 message="!NILVALUE"]`,
+		},
+		{
+			name: "given_error_with_code_when_marshal_syslog_sd_then_returns_code_param",
+			err:  New("failed").WithCode("NOT_FOUND"),
+			want: `[error@32473 message="failed" code="NOT_FOUND"]`,
+		},
+		{
+			name: "given_error_with_special_characters_when_marshal_syslog_sd_then_escapes_them",
+			err:  New(`bad "value" in C:\tmp [x]`),
+			want: `[error@32473 message="bad \"value\" in C:\\tmp [x\]"]`,
+		},
+		{
+			name: "given_error_with_tags_when_marshal_syslog_sd_then_repeats_tag_param",
+			err:  New("test").WithTags("a", "b"),
+			want: `[error@32473 message="test" tag="a" tag="b"]`,
+		},
+		{
+			name: "given_error_with_attrs_when_marshal_syslog_sd_then_returns_params_keyed_by_attr_key",
+			err: New("test").WithAttrs(
+				String("request_id", "123"),
+				Ints("ids", 1, 2),
+				Object("user", String("name", "john"), Bool("admin", false)),
+				Duration("elapsed", time.Second),
+			),
+			want: `[error@32473 message="test" request_id="123" ids="1" ids="2" ` +
+				`user.name="john" user.admin="false" elapsed="1s"]`,
+		},
+		{
+			name: "given_error_with_invalid_param_names_when_marshal_syslog_sd_then_sanitizes_them",
+			err: New("test").WithAttrs(
+				String(`a b=c]"d`, "value"),
+				String("abcdefghijklmnopqrstuvwxyz0123456789", "long"),
+			),
+			want: `[error@32473 message="test" a_b_c__d="value" abcdefghijklmnopqrstuvwxyz012345="long"]`,
+		},
+		{
+			name: "given_error_with_nested_errors_when_marshal_syslog_sd_then_returns_indexed_prefixes",
+			err: New("parent").WithErrors(
+				stderrors.New("child error"),
+				New("structured").WithTags("inner"),
+				nil,
+			),
+			want: `[error@32473 message="parent" errors.0.message="child error" errors.1.message="structured" ` +
+				`errors.1.tag="inner" errors.2.message="!NILVALUE"]`,
+		},
+		{
+			name: "given_error_with_sensitive_attr_when_marshal_syslog_sd_then_redacts_it",
+			err:  New("test").WithAttrs(Sensitive("password", "secret")),
+			want: `[error@32473 message="test" password="[REDACTED\]"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.MarshalSyslogSD()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestSetSyslogEnterpriseNumber(t *testing.T) { //nolint:paralleltest // SetSyslogEnterpriseNumber is not thread-safe
+	t.Cleanup(
+		func() {
+			SetSyslogEnterpriseNumber(32473)
+		},
+	)
+
+	// given
+	err := New("test")
+
+	// when
+	SetSyslogEnterpriseNumber(12345)
+
+	// then
+	assert.Equal(t, "12345", SyslogEnterpriseNumber())
+	assert.Equal(t, `[error@12345 message="test"]`, err.MarshalSyslogSD())
+}
+
+func TestStructuredErrorMarshalSyslogSDWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	var got string
+
+	assert.NotPanics(t, func() { got = err.MarshalSyslogSD() })
+
+	// then
+	assert.Equal(t, `[error@32473 message="test" count="not an int" user="john"]`, got)
+}
+
+func TestStructuredErrorMarshalSyslogSDWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var err *StructuredError
+
+	// when
+	got := err.MarshalSyslogSD()
+
+	// then
+	assert.Equal(t, `[error@32473 message="NULL"]`, got)
+}
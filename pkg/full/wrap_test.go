@@ -1,9 +1,11 @@
 package errors
 
 import (
+	"context"
 	stderrors "errors"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -971,6 +973,79 @@ func TestStructuredErrorIs(t *testing.T) {
 	}
 }
 
+func TestStructuredErrorIsWithCode(t *testing.T) {
+	t.Parallel()
+
+	sentinel := New("sentinel")
+
+	tests := []struct {
+		name string
+		// given
+		err    error
+		target error
+		// then
+		want bool
+	}{
+		{
+			name:   "given_same_code_when_is_then_returns_true",
+			err:    New("user 7 not found").WithCode("NOT_FOUND").WithAttrs(Int("id", 7)),
+			target: New("").WithCode("NOT_FOUND"),
+			want:   true,
+		},
+		{
+			name:   "given_different_code_when_is_then_returns_false",
+			err:    New("user 7 not found").WithCode("NOT_FOUND"),
+			target: New("").WithCode("FORBIDDEN"),
+			want:   false,
+		},
+		{
+			name:   "given_same_message_without_code_when_is_then_returns_false",
+			err:    New("not found"),
+			target: New("not found"),
+			want:   false,
+		},
+		{
+			name:   "given_target_without_code_when_is_then_matches_by_identity",
+			err:    sentinel,
+			target: sentinel,
+			want:   true,
+		},
+		{
+			name:   "given_nested_error_with_code_when_is_then_returns_true",
+			err:    New("outer").WithErrors(stderrors.New("std"), New("inner").WithCode("NOT_FOUND")),
+			target: New("").WithCode("NOT_FOUND"),
+			want:   true,
+		},
+		{
+			name:   "given_nested_sentinel_when_is_then_matches_by_identity",
+			err:    New("outer").WithCode("INTERNAL").WithErrors(sentinel),
+			target: sentinel,
+			want:   true,
+		},
+		{
+			name:   "given_non_structured_target_when_is_then_returns_false",
+			err:    New("test").WithCode("NOT_FOUND"),
+			target: stderrors.New("test"),
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := stderrors.Is(test.err, test.target)
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
 func TestStructuredErrorIsWithCustomErrors(t *testing.T) {
 	targetErr := stderrors.New("target")
 	customErr := &customErrorWithIs{msg: "custom", target: targetErr}
@@ -1064,6 +1139,92 @@ func TestStructuredErrorIsWithSentinelErrors(t *testing.T) {
 	}
 }
 
+func TestIsWithSentinelErrorsInErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		target error
+		err    error
+		name   string
+		want   bool
+	}{
+		{
+			name:   "given_errors_with_eof_when_is_with_eof_then_returns_true",
+			err:    New("wrapper").WithErrors(io.EOF),
+			target: io.EOF,
+			want:   true,
+		},
+		{
+			name:   "given_eof_after_other_errors_when_is_with_eof_then_returns_true",
+			err:    New("wrapper").WithErrors(io.ErrUnexpectedEOF, io.ErrClosedPipe, io.EOF),
+			target: io.EOF,
+			want:   true,
+		},
+		{
+			name:   "given_eof_in_nested_errors_when_is_with_eof_then_returns_true",
+			err:    New("outer").WithErrors(New("middle").WithErrors(New("inner").WithErrors(io.EOF))),
+			target: io.EOF,
+			want:   true,
+		},
+		{
+			name:   "given_joined_eof_when_is_with_eof_then_returns_true",
+			err:    Join(io.ErrUnexpectedEOF, io.EOF),
+			target: io.EOF,
+			want:   true,
+		},
+		{
+			name:   "given_eof_wrapped_by_fmt_in_errors_when_is_with_eof_then_returns_true",
+			err:    New("wrapper").WithErrors(fmt.Errorf("read failed: %w", io.EOF)),
+			target: io.EOF,
+			want:   true,
+		},
+		{
+			name:   "given_errors_without_eof_when_is_with_eof_then_returns_false",
+			err:    New("wrapper").WithErrors(io.ErrUnexpectedEOF, stderrors.New("EOF")),
+			target: io.EOF,
+			want:   false,
+		},
+		{
+			name:   "given_no_errors_when_is_with_eof_then_returns_false",
+			err:    New("wrapper"),
+			target: io.EOF,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := Is(test.err, test.target)
+				gotStd := stderrors.Is(test.err, test.target)
+
+				// then
+				assert.Equal(t, test.want, got)
+				assert.Equal(t, test.want, gotStd)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorUnwrapWithoutJoin(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("wrapper").WithErrors(io.EOF, io.ErrUnexpectedEOF)
+
+	// when
+	unwrapper, ok := error(err).(MultiUnwrapper) //nolint:errorlint // the method set is checked
+
+	// then
+	require.True(t, ok)
+	assert.False(t, err.joined)
+	assert.Equal(t, []error{io.EOF, io.ErrUnexpectedEOF}, unwrapper.Unwrap())
+}
+
 func TestStructuredErrorAs(t *testing.T) {
 	customErr := &customError{msg: "custom error"}
 
@@ -1416,3 +1577,921 @@ func TestStructuredErrorIsAsIntegration(t *testing.T) {
 		)
 	}
 }
+
+func TestFindByTag(t *testing.T) {
+	t.Parallel()
+
+	retryable := New("timeout").WithTags("network", "retryable")
+
+	tests := []struct {
+		name string
+		// given
+		err error
+		tag string
+		// then
+		want   *StructuredError
+		wantOk bool
+	}{
+		{
+			name:   "given_nil_error_when_find_by_tag_then_returns_not_found",
+			err:    nil,
+			tag:    "retryable",
+			want:   nil,
+			wantOk: false,
+		},
+		{
+			name:   "given_tagged_error_when_find_by_tag_then_returns_itself",
+			err:    retryable,
+			tag:    "retryable",
+			want:   retryable,
+			wantOk: true,
+		},
+		{
+			name:   "given_error_without_tag_when_find_by_tag_then_returns_not_found",
+			err:    New("test").WithTags("other").WithErrors(stderrors.New("child")),
+			tag:    "retryable",
+			want:   nil,
+			wantOk: false,
+		},
+		{
+			name:   "given_non_structured_error_when_find_by_tag_then_returns_not_found",
+			err:    stderrors.New("retryable"),
+			tag:    "retryable",
+			want:   nil,
+			wantOk: false,
+		},
+		{
+			name: "given_nested_joins_when_find_by_tag_then_returns_error_at_depth_two",
+			err: Join(
+				stderrors.New("first"),
+				Join(New("permanent").WithTags("fatal"), retryable),
+			),
+			tag:    "retryable",
+			want:   retryable,
+			wantOk: true,
+		},
+		{
+			name: "given_multi_unwrappers_when_find_by_tag_then_traverses_unwrap_slice",
+			err: multiUnwrapper{errs: []error{
+				stderrors.New("first"),
+				multiUnwrapper{errs: []error{stderrors.New("second"), retryable}},
+			}},
+			tag:    "retryable",
+			want:   retryable,
+			wantOk: true,
+		},
+		{
+			name:   "given_wrapped_error_when_find_by_tag_then_traverses_unwrap",
+			err:    singleUnwrapper{err: New("parent").WithErrors(fmt.Errorf("inner: %w", retryable))},
+			tag:    "retryable",
+			want:   retryable,
+			wantOk: true,
+		},
+		{
+			name: "given_several_tagged_errors_when_find_by_tag_then_returns_first_depth_first",
+			err: New("parent").WithErrors(
+				New("child").WithErrors(retryable),
+				New("sibling").WithTags("retryable"),
+			),
+			tag:    "retryable",
+			want:   retryable,
+			wantOk: true,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got, ok := FindByTag(test.err, test.tag)
+
+				// then
+				assert.Equal(t, test.wantOk, ok)
+				assert.Same(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestWrapf(t *testing.T) {
+	t.Parallel()
+
+	cause := stderrors.New("file not found")
+
+	tests := []struct {
+		name string
+		// given
+		err    error
+		format string
+		args   []any
+		// then
+		want error
+	}{
+		{
+			name:   "given_nil_error_when_wrapf_then_returns_nil",
+			err:    nil,
+			format: "loading %s",
+			args:   []any{"config.yaml"},
+			want:   nil,
+		},
+		{
+			name:   "given_error_when_wrapf_then_wraps_with_formatted_message",
+			err:    cause,
+			format: "loading %s",
+			args:   []any{"config.yaml"},
+			want:   New("loading config.yaml").WithErrors(cause),
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := Wrapf(test.err, test.format, test.args...)
+
+				// then
+				assert.Equal(t, test.want, got)
+
+				if test.err != nil {
+					assert.ErrorIs(t, got, test.err)
+				} else {
+					assert.NoError(t, got)
+				}
+			},
+		)
+	}
+}
+
+func TestClassifyContext(t *testing.T) {
+	t.Parallel()
+
+	deadlineExceeded := fmt.Errorf("fetching user: %w", context.DeadlineExceeded)
+	unrelated := stderrors.New("connection refused")
+
+	tests := []struct {
+		name string
+		// given
+		err error
+		// then
+		want        *StructuredError
+		wantMessage string
+		wantJSON    string
+	}{
+		{
+			name: "given_nil_error_when_classify_context_then_returns_nil",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name:        "given_deadline_exceeded_error_when_classify_context_then_tagged_timeout",
+			err:         deadlineExceeded,
+			want:        New("classified error").WithErrors(deadlineExceeded).WithTags("timeout"),
+			wantMessage: "context deadline exceeded",
+			wantJSON: `{"message":"classified error","tags":["timeout"],` +
+				`"errors":[{"message":"context deadline exceeded"}]}`,
+		},
+		{
+			name:        "given_canceled_error_when_classify_context_then_tagged_canceled",
+			err:         context.Canceled,
+			want:        New("classified error").WithErrors(context.Canceled).WithTags("canceled"),
+			wantMessage: "context canceled",
+			wantJSON: `{"message":"classified error","tags":["canceled"],` +
+				`"errors":[{"message":"context canceled"}]}`,
+		},
+		{
+			name:        "given_unrelated_error_when_classify_context_then_wrapped_without_tags",
+			err:         unrelated,
+			want:        New("classified error").WithErrors(unrelated),
+			wantMessage: "connection refused",
+			wantJSON:    `{"message":"classified error","errors":[{"message":"connection refused"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := ClassifyContext(test.err)
+
+				// then
+				assert.Equal(t, test.want, got)
+
+				if test.err == nil {
+					return
+				}
+
+				assert.ErrorIs(t, got, test.err)
+				assert.Equal(t, 1, strings.Count(got.Error(), test.wantMessage))
+
+				gotJSON, err := got.MarshalJSON()
+				require.NoError(t, err)
+				assert.JSONEq(t, test.wantJSON, string(gotJSON))
+			},
+		)
+	}
+}
+
+func TestClassifyContextWithExpiredContext(t *testing.T) {
+	t.Parallel()
+
+	// given
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	<-ctx.Done()
+
+	// when
+	got := ClassifyContext(ctx.Err())
+
+	// then
+	assert.Equal(t, []string{"timeout"}, got.Tags)
+	assert.ErrorIs(t, got, context.DeadlineExceeded)
+}
+
+func TestStructuredErrorCauseAt(t *testing.T) {
+	t.Parallel()
+
+	errA := stderrors.New("a")
+	errB := stderrors.New("b")
+	errC := stderrors.New("c")
+
+	tests := []struct {
+		name string
+		// given
+		err   *StructuredError
+		index int
+		// then
+		want      error
+		wantCount int
+	}{
+		{
+			name:      "given_nil_error_when_cause_at_then_returns_nil",
+			err:       nil,
+			index:     0,
+			want:      nil,
+			wantCount: 0,
+		},
+		{
+			name:      "given_error_without_children_when_cause_at_then_returns_nil",
+			err:       New("test"),
+			index:     0,
+			want:      nil,
+			wantCount: 0,
+		},
+		{
+			name:      "given_non_joined_error_when_cause_at_with_valid_index_then_returns_child",
+			err:       New("parent").WithErrors(errA, errB),
+			index:     1,
+			want:      errB,
+			wantCount: 2,
+		},
+		{
+			name:      "given_non_joined_error_when_cause_at_with_negative_index_then_returns_nil",
+			err:       New("parent").WithErrors(errA, errB),
+			index:     -1,
+			want:      nil,
+			wantCount: 2,
+		},
+		{
+			name:      "given_non_joined_error_when_cause_at_with_out_of_range_index_then_returns_nil",
+			err:       New("parent").WithErrors(errA, errB),
+			index:     2,
+			want:      nil,
+			wantCount: 2,
+		},
+		{
+			name:      "given_joined_error_when_cause_at_with_valid_index_then_returns_flattened_child",
+			err:       New("parent").WithErrors(Join(errA, Join(errB, errC))),
+			index:     2,
+			want:      errC,
+			wantCount: 3,
+		},
+		{
+			name:      "given_joined_error_when_cause_at_with_negative_index_then_returns_nil",
+			err:       New("parent").WithErrors(Join(errA, errB)),
+			index:     -1,
+			want:      nil,
+			wantCount: 2,
+		},
+		{
+			name:      "given_joined_error_when_cause_at_with_out_of_range_index_then_returns_nil",
+			err:       New("parent").WithErrors(Join(errA, errB)),
+			index:     5,
+			want:      nil,
+			wantCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.CauseAt(test.index)
+				gotCount := test.err.CauseCount()
+
+				// then
+				assert.Equal(t, test.want, got)
+				assert.Equal(t, test.wantCount, gotCount)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorRootCause(t *testing.T) {
+	t.Parallel()
+
+	leaf := New("leaf")
+	sentinel := stderrors.New("sentinel")
+	errA := stderrors.New("a")
+	errB := stderrors.New("b")
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		want error
+	}{
+		{
+			name: "given_nil_error_when_root_cause_then_returns_nil",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "given_leaf_error_when_root_cause_then_returns_itself",
+			err:  leaf,
+			want: leaf,
+		},
+		{
+			name: "given_three_level_chain_when_root_cause_then_returns_deepest_error",
+			err:  New("top").WithErrors(New("middle").WithErrors(leaf)),
+			want: leaf,
+		},
+		{
+			name: "given_chain_ending_in_std_error_when_root_cause_then_returns_std_error",
+			err:  New("top").WithErrors(New("middle").WithErrors(sentinel)),
+			want: sentinel,
+		},
+		{
+			name: "given_fmt_wrapped_error_when_root_cause_then_unwraps_it",
+			err:  New("top").WithErrors(fmt.Errorf("read: %w", fmt.Errorf("open: %w", io.EOF))),
+			want: io.EOF,
+		},
+		{
+			name: "given_joined_error_when_root_cause_then_follows_first_child",
+			err:  New("top").WithErrors(Join(New("first").WithErrors(errA), errB)),
+			want: errA,
+		},
+		{
+			name: "given_nil_first_children_when_root_cause_then_skips_them",
+			err:  New("top").WithErrors(nil, (*StructuredError)(nil), errB),
+			want: errB,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.RootCause()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorRootCauseMaxDepth(t *testing.T) { //nolint:paralleltest // SetMaxDepthMarshal is not thread-safe
+	// given
+	SetMaxDepthMarshal(2)
+	t.Cleanup(func() { SetMaxDepthMarshal(100) })
+
+	middle := New("middle").WithErrors(New("leaf"))
+
+	// when
+	got := New("top").WithErrors(New("upper").WithErrors(middle)).RootCause()
+
+	// then
+	assert.Equal(t, middle, got)
+}
+
+func TestStructuredErrorCauser(t *testing.T) {
+	t.Parallel()
+
+	var _ interface{ Cause() error } = (*StructuredError)(nil)
+
+	leaf := New("leaf")
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		want error
+	}{
+		{
+			name: "given_nil_error_when_cause_then_returns_nil",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "given_leaf_error_when_cause_then_returns_nil",
+			err:  New("leaf"),
+			want: nil,
+		},
+		{
+			name: "given_three_level_chain_when_cause_then_returns_deepest_error",
+			err:  New("top").WithErrors(New("middle").WithErrors(leaf)),
+			want: leaf,
+		},
+		{
+			name: "given_fmt_wrapped_error_when_cause_then_unwraps_it",
+			err:  New("top").WithErrors(fmt.Errorf("read: %w", io.EOF)),
+			want: io.EOF,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.Cause()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorCauserChain(t *testing.T) {
+	t.Parallel()
+
+	type causer interface {
+		Cause() error
+	}
+
+	// given
+	leaf := New("leaf")
+	var err error = New("top").WithErrors(New("middle").WithErrors(leaf))
+
+	// when: walking the causes like github.com/pkg/errors and Sentry do
+	var got []error
+
+	for steps := 0; err != nil && steps < 10; steps++ {
+		got = append(got, err)
+
+		cause, ok := err.(causer) //nolint:errorlint // the chain is walked manually
+		if !ok {
+			break
+		}
+
+		err = cause.Cause()
+	}
+
+	// then: the walk should stop right after the root cause
+	require.Len(t, got, 2)
+	assert.Equal(t, leaf, got[1])
+}
+
+func TestAsTagged(t *testing.T) {
+	t.Parallel()
+
+	retryable := New("timeout").WithTags("retryable")
+
+	tests := []struct {
+		name string
+		// given
+		err error
+		tag string
+		// then
+		want   *StructuredError
+		wantOk bool
+	}{
+		{
+			name:   "given_tagged_error_under_fmt_errorf_when_as_tagged_then_sets_target",
+			err:    fmt.Errorf("calling service: %w", retryable),
+			tag:    "retryable",
+			want:   retryable,
+			wantOk: true,
+		},
+		{
+			name:   "given_tagged_error_under_join_when_as_tagged_then_sets_target",
+			err:    Join(New("permanent").WithTags("fatal"), fmt.Errorf("wrapped: %w", retryable)),
+			tag:    "retryable",
+			want:   retryable,
+			wantOk: true,
+		},
+		{
+			name:   "given_error_without_tag_when_as_tagged_then_keeps_target",
+			err:    Join(New("permanent").WithTags("fatal"), stderrors.New("std")),
+			tag:    "retryable",
+			want:   nil,
+			wantOk: false,
+		},
+		{
+			name:   "given_nil_error_when_as_tagged_then_keeps_target",
+			err:    nil,
+			tag:    "retryable",
+			want:   nil,
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				var target *StructuredError
+
+				// when
+				got := AsTagged(test.err, test.tag, &target)
+
+				// then
+				assert.Equal(t, test.wantOk, got)
+				assert.Same(t, test.want, target)
+			},
+		)
+	}
+
+	t.Run(
+		"given_nil_target_when_as_tagged_then_panics", func(t *testing.T) {
+			t.Parallel()
+
+			// then
+			assert.Panics(
+				t, func() {
+					// when
+					AsTagged(retryable, "retryable", nil)
+				},
+			)
+		},
+	)
+}
+
+func TestStructuredErrorDepth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		want int
+	}{
+		{
+			name: "given_nil_error_when_depth_then_returns_zero",
+			err:  nil,
+			want: 0,
+		},
+		{
+			name: "given_leaf_error_when_depth_then_returns_one",
+			err:  New("leaf"),
+			want: 1,
+		},
+		{
+			name: "given_non_structured_children_when_depth_then_counts_them_as_one",
+			err:  New("parent").WithErrors(stderrors.New("child"), nil),
+			want: 2,
+		},
+		{
+			name: "given_two_level_tree_when_depth_then_returns_two",
+			err:  New("parent").WithErrors(New("child1"), New("child2")),
+			want: 2,
+		},
+		{
+			name: "given_asymmetric_tree_when_depth_then_returns_maximum_branch_depth",
+			err: New("root").WithErrors(
+				New("shallow"),
+				New("deep").WithErrors(
+					stderrors.New("leaf"),
+					New("deeper").WithErrors(New("deepest")),
+				),
+				stderrors.New("std"),
+			),
+			want: 4,
+		},
+		{
+			name: "given_wrapped_structured_child_when_depth_then_counts_it_as_one",
+			err: New("parent").WithErrors(
+				fmt.Errorf("wrapped: %w", New("child").WithErrors(New("grandchild").WithErrors(io.EOF))),
+			),
+			want: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.Depth()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorLeafCount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		want int
+	}{
+		{
+			name: "given_nil_error_when_leaf_count_then_returns_zero",
+			err:  nil,
+			want: 0,
+		},
+		{
+			name: "given_leaf_error_when_leaf_count_then_returns_one",
+			err:  New("leaf"),
+			want: 1,
+		},
+		{
+			name: "given_non_structured_children_when_leaf_count_then_skips_nil_errors",
+			err:  New("parent").WithErrors(stderrors.New("child1"), nil, stderrors.New("child2")),
+			want: 2,
+		},
+		{
+			name: "given_only_nil_children_when_leaf_count_then_returns_zero",
+			err:  New("parent").WithErrors(nil),
+			want: 0,
+		},
+		{
+			name: "given_mixed_tree_when_leaf_count_then_counts_terminal_errors",
+			err: New("root").WithErrors(
+				New("shallow"),
+				New("deep").WithErrors(
+					stderrors.New("leaf"),
+					New("deeper").WithErrors(New("deepest1"), io.EOF),
+				),
+				stderrors.New("std"),
+			),
+			want: 5,
+		},
+		{
+			name: "given_wrapped_structured_child_when_leaf_count_then_counts_it_as_one",
+			err: New("parent").WithErrors(
+				fmt.Errorf("wrapped: %w", New("child").WithErrors(io.EOF, io.ErrUnexpectedEOF)),
+				stderrors.New("std"),
+			),
+			want: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := test.err.LeafCount()
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestIsMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err     error
+		message string
+		// then
+		want bool
+	}{
+		{
+			name:    "given_nil_error_when_is_message_then_returns_false",
+			err:     nil,
+			message: "not found",
+			want:    false,
+		},
+		{
+			name:    "given_top_level_std_error_when_is_message_then_returns_true",
+			err:     stderrors.New(" not found\n"),
+			message: "not found",
+			want:    true,
+		},
+		{
+			name:    "given_top_level_structured_error_when_is_message_then_matches_message_field",
+			err:     New("not found").WithTags("tag"),
+			message: " not found ",
+			want:    true,
+		},
+		{
+			name: "given_message_nested_two_levels_when_is_message_then_returns_true",
+			err: New("root").WithErrors(
+				New("other"),
+				fmt.Errorf("wrapped: %w", New("middle").WithErrors(stderrors.New("legacy sentinel"))),
+			),
+			message: "legacy sentinel",
+			want:    true,
+		},
+		{
+			name:    "given_fmt_errorf_when_is_message_then_matches_wrapped_message",
+			err:     fmt.Errorf("wrapped: %w", io.EOF),
+			message: "EOF",
+			want:    true,
+		},
+		{
+			name:    "given_other_messages_when_is_message_then_returns_false",
+			err:     Join(New("first"), stderrors.New("second")),
+			message: "third",
+			want:    false,
+		},
+		{
+			name:    "given_partial_message_when_is_message_then_returns_false",
+			err:     stderrors.New("not found: user"),
+			message: "not found",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := IsMessage(test.err, test.message)
+
+				// then
+				assert.Equal(t, test.want, got)
+			},
+		)
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err error
+		// then
+		want   int
+		wantOk bool
+	}{
+		{
+			name:   "given_nil_error_when_http_status_then_returns_false",
+			err:    nil,
+			want:   0,
+			wantOk: false,
+		},
+		{
+			name:   "given_status_at_the_top_when_http_status_then_returns_it_over_children",
+			err:    New("root").WithHTTPStatus(400).WithErrors(New("child").WithHTTPStatus(500)),
+			want:   400,
+			wantOk: true,
+		},
+		{
+			name: "given_status_only_on_nested_child_when_http_status_then_returns_it",
+			err: New("root").WithErrors(
+				New("first"),
+				fmt.Errorf("wrapped: %w", New("second").WithErrors(New("deep").WithHTTPStatus(404))),
+				New("third").WithHTTPStatus(409),
+			),
+			want:   404,
+			wantOk: true,
+		},
+		{
+			name:   "given_no_status_when_http_status_then_returns_false",
+			err:    New("root").WithErrors(New("child"), io.EOF),
+			want:   0,
+			wantOk: false,
+		},
+		{
+			name:   "given_std_error_when_http_status_then_returns_false",
+			err:    stderrors.New("plain"),
+			want:   0,
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got, ok := HTTPStatus(test.err)
+
+				// then
+				assert.Equal(t, test.want, got)
+				assert.Equal(t, test.wantOk, ok)
+			},
+		)
+	}
+}
+
+func TestStructure(t *testing.T) {
+	t.Parallel()
+
+	structured := New("structured").WithTags("tag")
+	std := stderrors.New("std error")
+
+	tests := []struct {
+		name string
+		// given
+		err error
+		// then
+		want *StructuredError
+	}{
+		{
+			name: "given_nil_error_when_structure_then_returns_nil",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "given_structured_error_when_structure_then_returns_it",
+			err:  structured,
+			want: structured,
+		},
+		{
+			name: "given_std_error_when_structure_then_returns_error_with_message",
+			err:  std,
+			want: New("std error"),
+		},
+		{
+			name: "given_fmt_errorf_chain_when_structure_then_returns_error_with_message",
+			err:  fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", std)),
+			want: New("outer: inner: std error"),
+		},
+		{
+			name: "given_fmt_errorf_wrapping_structured_error_when_structure_then_returns_it",
+			err:  fmt.Errorf("outer: %w", structured),
+			want: structured,
+		},
+		{
+			name: "given_std_joined_errors_when_structure_then_returns_joined_error",
+			err:  multiUnwrapper{errs: []error{std, nil, structured, multiUnwrapper{errs: []error{io.EOF}}}},
+			want: &StructuredError{
+				joined: true,
+				Errors: []error{
+					New("std error"),
+					structured,
+					&StructuredError{joined: true, Errors: []error{New("EOF")}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// when
+				got := Structure(test.err)
+
+				// then
+				assert.Equal(t, test.want, got)
+
+				if test.want == structured {
+					assert.Same(t, structured, got)
+				}
+			},
+		)
+	}
+}
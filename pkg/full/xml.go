@@ -0,0 +1,352 @@
+package errors
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMarshalXML is returned when marshaling to XML fails.
+	ErrMarshalXML = New("failed to marshal XML")
+)
+
+// MarshalXML implements xml.Marshaler.
+//
+// It marshals the StructuredError into the given xml.Encoder as an <error> element,
+// regardless of the name of the given xml.StartElement.
+//
+// If the receiver is nil, it adds a single <message> element with the value nilValue.
+//
+// Otherwise, it will have the following elements:
+//   - Message
+//   - Code, if not empty
+//   - HTTPStatus, if not zero
+//   - Tags
+//   - Attrs
+//   - Errors
+//   - Stack (base64 encoded).
+//
+// Usage must be with xml.Marshal or xml.Encoder.Encode.
+func (receiver *StructuredError) MarshalXML(encoder *xml.Encoder, _ xml.StartElement) error {
+	start := startXML(errorKey)
+
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return JoinIf(err, ErrMarshalXML)
+	}
+
+	err = receiver.asXML(encoder)
+	if err != nil {
+		return err
+	}
+
+	return JoinIf(encoder.EncodeToken(start.End()), ErrMarshalXML)
+}
+
+// asXML is the actual implementation for MarshalXML.
+// It writes the children of the <error> element to the given xml.Encoder.
+func (receiver *StructuredError) asXML(encoder *xml.Encoder) error {
+	if receiver == nil {
+		return valueToXML(encoder, startXML(messageKey), nilValue)
+	}
+
+	err := valueToXML(encoder, startXML(messageKey), cmpOr(trimmedMessage(receiver.Message), nilValue))
+	if err != nil {
+		return err
+	}
+
+	if receiver.Code != emptyString {
+		err = valueToXML(encoder, startXML(codeKey), receiver.Code)
+		if err != nil {
+			return err
+		}
+	}
+
+	if receiver.HTTPStatus != zero {
+		err = valueToXML(encoder, startXML(httpStatusKey), strconv.Itoa(receiver.HTTPStatus))
+		if err != nil {
+			return err
+		}
+	}
+
+	if keepField(len(receiver.Tags)) {
+		err = sliceToXML(encoder, startXML(tagsKey), tagKey, receiver.Tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	if attrs := withoutOmittedAttrs(receiver.Attrs); keepField(len(attrs)) {
+		err = sliceToXML(encoder, startXML(attrsKey), attrKey, attrs)
+		if err != nil {
+			return err
+		}
+	}
+
+	if keepField(len(receiver.Errors)) {
+		normalized := receiver.normalizedErrors()
+
+		err = sliceToXML(encoder, startXML(errorsKey), errorKey, normalized)
+		if err != nil {
+			return err
+		}
+	}
+
+	if keepField(len(receiver.Stack)) {
+		encoded := base64.StdEncoding.EncodeToString(receiver.Stack)
+
+		err = valueToXML(encoder, startXML(stackKey), encoded)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MarshalXML implements xml.Marshaler.
+//
+// It marshals the Attr into the given xml.Encoder as an <attr key="..."> element,
+// regardless of the name of the given xml.StartElement.
+//
+// If the receiver is nil, the element will have the key nilValue and the value nilValue.
+//
+// Scalar values are written as the element's character data, slices are written as
+// nested <value> elements and objects are written as nested <attr> elements.
+//
+// Usage must be with xml.Marshal or xml.Encoder.Encode.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) MarshalXML(encoder *xml.Encoder, _ xml.StartElement) error {
+	if receiver == nil {
+		return valueToXML(encoder, attrStartXML(nilValue), nilValue)
+	}
+
+	receiver = receiver.redacted()
+
+	start := attrStartXML(receiver.Key)
+
+	switch receiver.Type {
+	case AnyType:
+		return valueToXML(encoder, start, fmt.Sprintf(verboseFormat, receiver.Value))
+	case ObjectType:
+		return sliceToXML(encoder, start, attrKey, receiver.Value.([]Attr))
+	case BoolType:
+		return valueToXML(encoder, start, strconv.FormatBool(receiver.Value.(bool)))
+	case BoolsType:
+		return sliceToXML(encoder, start, valueKey, receiver.Value.([]bool))
+	case TimeType:
+		return valueToXML(encoder, start, receiver.Value.(time.Time).Format(time.RFC3339Nano))
+	case TimesType:
+		return sliceToXML(encoder, start, valueKey, receiver.Value.([]time.Time))
+	case DurationType:
+		return valueToXML(encoder, start, receiver.Value.(time.Duration).String())
+	case DurationsType:
+		return sliceToXML(encoder, start, valueKey, receiver.Value.([]time.Duration))
+	case IntType:
+		return valueToXML(encoder, start, strconv.Itoa(receiver.Value.(int)))
+	case IntsType:
+		return sliceToXML(encoder, start, valueKey, receiver.Value.([]int))
+	case Int64Type:
+		return valueToXML(encoder, start, strconv.FormatInt(receiver.Value.(int64), ten))
+	case Int64sType:
+		return sliceToXML(encoder, start, valueKey, receiver.Value.([]int64))
+	case Uint64Type:
+		return valueToXML(encoder, start, strconv.FormatUint(receiver.Value.(uint64), ten))
+	case Uint64sType:
+		return sliceToXML(encoder, start, valueKey, receiver.Value.([]uint64))
+	case Float64Type:
+		return valueToXML(encoder, start, strconv.FormatFloat(receiver.Value.(float64), 'f', -1, sixtyFour))
+	case Float64sType:
+		return sliceToXML(encoder, start, valueKey, receiver.Value.([]float64))
+	case StringType:
+		return valueToXML(encoder, start, receiver.Value.(string))
+	case StringsType:
+		return sliceToXML(encoder, start, valueKey, receiver.Value.([]string))
+	case IPType:
+		return valueToXML(encoder, start, ipToString(receiver.Value.(net.IP)))
+	case URLType:
+		return valueToXML(encoder, start, urlToString(receiver.Value.(*url.URL)))
+	case JSONRawType:
+		return valueToXML(encoder, start, jsonRawToString(receiver.Value.(json.RawMessage)))
+	case LogValuerType:
+		return valueToXML(encoder, start, stringerToString(receiver.Value.(fmt.Stringer)))
+	case RuneType:
+		return valueToXML(encoder, start, runeToString(receiver.Value.(rune)))
+	case Complex128Type:
+		return valueToXML(encoder, start, complexToString(receiver.Value.(complex128)))
+	default:
+		return valueToXML(encoder, start, fmt.Sprintf(verboseFormat, receiver.Value))
+	}
+}
+
+// startXML returns a xml.StartElement with the given name and no attributes.
+func startXML(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+// attrStartXML returns the <attr key="..."> xml.StartElement for the given key.
+func attrStartXML(key string) xml.StartElement {
+	start := startXML(attrKey)
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: keyKey}, Value: key})
+
+	return start
+}
+
+// valueToXML writes the given value as the character data of the given element.
+//
+// Parameters:
+//
+//	encoder - the xml.Encoder to write to
+//	start - the element wrapping the value
+//	value - the value to be encoded
+func valueToXML(encoder *xml.Encoder, start xml.StartElement, value string) error {
+	return JoinIf(encoder.EncodeElement(value, start), ErrMarshalXML)
+}
+
+// errorToXML writes the given error to the provided xml.Encoder as an <error> element.
+//
+// If the error is nil, the element has a single <message> child with the value nilValue.
+// If the error is a StructuredError, the element has the same children as the StructuredError.
+// If the error is not a StructuredError, the element has a single <message> child
+// with the value of the error's Error() method.
+func errorToXML(encoder *xml.Encoder, err error) error {
+	var value *StructuredError
+	switch {
+	case err == nil:
+		return messageToXML(encoder, nilValue)
+	case stderrors.As(err, &value):
+		return value.MarshalXML(encoder, startXML(errorKey))
+	default:
+		errStr := strings.TrimSpace(err.Error())
+
+		return messageToXML(encoder, cmpOr(errStr, nilValue))
+	}
+}
+
+// messageToXML writes an <error> element with a single <message> child to the provided xml.Encoder.
+func messageToXML(encoder *xml.Encoder, message string) error {
+	start := startXML(errorKey)
+
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return JoinIf(err, ErrMarshalXML)
+	}
+
+	err = valueToXML(encoder, startXML(messageKey), message)
+	if err != nil {
+		return err
+	}
+
+	return JoinIf(encoder.EncodeToken(start.End()), ErrMarshalXML)
+}
+
+// sliceToXML writes the given slice to the provided xml.Encoder.
+//
+// Parameters:
+//
+//	encoder - the xml.Encoder to write to
+//	start - the element wrapping the slice
+//	itemKey - the name of the element of each scalar item
+//	slice - the slice of values to be encoded
+//
+// Attrs are written as <attr> elements, errors as <error> elements and
+// every other value as an itemKey element.
+func sliceToXML[T any](encoder *xml.Encoder, start xml.StartElement, itemKey string, slice []T) error {
+	err := encoder.EncodeToken(start)
+	if err != nil {
+		return JoinIf(err, ErrMarshalXML)
+	}
+
+	item := startXML(itemKey)
+
+	switch values := any(slice).(type) {
+	case []Attr:
+		for _, value := range values {
+			err = value.MarshalXML(encoder, item)
+			if err != nil {
+				return err
+			}
+		}
+	case []error:
+		for _, value := range values {
+			err = errorToXML(encoder, value)
+			if err != nil {
+				return err
+			}
+		}
+	case []bool:
+		for _, value := range values {
+			err = valueToXML(encoder, item, strconv.FormatBool(value))
+			if err != nil {
+				return err
+			}
+		}
+	case []time.Time:
+		for _, value := range values {
+			err = valueToXML(encoder, item, value.Format(time.RFC3339Nano))
+			if err != nil {
+				return err
+			}
+		}
+	case []time.Duration:
+		for _, value := range values {
+			err = valueToXML(encoder, item, value.String())
+			if err != nil {
+				return err
+			}
+		}
+	case []int:
+		for _, value := range values {
+			err = valueToXML(encoder, item, strconv.Itoa(value))
+			if err != nil {
+				return err
+			}
+		}
+	case []int64:
+		for _, value := range values {
+			err = valueToXML(encoder, item, strconv.FormatInt(value, ten))
+			if err != nil {
+				return err
+			}
+		}
+	case []uint64:
+		for _, value := range values {
+			err = valueToXML(encoder, item, strconv.FormatUint(value, ten))
+			if err != nil {
+				return err
+			}
+		}
+	case []float64:
+		for _, value := range values {
+			err = valueToXML(encoder, item, strconv.FormatFloat(value, 'f', -1, sixtyFour))
+			if err != nil {
+				return err
+			}
+		}
+	case []string:
+		for _, value := range values {
+			err = valueToXML(encoder, item, strings.TrimSpace(value))
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		for _, value := range slice {
+			err = valueToXML(encoder, item, fmt.Sprintf(verboseFormat, value))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return JoinIf(encoder.EncodeToken(start.End()), ErrMarshalXML)
+}
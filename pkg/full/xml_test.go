@@ -0,0 +1,316 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/xml"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredErrorMarshalXML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err *StructuredError
+		// then
+		wantContains []string
+	}{
+		{
+			name:         "given_nil_error_when_marshal_xml_then_returns_xml_with_nil_message",
+			err:          nil,
+			wantContains: []string{`<error><message>!NILVALUE</message></error>`},
+		},
+		{
+			name:         "given_error_with_message_when_marshal_xml_then_returns_xml_with_message",
+			err:          New("test error"),
+			wantContains: []string{`<error><message>test error</message></error>`},
+		},
+		{
+			name:         "given_error_with_empty_message_when_marshal_xml_then_returns_xml_with_nil_value",
+			err:          New(""),
+			wantContains: []string{`<message>!NILVALUE</message>`},
+		},
+		{
+			name:         "given_error_with_code_when_marshal_xml_then_returns_xml_with_code",
+			err:          New("test").WithCode("NOT_FOUND"),
+			wantContains: []string{`<error><message>test</message><code>NOT_FOUND</code></error>`},
+		},
+		{
+			name:         "given_error_with_http_status_when_marshal_xml_then_returns_xml_with_http_status",
+			err:          New("test").WithHTTPStatus(404),
+			wantContains: []string{`<error><message>test</message><http_status>404</http_status></error>`},
+		},
+		{
+			name:         "given_error_with_tags_when_marshal_xml_then_returns_xml_with_tags",
+			err:          New("test").WithTags("tag1", " tag2 "),
+			wantContains: []string{`<tags><tag>tag1</tag><tag>tag2</tag></tags>`},
+		},
+		{
+			name:         "given_error_with_attrs_when_marshal_xml_then_returns_xml_with_attrs",
+			err:          New("test").WithAttrs(String("key", "value"), Int("count", 3)),
+			wantContains: []string{`<attrs><attr key="key">value</attr><attr key="count">3</attr></attrs>`},
+		},
+		{
+			name: "given_error_with_child_errors_when_marshal_xml_then_returns_xml_with_nested_errors",
+			err: New("parent").WithErrors(
+				stderrors.New("child"),
+				New("structured").WithTags("inner"),
+				nil,
+			),
+			wantContains: []string{
+				`<errors><error><message>child</message></error>`,
+				`<error><message>structured</message><tags><tag>inner</tag></tags></error>`,
+				`<error><message>!NILVALUE</message></error></errors>`,
+			},
+		},
+		{
+			name:         "given_error_with_stack_when_marshal_xml_then_returns_xml_with_base64_stack",
+			err:          New("test").WithStack([]byte("stack")),
+			wantContains: []string{`<stack>c3RhY2s=</stack>`},
+		},
+		{
+			name:         "given_error_with_special_characters_when_marshal_xml_then_escapes_them",
+			err:          New("a < b & c"),
+			wantContains: []string{`<message>a &lt; b &amp; c</message>`},
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				var buffer bytes.Buffer
+
+				encoder := xml.NewEncoder(&buffer)
+
+				// when
+				err := test.err.MarshalXML(encoder, xml.StartElement{})
+
+				// then
+				require.NoError(t, err)
+				require.NoError(t, encoder.Flush())
+
+				got := buffer.String()
+				for _, want := range test.wantContains {
+					assert.Contains(t, got, want)
+				}
+			},
+		)
+	}
+}
+
+func TestStructuredErrorMarshalXMLWithXMLMarshal(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("parent").
+		WithTags("tag").
+		WithAttrs(Bool("retry", true)).
+		WithErrors(New("child"))
+
+	// when
+	got, gotErr := xml.Marshal(err)
+
+	// then
+	require.NoError(t, gotErr)
+	assert.Equal(
+		t,
+		`<error><message>parent</message><tags><tag>tag</tag></tags>`+
+			`<attrs><attr key="retry">true</attr></attrs>`+
+			`<errors><error><message>child</message></error></errors></error>`,
+		string(got),
+	)
+}
+
+func TestAttrMarshalXML(t *testing.T) {
+	t.Parallel()
+
+	testTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		// given
+		attr *Attr
+		// then
+		want string
+	}{
+		{
+			name: "given_nil_attr_when_marshal_xml_then_returns_nil_value",
+			attr: nil,
+			want: `<attr key="!NILVALUE">!NILVALUE</attr>`,
+		},
+		{
+			name: "given_string_attr_when_marshal_xml_then_returns_value",
+			attr: &Attr{Type: StringType, Key: "key", Value: "value"},
+			want: `<attr key="key">value</attr>`,
+		},
+		{
+			name: "given_bool_attr_when_marshal_xml_then_returns_value",
+			attr: &Attr{Type: BoolType, Key: "flag", Value: true},
+			want: `<attr key="flag">true</attr>`,
+		},
+		{
+			name: "given_time_attr_when_marshal_xml_then_returns_rfc3339_value",
+			attr: &Attr{Type: TimeType, Key: "at", Value: testTime},
+			want: `<attr key="at">2024-01-02T03:04:05Z</attr>`,
+		},
+		{
+			name: "given_duration_attr_when_marshal_xml_then_returns_value",
+			attr: &Attr{Type: DurationType, Key: "took", Value: time.Second},
+			want: `<attr key="took">1s</attr>`,
+		},
+		{
+			name: "given_float64_attr_when_marshal_xml_then_returns_value",
+			attr: &Attr{Type: Float64Type, Key: "ratio", Value: 1.5},
+			want: `<attr key="ratio">1.5</attr>`,
+		},
+		{
+			name: "given_ints_attr_when_marshal_xml_then_returns_values",
+			attr: &Attr{Type: IntsType, Key: "ids", Value: []int{1, 2}},
+			want: `<attr key="ids"><value>1</value><value>2</value></attr>`,
+		},
+		{
+			name: "given_strings_attr_when_marshal_xml_then_returns_trimmed_values",
+			attr: &Attr{Type: StringsType, Key: "names", Value: []string{" a ", "b"}},
+			want: `<attr key="names"><value>a</value><value>b</value></attr>`,
+		},
+		{
+			name: "given_object_attr_when_marshal_xml_then_returns_nested_attrs",
+			attr: &Attr{Type: ObjectType, Key: "obj", Value: []Attr{String("inner", "value")}},
+			want: `<attr key="obj"><attr key="inner">value</attr></attr>`,
+		},
+		{
+			name: "given_any_attr_when_marshal_xml_then_returns_verbose_value",
+			attr: &Attr{Type: AnyType, Key: "any", Value: struct{ A int }{A: 1}},
+			want: `<attr key="any">{A:1}</attr>`,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				var buffer bytes.Buffer
+
+				encoder := xml.NewEncoder(&buffer)
+
+				// when
+				err := test.attr.MarshalXML(encoder, xml.StartElement{})
+
+				// then
+				require.NoError(t, err)
+				require.NoError(t, encoder.Flush())
+				assert.Equal(t, test.want, buffer.String())
+			},
+		)
+	}
+}
+
+func TestErrorToXML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		name string
+		want string
+	}{
+		{
+			name: "given_nil_error_when_error_to_xml_then_returns_nil_message",
+			err:  nil,
+			want: `<error><message>!NILVALUE</message></error>`,
+		},
+		{
+			name: "given_standard_error_when_error_to_xml_then_returns_message",
+			err:  stderrors.New("standard error"),
+			want: `<error><message>standard error</message></error>`,
+		},
+		{
+			name: "given_error_with_whitespace_when_error_to_xml_then_returns_trimmed_message",
+			err:  stderrors.New("  error  "),
+			want: `<error><message>error</message></error>`,
+		},
+		{
+			name: "given_structured_error_when_error_to_xml_then_returns_structured_message",
+			err:  New("structured"),
+			want: `<error><message>structured</message></error>`,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				var buffer bytes.Buffer
+
+				encoder := xml.NewEncoder(&buffer)
+
+				// when
+				err := errorToXML(encoder, test.err)
+
+				// then
+				require.NoError(t, err)
+				require.NoError(t, encoder.Flush())
+				assert.Equal(t, test.want, buffer.String())
+			},
+		)
+	}
+}
+
+func TestStructuredErrorMarshalXMLWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	var (
+		got  []byte
+		errM error
+	)
+
+	assert.NotPanics(t, func() { got, errM = xml.Marshal(err) })
+
+	// then
+	require.NoError(t, errM)
+	assert.Contains(t, string(got), "not an int")
+	assert.Contains(t, string(got), "john")
+}
+
+func TestStructuredErrorMarshalXMLWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	err := New("").WithErrors(nil)
+
+	// when
+	got, errM := xml.Marshal(err)
+
+	// then
+	require.NoError(t, errM)
+	assert.Contains(t, string(got), "NULL")
+	assert.NotContains(t, string(got), "!NILVALUE")
+}
@@ -102,6 +102,16 @@ func TestStructuredErrorMarshalLogObjectFields(t *testing.T) {
 			err:      New("test"),
 			wantKeys: []string{"message"},
 		},
+		{
+			name:     "given_error_with_code_when_marshal_log_object_then_has_message_and_code",
+			err:      New("test").WithCode("NOT_FOUND"),
+			wantKeys: []string{"message", "code"},
+		},
+		{
+			name:     "given_error_with_http_status_when_marshal_log_object_then_has_message_and_http_status",
+			err:      New("test").WithHTTPStatus(404),
+			wantKeys: []string{"message", "http_status"},
+		},
 		{
 			name:     "given_error_with_tags_when_marshal_log_object_then_has_message_and_tags",
 			err:      New("test").WithTags("tag1"),
@@ -782,3 +792,45 @@ func TestStructuredErrorMarshalLogObjectIntegration(t *testing.T) {
 		)
 	}
 }
+
+func TestStructuredErrorMarshalLogObjectWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	encoder := zapcore.NewMapObjectEncoder()
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	var errM error
+
+	assert.NotPanics(t, func() { errM = err.MarshalLogObject(encoder) })
+
+	// then
+	require.NoError(t, errM)
+	assert.Equal(t, map[string]any{"count": "not an int", "user": "john"}, encoder.Fields["attrs"])
+}
+
+func TestStructuredErrorMarshalLogObjectWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var err *StructuredError
+
+	encoder := zapcore.NewMapObjectEncoder()
+
+	// when
+	errM := err.MarshalLogObject(encoder)
+
+	// then
+	require.NoError(t, errM)
+	assert.Equal(t, "NULL", encoder.Fields["message"])
+}
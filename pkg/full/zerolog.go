@@ -1,7 +1,12 @@
 package errors
 
 import (
+	"encoding/json"
 	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +21,32 @@ type (
 	LogArrayMarshalerFunc func(*zerolog.Array)
 )
 
+//nolint:gochecknoglobals // needed to avoid use of specific instance of StructuredError
+var zerologStackMaxLines int
+
+// ZerologStackMaxLines returns the maximum number of stack lines emitted by MarshalZerologObject,
+// or 0 if every line is emitted.
+func ZerologStackMaxLines() int {
+	return zerologStackMaxLines
+}
+
+// SetZerologStackMaxLines sets the maximum number of stack lines emitted by MarshalZerologObject.
+// Only the top lines are emitted, followed by a "... +M more" line with the number of lines left out,
+// so huge stacks do not exceed the size limits of zerolog events.
+//
+// The default value is 0, which emits every line, as do negative values.
+// The other marshalers are not affected.
+//
+// SetZerologStackMaxLines is not thread-safe. It should be called before any StructuredError is
+// marshaled.
+func SetZerologStackMaxLines(lines int) {
+	if lines < zero {
+		lines = zero
+	}
+
+	zerologStackMaxLines = lines
+}
+
 // MarshalZerologObject implements zerolog.LogObjectMarshaler.
 func (f LogObjectMarshalerFunc) MarshalZerologObject(e *zerolog.Event) {
 	f(e)
@@ -35,10 +66,14 @@ func (f LogArrayMarshalerFunc) MarshalZerologArray(e *zerolog.Array) {
 //
 // Otherwise, it will have the following attributes:
 //   - Message
+//   - Code, if not empty
+//   - HTTPStatus, if not zero
 //   - Tags
 //   - Attrs
+//   - Joined, only for errors created via Join or JoinIf
 //   - Errors
-//   - Stack.
+//   - Stack
+//   - Caller, only for errors with a caller set via WithCaller or WithCallerSkip.
 //
 // Usage must be with zerolog.Event.Interface or zerolog.Event.Object.
 func (receiver *StructuredError) MarshalZerologObject(event *zerolog.Event) {
@@ -48,30 +83,57 @@ func (receiver *StructuredError) MarshalZerologObject(event *zerolog.Event) {
 		return
 	}
 
-	event.Str(messageKey, cmpOr(receiver.Message, nilValue))
+	event.Str(messageKey, cmpOr(trimmedMessage(receiver.Message), nilValue))
+
+	if receiver.Code != emptyString {
+		event.Str(codeKey, receiver.Code)
+	}
 
-	if len(receiver.Tags) > zero {
+	if receiver.HTTPStatus != zero {
+		event.Int(httpStatusKey, receiver.HTTPStatus)
+	}
+
+	if keepField(len(receiver.Tags)) {
 		sliceToZerolog(event, tagsKey, receiver.Tags)
 	}
 
-	if len(receiver.Attrs) > zero {
-		sliceToZerolog(event, attrsKey, receiver.Attrs)
+	if attrs := withoutOmittedAttrs(receiver.Attrs); keepField(len(attrs)) {
+		sliceToZerolog(event, attrsKey, attrs)
 	}
 
-	if len(receiver.Errors) > zero {
-		target := normalizerTarget{
-			errs: make([]error, zero, len(receiver.Errors)),
-		}
-		normalizeErrors(zero, &target, receiver.Errors...)
+	if receiver.joined {
+		event.Bool(joinedKey, true)
+	}
+
+	if keepField(len(receiver.Errors)) {
+		normalized := receiver.normalizedErrors()
+
+		sliceToZerolog(event, errorsKey, normalized)
+	}
 
-		sliceToZerolog(event, errorsKey, target.errs)
+	if keepField(len(receiver.Stack)) {
+		sliceToZerolog(event, stackKey, stackToZerolog(receiver.Stack))
 	}
 
-	if len(receiver.Stack) > zero {
-		sliceToZerolog(event, stackKey, strings.Split(string(receiver.Stack), newLine))
+	if receiver.caller != emptyString {
+		event.Str(callerKey, receiver.caller)
 	}
 }
 
+// stackToZerolog splits the given stack into lines, keeping the top lines set via SetZerologStackMaxLines
+// followed by a "... +M more" line when there are more.
+func stackToZerolog(stack []byte) []string {
+	lines := stackLines(stack)
+
+	if zerologStackMaxLines == zero || len(lines) <= zerologStackMaxLines {
+		return lines
+	}
+
+	more := len(lines) - zerologStackMaxLines
+
+	return append(lines[:zerologStackMaxLines:zerologStackMaxLines], "... +"+strconv.Itoa(more)+" more")
+}
+
 // MarshalZerologObject implements zerolog.LogObjectMarshaler.
 //
 // It marshals the Attr into the given zerolog.Event.
@@ -93,8 +155,16 @@ func (receiver *Attr) MarshalZerologObject(event *zerolog.Event) {
 		return
 	}
 
+	receiver = receiver.redacted()
+
 	switch receiver.Type {
 	case AnyType:
+		if values, ok := receiver.Value.([]any); ok && len(values) > zero {
+			sliceToZerolog(event, receiver.Key, values)
+
+			return
+		}
+
 		event.Interface(receiver.Key, receiver.Value)
 	case ObjectType:
 		sliceToZerolog(event, receiver.Key, receiver.Value.([]Attr))
@@ -130,6 +200,18 @@ func (receiver *Attr) MarshalZerologObject(event *zerolog.Event) {
 		event.Str(receiver.Key, receiver.Value.(string))
 	case StringsType:
 		event.Strs(receiver.Key, receiver.Value.([]string))
+	case IPType:
+		event.Str(receiver.Key, ipToString(receiver.Value.(net.IP)))
+	case URLType:
+		event.Str(receiver.Key, urlToString(receiver.Value.(*url.URL)))
+	case JSONRawType:
+		event.Str(receiver.Key, jsonRawToString(receiver.Value.(json.RawMessage)))
+	case LogValuerType:
+		event.Str(receiver.Key, stringerToString(receiver.Value.(fmt.Stringer)))
+	case RuneType:
+		event.Str(receiver.Key, runeToString(receiver.Value.(rune)))
+	case Complex128Type:
+		event.Str(receiver.Key, complexToString(receiver.Value.(complex128)))
 	default:
 		event.Interface(receiver.Key, receiver.Value)
 	}
@@ -168,6 +250,9 @@ func errorToZerolog(event *zerolog.Event, err error) {
 //
 // If the slice is of type []string, it trims each string and marshals the trimmed strings into the event.
 //
+// If the slice is of type []any and all its values share the same scalar type, it marshals them
+// with the zerolog.Array method of their native type, like zerolog.Array.Int, instead of zerolog.Array.Interface.
+//
 // Otherwise, it marshals the slice into the event as an array of interfaces.
 func sliceToZerolog[T any](event *zerolog.Event, key string, slice []T) {
 	if len(slice) == zero {
@@ -216,6 +301,21 @@ func sliceToZerolog[T any](event *zerolog.Event, key string, slice []T) {
 				},
 			),
 		)
+	case []any:
+		event.Array(
+			key,
+			LogArrayMarshalerFunc(
+				func(eventArr *zerolog.Array) {
+					if appendScalarsToZerolog(eventArr, values) {
+						return
+					}
+
+					for _, value := range values {
+						eventArr.Interface(value)
+					}
+				},
+			),
+		)
 	default:
 		event.Array(
 			key,
@@ -229,3 +329,51 @@ func sliceToZerolog[T any](event *zerolog.Event, key string, slice []T) {
 		)
 	}
 }
+
+// appendScalarsToZerolog appends the values to the given zerolog.Array with the method of their native type,
+// if all of them share the same scalar type.
+// It reports whether the values were appended, if not, the zerolog.Array is not modified.
+func appendScalarsToZerolog(eventArr *zerolog.Array, values []any) bool {
+	if len(values) == zero {
+		return false
+	}
+
+	switch values[zero].(type) {
+	case bool:
+		return appendAllToZerolog(eventArr, values, (*zerolog.Array).Bool)
+	case time.Time:
+		return appendAllToZerolog(eventArr, values, (*zerolog.Array).Time)
+	case time.Duration:
+		return appendAllToZerolog(eventArr, values, (*zerolog.Array).Dur)
+	case int:
+		return appendAllToZerolog(eventArr, values, (*zerolog.Array).Int)
+	case int64:
+		return appendAllToZerolog(eventArr, values, (*zerolog.Array).Int64)
+	case uint64:
+		return appendAllToZerolog(eventArr, values, (*zerolog.Array).Uint64)
+	case float64:
+		return appendAllToZerolog(eventArr, values, (*zerolog.Array).Float64)
+	case string:
+		return appendAllToZerolog(eventArr, values, (*zerolog.Array).Str)
+	default:
+		return false
+	}
+}
+
+// appendAllToZerolog appends the values to the given zerolog.Array with the given method, if all of them are of type T.
+// It reports whether the values were appended, if not, the zerolog.Array is not modified.
+func appendAllToZerolog[T any](
+	eventArr *zerolog.Array, values []any, appendValue func(*zerolog.Array, T) *zerolog.Array,
+) bool {
+	for _, value := range values {
+		if _, ok := value.(T); !ok {
+			return false
+		}
+	}
+
+	for _, value := range values {
+		appendValue(eventArr, value.(T)) //nolint:forcetypeassert,errcheck // checked above
+	}
+
+	return true
+}
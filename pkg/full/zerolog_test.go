@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	stderrors "errors"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -116,6 +118,16 @@ func TestStructuredErrorMarshalZerologObject(t *testing.T) {
 			err:          New("test error"),
 			wantContains: []string{`"message":"test error"`},
 		},
+		{
+			name:         "given_error_with_code_when_marshal_zerolog_object_then_has_code",
+			err:          New("test").WithCode("NOT_FOUND"),
+			wantContains: []string{`"message":"test","code":"NOT_FOUND"`},
+		},
+		{
+			name:         "given_error_with_http_status_when_marshal_zerolog_object_then_has_http_status",
+			err:          New("test").WithHTTPStatus(404),
+			wantContains: []string{`"message":"test","http_status":404`},
+		},
 		{
 			name:         "given_error_with_tags_when_marshal_zerolog_object_then_has_tags",
 			err:          New("test").WithTags("tag1", "tag2"),
@@ -164,6 +176,76 @@ func TestStructuredErrorMarshalZerologObject(t *testing.T) {
 	}
 }
 
+func TestStructuredErrorMarshalZerologObjectWithTrimMessages(t *testing.T) { //nolint:paralleltest // SetTrimMessages is not thread-safe
+	// given
+	SetTrimMessages(true)
+	t.Cleanup(func() { SetTrimMessages(false) })
+
+	err := &StructuredError{Message: "  padded  ", Errors: []error{stderrors.New("  plain  ")}}
+
+	var buf bytes.Buffer
+
+	logger := zerolog.New(&buf)
+	event := logger.Info()
+
+	// when
+	err.MarshalZerologObject(event)
+	event.Send()
+
+	// then
+	assert.Contains(t, buf.String(), `"message":"padded"`)
+	assert.Contains(t, buf.String(), `"errors":[{"message":"plain"}]`)
+}
+
+func TestStructuredErrorMarshalZerologObjectAfterMutation(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("parent").WithErrors(New("first"))
+
+	marshal := func() string {
+		var buf bytes.Buffer
+
+		logger := zerolog.New(&buf)
+		event := logger.Info()
+
+		err.MarshalZerologObject(event)
+		event.Send()
+
+		return buf.String()
+	}
+
+	first := marshal()
+
+	// when
+	err.AppendErrors(New("second"))
+
+	second := marshal()
+
+	// then
+	assert.Contains(t, first, `"errors":[{"message":"first"}]`)
+	assert.Contains(t, second, `"errors":[{"message":"first"},{"message":"second"}]`)
+}
+
+func TestStructuredErrorMarshalZerologObjectWithCaller(t *testing.T) {
+	t.Parallel()
+
+	// given
+	err := New("test").WithCaller()
+
+	var buf bytes.Buffer
+
+	logger := zerolog.New(&buf)
+	event := logger.Info()
+
+	// when
+	err.MarshalZerologObject(event)
+	event.Send()
+
+	// then
+	assert.Contains(t, buf.String(), `"caller":"`+err.Caller()+`"`)
+}
+
 func TestStructuredErrorMarshalZerologObjectFields(t *testing.T) {
 	t.Parallel()
 
@@ -231,6 +313,81 @@ func TestStructuredErrorMarshalZerologObjectFields(t *testing.T) {
 	}
 }
 
+func TestStructuredErrorMarshalZerologObjectWithJoined(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// given
+		err error
+		// then
+		wantJoined bool
+	}{
+		{
+			name:       "given_joined_error_when_marshal_zerolog_object_then_has_joined_field",
+			err:        Join(New("first"), New("second")),
+			wantJoined: true,
+		},
+		{
+			name:       "given_error_with_errors_when_marshal_zerolog_object_then_has_no_joined_field",
+			err:        New("parent").WithErrors(New("first"), New("second")),
+			wantJoined: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				var buf bytes.Buffer
+
+				logger := zerolog.New(&buf)
+				event := logger.Info()
+
+				structured, ok := test.err.(*StructuredError)
+				require.True(t, ok)
+
+				// when
+				structured.MarshalZerologObject(event)
+				event.Msg("test")
+
+				// then
+				var result map[string]any
+
+				err := json.Unmarshal(buf.Bytes(), &result)
+				require.NoError(t, err)
+
+				if test.wantJoined {
+					assert.Equal(t, true, result["joined"])
+				} else {
+					assert.NotContains(t, result, "joined")
+				}
+			},
+		)
+	}
+}
+
+func TestStructuredErrorMarshalZerologObjectWithoutOmitEmpty(t *testing.T) { //nolint:paralleltest // SetOmitEmpty is not thread-safe
+	// given
+	SetOmitEmpty(false)
+	t.Cleanup(func() { SetOmitEmpty(true) })
+
+	var buf bytes.Buffer
+
+	logger := zerolog.New(&buf)
+	event := logger.Info()
+
+	// when
+	New("test").MarshalZerologObject(event)
+	event.Send()
+
+	// then
+	assert.JSONEq(t, `{"level":"info","message":"test","tags":[],"attrs":[],"errors":[],"stack":[]}`, buf.String())
+}
+
 func TestAttrMarshalZerologObject(t *testing.T) {
 	t.Parallel()
 
@@ -796,3 +953,225 @@ func TestStructuredErrorMarshalZerologObjectIntegration(t *testing.T) {
 		)
 	}
 }
+
+func TestStructuredErrorMarshalZerologObjectRedactsSensitiveAttrs(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var buf bytes.Buffer
+
+	logger := zerolog.New(&buf)
+	err := New("test").WithAttrs(Sensitive("password", "secret"), String("user", "john"))
+
+	// when
+	logger.Error().Object("error", err).Send()
+
+	// then
+	assert.Contains(t, buf.String(), `"password":"[REDACTED]"`)
+	assert.Contains(t, buf.String(), `"user":"john"`)
+	assert.NotContains(t, buf.String(), "secret")
+	assert.Equal(t, "secret", err.Attrs[0].Value)
+}
+
+func TestZerologStackMaxLines(t *testing.T) { //nolint:paralleltest // SetZerologStackMaxLines is not thread-safe
+	t.Cleanup(
+		func() {
+			SetZerologStackMaxLines(0)
+		},
+	)
+
+	// given
+	SetZerologStackMaxLines(10)
+
+	// when
+	got := ZerologStackMaxLines()
+
+	// then
+	assert.Equal(t, 10, got)
+}
+
+func TestSetZerologStackMaxLines(t *testing.T) { //nolint:paralleltest // SetZerologStackMaxLines is not thread-safe
+	t.Cleanup(
+		func() {
+			SetZerologStackMaxLines(0)
+		},
+	)
+
+	// given
+	lines := make([]string, 0, 50)
+	for index := 0; index < 50; index++ {
+		lines = append(lines, "frame "+strconv.Itoa(index))
+	}
+
+	err := New("test").WithStack([]byte(strings.Join(lines, "\n")))
+
+	tests := []struct {
+		name string
+		// given
+		maxLines int
+		// then
+		wantLines []string
+	}{
+		{
+			name:      "given_zero_max_lines_when_marshal_then_emits_every_line",
+			maxLines:  0,
+			wantLines: lines,
+		},
+		{
+			name:      "given_negative_max_lines_when_marshal_then_emits_every_line",
+			maxLines:  -1,
+			wantLines: lines,
+		},
+		{
+			name:      "given_max_lines_above_stack_size_when_marshal_then_emits_every_line",
+			maxLines:  50,
+			wantLines: lines,
+		},
+		{
+			name:      "given_max_lines_below_stack_size_when_marshal_then_emits_top_lines_and_marker",
+			maxLines:  10,
+			wantLines: append(append([]string{}, lines[:10]...), "... +40 more"),
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				// given
+				SetZerologStackMaxLines(test.maxLines)
+
+				var buf bytes.Buffer
+
+				logger := zerolog.New(&buf)
+
+				// when
+				logger.Log().Object("error", err).Send()
+
+				// then
+				var got struct {
+					Error struct {
+						Stack []string `json:"stack"`
+					} `json:"error"`
+				}
+
+				require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+				assert.Equal(t, test.wantLines, got.Error.Stack)
+				assert.Len(t, err.Stack, len(strings.Join(lines, "\n")))
+			},
+		)
+	}
+}
+
+func TestAttrMarshalZerologObjectWithAnySlices(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		// given
+		attr Attr
+		// then
+		want       string
+		wantNative bool
+	}{
+		{
+			name:       "given_homogeneous_ints_when_marshal_then_appends_native_ints",
+			attr:       Any("values", []any{1, 2, 3}),
+			want:       `{"values":[1,2,3]}`,
+			wantNative: true,
+		},
+		{
+			name:       "given_homogeneous_strings_when_marshal_then_appends_native_strings",
+			attr:       Any("values", []any{"a", "b"}),
+			want:       `{"values":["a","b"]}`,
+			wantNative: true,
+		},
+		{
+			name:       "given_homogeneous_times_when_marshal_then_appends_native_times",
+			attr:       Any("values", []any{now}),
+			want:       `{"values":["2024-01-02T03:04:05Z"]}`,
+			wantNative: true,
+		},
+		{
+			name:       "given_heterogeneous_values_when_marshal_then_appends_interfaces",
+			attr:       Any("values", []any{1, "a", true}),
+			want:       `{"values":[1,"a",true]}`,
+			wantNative: false,
+		},
+		{
+			name:       "given_non_scalar_values_when_marshal_then_appends_interfaces",
+			attr:       Any("values", []any{map[string]int{"a": 1}}),
+			want:       `{"values":[{"a":1}]}`,
+			wantNative: false,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// given
+				var buf bytes.Buffer
+
+				logger := zerolog.New(&buf)
+
+				// when
+				logger.Log().EmbedObject(&test.attr).Send()
+				values, _ := test.attr.Value.([]any)
+				native := appendScalarsToZerolog(zerolog.Arr(), values)
+
+				// then
+				assert.JSONEq(t, test.want, buf.String())
+				assert.Equal(t, test.wantNative, native)
+			},
+		)
+	}
+}
+
+func TestStructuredErrorMarshalZerologObjectWithMismatchedAttr(t *testing.T) {
+	t.Parallel()
+
+	// given
+	var buf bytes.Buffer
+
+	logger := zerolog.New(&buf)
+	err := New("test").WithAttrs(
+		Attr{Type: IntType, Key: "count", Value: "not an int"},
+		Attr{Type: ObjectType, Key: "user", Value: "john"},
+	)
+
+	// when
+	assert.NotPanics(t, func() { logger.Error().Object("error", err).Send() })
+
+	// then
+	assert.Contains(t, buf.String(), `"count":"not an int"`)
+	assert.Contains(t, buf.String(), `"user":"john"`)
+}
+
+func TestStructuredErrorMarshalZerologObjectWithCustomNilValue(t *testing.T) { //nolint:paralleltest // SetNilValue is not thread-safe
+	t.Cleanup(
+		func() {
+			SetNilValue("")
+		},
+	)
+
+	// given
+	SetNilValue("NULL")
+
+	var (
+		err *StructuredError
+		buf bytes.Buffer
+	)
+
+	logger := zerolog.New(&buf)
+
+	// when
+	logger.Error().Object("error", err).Send()
+
+	// then
+	assert.Contains(t, buf.String(), `"message":"NULL"`)
+}
@@ -0,0 +1,172 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type (
+	// GokitKeyvalsMarshaler is implemented by values that can be flattened into go-kit/log key/value pairs.
+	//
+	// This is not go-kit/log official interface, but it is here to help you somehow is you need to do type assertions.
+	GokitKeyvalsMarshaler interface {
+		LogKeyvals() []any
+	}
+)
+
+const (
+	gokitPrefix    = "err"
+	gokitSeparator = "."
+)
+
+// LogKeyvals flattens the StructuredError into alternating key/value pairs.
+// Every key is prefixed with "err.".
+//
+// If the receiver is nil, it has a single pair with the key "err.message" and the value nilValue.
+//
+// Otherwise, it will have the following keys:
+//   - err.message
+//   - err.code
+//   - err.tags
+//   - err.attrs.<key>, object attrs are flattened with dotted keys
+//   - err.errors.<index>.<key>, nested errors are flattened with indexed keys
+//   - err.stack.
+//
+// Usage must be like:
+//
+//	var _err *errors.StructuredError
+//
+//	if !errors.As(err, &_err) {
+//	  log.Fatal("what!?", err)
+//	}
+//
+//	logger := log.NewLogfmtLogger(os.Stderr)
+//	logger.Log(append([]any{"msg", "message"}, _err.LogKeyvals()...)...)
+func (receiver *StructuredError) LogKeyvals() []any {
+	return receiver.asGokit(nil, gokitPrefix+gokitSeparator)
+}
+
+// asGokit is the actual implementation for LogKeyvals.
+// It appends the key/value pairs of the receiver, prefixed with the given prefix, to the given keyvals.
+func (receiver *StructuredError) asGokit(keyvals []any, prefix string) []any {
+	if receiver == nil {
+		return append(keyvals, prefix+messageKey, nilValue)
+	}
+
+	keyvals = append(keyvals, prefix+messageKey, cmpOr(trimmedMessage(receiver.Message), nilValue))
+
+	if receiver.Code != emptyString {
+		keyvals = append(keyvals, prefix+codeKey, receiver.Code)
+	}
+
+	if receiver.HTTPStatus != zero {
+		keyvals = append(keyvals, prefix+httpStatusKey, receiver.HTTPStatus)
+	}
+
+	if keepField(len(receiver.Tags)) {
+		tags := make([]string, zero, len(receiver.Tags))
+		for _, tag := range receiver.Tags {
+			tags = append(tags, strings.TrimSpace(tag))
+		}
+
+		keyvals = append(keyvals, prefix+tagsKey, strings.Join(tags, comma))
+	}
+
+	for _, attr := range withoutOmittedAttrs(receiver.Attrs) {
+		keyvals = attr.asGokit(keyvals, prefix+attrsKey+gokitSeparator)
+	}
+
+	if len(receiver.Errors) > zero {
+		normalized := receiver.normalizedErrors()
+
+		for index, err := range normalized {
+			keyvals = errorToGokit(keyvals, prefix+errorsKey+gokitSeparator+strconv.Itoa(index)+gokitSeparator, err)
+		}
+	}
+
+	if keepField(len(receiver.Stack)) {
+		keyvals = append(keyvals, prefix+stackKey, string(receiver.Stack))
+	}
+
+	return keyvals
+}
+
+// LogKeyvals flattens the Attr into alternating key/value pairs.
+// If the receiver is nil, it has a single pair with the key nilValue and the value nilValue.
+//
+// Otherwise, it will have a single pair with the key receiver.Key and the value receiver.Value,
+// or one pair per nested Attr with dotted keys if the receiver is an object.
+func (receiver *Attr) LogKeyvals() []any {
+	return receiver.asGokit(nil, emptyString)
+}
+
+// asGokit is the actual implementation for LogKeyvals.
+// It appends the key/value pairs of the receiver, prefixed with the given prefix, to the given keyvals.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) asGokit(keyvals []any, prefix string) []any {
+	if receiver == nil {
+		return append(keyvals, prefix+nilValue, nilValue)
+	}
+
+	receiver = receiver.redacted()
+
+	switch receiver.Type { //nolint:exhaustive // just objects, strings, IPs, URLs and raw JSON need specific assert
+	case ObjectType:
+		for _, attr := range receiver.Value.([]Attr) {
+			keyvals = attr.asGokit(keyvals, prefix+receiver.Key+gokitSeparator)
+		}
+
+		return keyvals
+	case StringsType:
+		values := receiver.Value.([]string)
+
+		result := make([]string, zero, len(values))
+		for _, value := range values {
+			result = append(result, strings.TrimSpace(value))
+		}
+
+		return append(keyvals, prefix+receiver.Key, strings.Join(result, comma))
+	case IPType:
+		return append(keyvals, prefix+receiver.Key, ipToString(receiver.Value.(net.IP)))
+	case URLType:
+		return append(keyvals, prefix+receiver.Key, urlToString(receiver.Value.(*url.URL)))
+	case JSONRawType:
+		return append(keyvals, prefix+receiver.Key, jsonRawToString(receiver.Value.(json.RawMessage)))
+	case LogValuerType:
+		return append(keyvals, prefix+receiver.Key, stringerToString(receiver.Value.(fmt.Stringer)))
+	case RuneType:
+		return append(keyvals, prefix+receiver.Key, runeToString(receiver.Value.(rune)))
+	case Complex128Type:
+		return append(keyvals, prefix+receiver.Key, complexToString(receiver.Value.(complex128)))
+	default:
+		return append(keyvals, prefix+receiver.Key, receiver.Value)
+	}
+}
+
+// errorToGokit appends the key/value pairs of the given error, prefixed with the given prefix, to the given keyvals.
+//
+// If the error is nil, it appends a single pair with the key "message" and the value nilValue.
+//
+// If the error is a *StructuredError, it appends the pairs of the *StructuredError.
+//
+// If the error is not a *StructuredError, it appends a single pair with the key "message"
+// and the value of the error's Error() method, or nilValue if the error message is empty.
+func errorToGokit(keyvals []any, prefix string, err error) []any {
+	var value *StructuredError
+	switch {
+	case err == nil:
+		return append(keyvals, prefix+messageKey, nilValue)
+	case stderrors.As(err, &value):
+		return value.asGokit(keyvals, prefix)
+	default:
+		errStr := strings.TrimSpace(err.Error())
+
+		return append(keyvals, prefix+messageKey, cmpOr(errStr, nilValue))
+	}
+}
@@ -0,0 +1,616 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DurationStyle is how Error() and String() write time.Duration values.
+type DurationStyle uint8
+
+// DurationStyle constants define how Error() and String() write time.Duration values.
+const (
+	// DurationAsString writes durations like time.Duration.String() does, like 1m30s.
+	DurationAsString DurationStyle = iota
+	// DurationAsSeconds writes durations as a number of seconds, like 90 or 0.5.
+	DurationAsSeconds
+)
+
+const (
+	colorReset = "\x1b[0m"
+	colorBold  = "\x1b[1m"
+	colorRed   = "\x1b[31m"
+	colorCyan  = "\x1b[36m"
+
+	truncatedMarker = "...(truncated)"
+)
+
+//nolint:gochecknoglobals // needed to avoid use of specific instance of StructuredError
+var (
+	colorOutput    bool
+	stringMaxDepth int
+	timeFormat     = time.RFC3339
+	durationFormat = DurationAsString
+
+	stringBufferPool = sync.Pool{
+		New: func() any {
+			return new(bytes.Buffer)
+		},
+	}
+)
+
+// SetColorOutput sets whether Error() and String() highlight their output with ANSI color codes,
+// like ColorString() does. It is disabled by default, to keep logs free of escape sequences.
+//
+// SetColorOutput is not thread-safe. It should be called before any StructuredError is
+// converted to a string.
+func SetColorOutput(enabled bool) {
+	colorOutput = enabled
+}
+
+// TimeFormat returns the layout used by Error() and String() to write time.Time values.
+func TimeFormat() string {
+	return timeFormat
+}
+
+// SetTimeFormat sets the layout used by Error() and String() to write time.Time values,
+// as time.Time.Format expects it. An empty layout restores the default time.RFC3339.
+//
+// The other marshalers, like MarshalJSON, are not affected.
+//
+// SetTimeFormat is not thread-safe. It should be called before any StructuredError is
+// converted to a string.
+func SetTimeFormat(layout string) {
+	timeFormat = cmpOr(layout, time.RFC3339)
+}
+
+// DurationFormat returns how Error() and String() write time.Duration values.
+func DurationFormat() DurationStyle {
+	return durationFormat
+}
+
+// SetDurationFormat sets how Error() and String() write time.Duration values.
+// The default value is DurationAsString.
+//
+// The other marshalers, like MarshalJSON, are not affected.
+//
+// SetDurationFormat is not thread-safe. It should be called before any StructuredError is
+// converted to a string.
+func SetDurationFormat(style DurationStyle) {
+	durationFormat = style
+}
+
+// StringMaxDepth returns the maximum nesting level written by Error() and String(),
+// or 0 if the nesting level is not limited.
+func StringMaxDepth() int {
+	return stringMaxDepth
+}
+
+// SetStringMaxDepth sets the maximum nesting level written by Error(), String() and ColorString().
+// The attrs, errors and objects nested deeper than the given level are replaced by "...(truncated)",
+// so deeply nested errors do not produce huge log lines.
+//
+// The default value is 0, which does not limit the nesting level, as do negative values.
+// The other marshalers, like MarshalJSON, are not affected.
+//
+// SetStringMaxDepth is not thread-safe. It should be called before any StructuredError is
+// converted to a string.
+func SetStringMaxDepth(depth int) {
+	if depth < zero {
+		depth = zero
+	}
+
+	stringMaxDepth = depth
+}
+
+// Error returns the error message as a string.
+// Implementation for rhe error built-in interface type for representing an error condition,
+// with the nil value representing no error.
+//
+// The returned slog.Value will have the following attributes:
+//   - Message
+//   - Code, if not empty
+//   - HTTPStatus, if not zero
+//   - Tags
+//   - Attrs
+//   - Errors
+//   - Stack.
+func (receiver *StructuredError) Error() string {
+	return pooledString(func(bytesBuffer *bytes.Buffer) {
+		receiver.asString(bytesBuffer, colorOutput, zero)
+	})
+}
+
+// String returns the error message as a string.
+// It is equivalent to calling Error().
+func (receiver *StructuredError) String() string {
+	return receiver.Error()
+}
+
+// ColorString returns the error message as a string, like Error(),
+// but always highlighted with ANSI color codes for terminals:
+// the message is bold, the keys are cyan and the !NILVALUE markers are red.
+//
+// It is meant for local development, Error() only uses colors after calling SetColorOutput(true).
+func (receiver *StructuredError) ColorString() string {
+	return pooledString(func(bytesBuffer *bytes.Buffer) {
+		receiver.asString(bytesBuffer, true, zero)
+	})
+}
+
+// asString is the actual implementation for Error.
+func (receiver *StructuredError) asString(bytesBuffer *bytes.Buffer, colored bool, depth int) {
+	if receiver == nil {
+		messageToString(bytesBuffer, colored, nilValue)
+
+		return
+	}
+
+	messageToString(bytesBuffer, colored, cmpOr(trimmedMessage(receiver.Message), nilValue))
+
+	if receiver.Code != emptyString {
+		bytesBuffer.WriteString(comma)
+		bytesBuffer.WriteString(newLine)
+		valueToString(bytesBuffer, colored, codeKey, receiver.Code)
+	}
+
+	if receiver.HTTPStatus != zero {
+		bytesBuffer.WriteString(comma)
+		bytesBuffer.WriteString(newLine)
+		valueToString(bytesBuffer, colored, httpStatusKey, strconv.Itoa(receiver.HTTPStatus))
+	}
+
+	if keepField(len(receiver.Tags)) {
+		bytesBuffer.WriteString(comma)
+		bytesBuffer.WriteString(newLine)
+		sliceToString(bytesBuffer, colored, zero, tagsKey, receiver.Tags)
+	}
+
+	if attrs := withoutOmittedAttrs(receiver.Attrs); keepField(len(attrs)) {
+		bytesBuffer.WriteString(comma)
+		bytesBuffer.WriteString(newLine)
+		sliceToString(bytesBuffer, colored, depth, attrsKey, attrs)
+	}
+
+	if keepField(len(receiver.Errors)) {
+		normalized := receiver.normalizedErrors()
+
+		bytesBuffer.WriteString(comma)
+		bytesBuffer.WriteString(newLine)
+		tabToString(bytesBuffer, depth)
+		sliceToString(bytesBuffer, colored, depth, errorsKey, normalized)
+	}
+
+	if keepField(len(receiver.Stack)) {
+		bytesBuffer.WriteString(comma)
+		bytesBuffer.WriteString(newLine)
+		valueToString(bytesBuffer, colored, stackKey, string(receiver.Stack))
+		bytesBuffer.WriteString(newLine)
+	}
+}
+
+// String returns the error message as a string.
+func (receiver *Attr) String() string {
+	return pooledString(func(bytesBuffer *bytes.Buffer) {
+		receiver.asString(bytesBuffer, colorOutput, zero)
+	})
+}
+
+// pooledString calls write with a buffer taken from stringBufferPool and returns a copy of what it wrote,
+// so formatting errors repeatedly, like into several sinks, reuses the buffers instead of growing new ones.
+// The buffer is reset before use, and is not returned to the pool if it grew above maxPooledBufferSize.
+func pooledString(write func(bytesBuffer *bytes.Buffer)) string {
+	bytesBuffer := stringBufferPool.Get().(*bytes.Buffer) //nolint:forcetypeassert,errcheck // the pool only holds *bytes.Buffer
+	bytesBuffer.Reset()
+
+	defer func() {
+		if bytesBuffer.Cap() <= maxPooledBufferSize {
+			stringBufferPool.Put(bytesBuffer)
+		}
+	}()
+
+	write(bytesBuffer)
+
+	return bytesBuffer.String()
+}
+
+// asString is the actual implementation for String.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) asString(bytesBuffer *bytes.Buffer, colored bool, depth int) {
+	if receiver == nil {
+		valueToString(bytesBuffer, colored, nilValue, nilValue)
+
+		return
+	}
+
+	receiver = receiver.redacted()
+
+	switch receiver.Type {
+	case AnyType:
+		valueToString(bytesBuffer, colored, receiver.Key, fmt.Sprintf(verboseFormat, receiver.Value))
+	case ObjectType:
+		objectToString(bytesBuffer, colored, depth, receiver.Key, receiver.Value.([]Attr))
+	case BoolType:
+		valueToString(bytesBuffer, colored, receiver.Key, strconv.FormatBool(receiver.Value.(bool)))
+	case BoolsType:
+		sliceToString(bytesBuffer, colored, depth, receiver.Key, receiver.Value.([]bool))
+	case TimeType:
+		valueToString(bytesBuffer, colored, receiver.Key, timeToString(receiver.Value.(time.Time)))
+	case TimesType:
+		sliceToString(bytesBuffer, colored, depth, receiver.Key, receiver.Value.([]time.Time))
+	case DurationType:
+		valueToString(bytesBuffer, colored, receiver.Key, durationToString(receiver.Value.(time.Duration)))
+	case DurationsType:
+		sliceToString(bytesBuffer, colored, depth, receiver.Key, receiver.Value.([]time.Duration))
+	case IntType:
+		valueToString(bytesBuffer, colored, receiver.Key, strconv.Itoa(receiver.Value.(int)))
+	case IntsType:
+		sliceToString(bytesBuffer, colored, depth, receiver.Key, receiver.Value.([]int))
+	case Int64Type:
+		valueToString(bytesBuffer, colored, receiver.Key, strconv.FormatInt(receiver.Value.(int64), ten))
+	case Int64sType:
+		sliceToString(bytesBuffer, colored, depth, receiver.Key, receiver.Value.([]int64))
+	case Uint64Type:
+		valueToString(bytesBuffer, colored, receiver.Key, strconv.FormatUint(receiver.Value.(uint64), ten))
+	case Uint64sType:
+		sliceToString(bytesBuffer, colored, depth, receiver.Key, receiver.Value.([]uint64))
+	case Float64Type:
+		valueToString(
+			bytesBuffer, colored, receiver.Key, strconv.FormatFloat(receiver.Value.(float64), 'f', -1, sixtyFour),
+		)
+	case Float64sType:
+		sliceToString(bytesBuffer, colored, depth, receiver.Key, receiver.Value.([]float64))
+	case StringType:
+		valueToString(bytesBuffer, colored, receiver.Key, receiver.Value.(string))
+	case StringsType:
+		sliceToString(bytesBuffer, colored, depth, receiver.Key, receiver.Value.([]string))
+	case IPType:
+		valueToString(bytesBuffer, colored, receiver.Key, ipToString(receiver.Value.(net.IP)))
+	case URLType:
+		valueToString(bytesBuffer, colored, receiver.Key, urlToString(receiver.Value.(*url.URL)))
+	case JSONRawType:
+		valueToString(bytesBuffer, colored, receiver.Key, jsonRawToString(receiver.Value.(json.RawMessage)))
+	case LogValuerType:
+		valueToString(bytesBuffer, colored, receiver.Key, stringerToString(receiver.Value.(fmt.Stringer)))
+	case RuneType:
+		valueToString(bytesBuffer, colored, receiver.Key, runeToString(receiver.Value.(rune)))
+	case Complex128Type:
+		valueToString(bytesBuffer, colored, receiver.Key, complexToString(receiver.Value.(complex128)))
+	default:
+		valueToString(bytesBuffer, colored, receiver.Key, fmt.Sprintf(verboseFormat, receiver.Value))
+	}
+}
+
+// valueToString writes a key-value pair to the provided bytes.Buffer.
+//
+// Parameters:
+//
+//	bytesBuffer - the bytes.Buffer to write to
+//	key - the key of the key-value pair
+//	value - the value of the key-value pair
+//
+// Returns: A key-value pair is written to the provided bytes.Buffer.
+func valueToString(bytesBuffer *bytes.Buffer, colored bool, key, value string) {
+	bytesBuffer.WriteString(parenthesisOpen)
+	colorToString(bytesBuffer, colored, colorCyan, key)
+	bytesBuffer.WriteString(equals)
+
+	if value == nilValue {
+		colorToString(bytesBuffer, colored, colorRed, value)
+	} else {
+		bytesBuffer.WriteString(value)
+	}
+
+	bytesBuffer.WriteString(parenthesisClose)
+}
+
+// messageToString writes a message key-value pair to the provided bytes.Buffer.
+//
+// Parameters:
+//
+//	bytesBuffer - the bytes.Buffer to write to
+//	colored - whether ANSI color codes are written
+//	message - the message to be written
+//
+// Returns: A message key-value pair is written to the provided bytes.Buffer,
+// with the message in bold when colored is true and the message is not nilValue.
+func messageToString(bytesBuffer *bytes.Buffer, colored bool, message string) {
+	if message == nilValue {
+		valueToString(bytesBuffer, colored, messageKey, message)
+
+		return
+	}
+
+	bytesBuffer.WriteString(parenthesisOpen)
+	colorToString(bytesBuffer, colored, colorCyan, messageKey)
+	bytesBuffer.WriteString(equals)
+	colorToString(bytesBuffer, colored, colorBold, message)
+	bytesBuffer.WriteString(parenthesisClose)
+}
+
+// colorToString writes a value to the provided bytes.Buffer,
+// wrapped in the given ANSI color code when colored is true.
+//
+// Parameters:
+//
+//	bytesBuffer - the bytes.Buffer to write to
+//	colored - whether ANSI color codes are written
+//	color - the ANSI color code to wrap the value in
+//	value - the value to be written
+//
+// Returns: A value is written to the provided bytes.Buffer.
+func colorToString(bytesBuffer *bytes.Buffer, colored bool, color, value string) {
+	if !colored {
+		bytesBuffer.WriteString(value)
+
+		return
+	}
+
+	bytesBuffer.WriteString(color)
+	bytesBuffer.WriteString(value)
+	bytesBuffer.WriteString(colorReset)
+}
+
+// errorToString writes an error to the provided bytes.Buffer.
+//
+// Parameters:
+//
+//	bytesBuffer - the bytes.Buffer to write to
+//	depth - the depth to which the error is marshaled
+//	err - the error to be written
+//
+// Returns: An error is written to the provided bytes.Buffer.
+//
+// The function writes a key-value pair to the provided bytes.Buffer.
+// If err is nil, the function writes a key-value pair with the key "message" and the value "nil".
+// If err is a StructuredError, the function writes a key-value pair with the same fields as the StructuredError.
+// If err is not a StructuredError, the function writes a key-value pair with the key "message"
+// and the value of the error's Error() method.
+func errorToString(bytesBuffer *bytes.Buffer, colored bool, depth int, err error) {
+	var value *StructuredError
+	switch {
+	case err == nil:
+		messageToString(bytesBuffer, colored, nilValue)
+	case stderrors.As(err, &value):
+		value.asString(bytesBuffer, colored, depth)
+	default:
+		errStr := strings.TrimSpace(err.Error())
+		messageToString(bytesBuffer, colored, cmpOr(errStr, nilValue))
+	}
+}
+
+// objectToString writes an object to the provided bytes.Buffer.
+//
+// Parameters:
+//
+//	bytesBuffer - the bytes.Buffer to write to
+//	depth - the depth to which the object is marshaled
+//	key - the key of the key-value pair
+//	object - the object to be written
+//
+// Returns: An object is written to the provided bytes.Buffer.
+//
+// The function writes a key-value pair to the provided bytes.Buffer.
+// If object is nil, the function writes a key-value pair with the key "message" and the value "nil".
+// If object is a slice of Attr, the function writes a key-value pair with the same fields as the slice of Attr.
+func objectToString(bytesBuffer *bytes.Buffer, colored bool, depth int, key string, object []Attr) {
+	valuesToString(bytesBuffer, colored, depth, key, object, curlyOpen, curlyClose)
+}
+
+// sliceToString writes a slice to the provided bytes.Buffer.
+//
+// Parameters:
+//
+//	bytesBuffer - the bytes.Buffer to write to
+//	depth - the depth to which the slice is marshaled
+//	key - the key of the key-value pair
+//	slice - the slice to be written
+//
+// Returns: A slice is written to the provided bytes.Buffer.
+//
+// The function writes a key-value pair to the provided bytes.Buffer.
+// If slice is empty, the function writes nothing.
+// If slice is not empty, the function writes a key-value pair with the same fields as the slice.
+func sliceToString[T any](bytesBuffer *bytes.Buffer, colored bool, depth int, key string, slice []T) {
+	valuesToString(bytesBuffer, colored, depth, key, slice, bracketOpen, bracketClose)
+}
+
+// valuesToString writes a slice to the provided bytes.Buffer.
+//
+// Parameters:
+//
+//	bytesBuffer - the bytes.Buffer to write to
+//	depth - the depth to which the slice is marshaled
+//	key - the key of the key-value pair
+//	slice - the slice to be written
+//	opener - the opening string to write
+//	closer - the closing string to write
+//
+// Returns: A slice is written to the provided bytes.Buffer.
+//
+// The function writes a key-value pair to the provided bytes.Buffer.
+// If slice is empty, the function writes nothing.
+// If slice is not empty, the function writes a key-value pair with the same fields as the slice.
+func valuesToString[T any](
+	bytesBuffer *bytes.Buffer, colored bool, depth int, key string, slice []T, opener, closer string,
+) {
+	bytesBuffer.WriteString(parenthesisOpen)
+	colorToString(bytesBuffer, colored, colorCyan, key)
+	bytesBuffer.WriteString(equals)
+	bytesBuffer.WriteString(opener)
+
+	if len(slice) == zero {
+		bytesBuffer.WriteString(closer)
+		bytesBuffer.WriteString(parenthesisClose)
+
+		return
+	}
+
+	depth++
+
+	if stringMaxDepth > zero && depth > stringMaxDepth {
+		bytesBuffer.WriteString(truncatedMarker)
+		bytesBuffer.WriteString(closer)
+		bytesBuffer.WriteString(parenthesisClose)
+
+		return
+	}
+
+	bytesBuffer.WriteString(newLine)
+
+	switch values := any(slice).(type) {
+	case []Attr:
+		for index, value := range values {
+			if index > zero {
+				bytesBuffer.WriteString(comma)
+				bytesBuffer.WriteString(newLine)
+			}
+
+			tabToString(bytesBuffer, depth)
+			value.asString(bytesBuffer, colored, depth)
+		}
+	case []error:
+		for index, value := range values {
+			if index > zero {
+				bytesBuffer.WriteString(comma)
+				bytesBuffer.WriteString(newLine)
+			}
+
+			tabToString(bytesBuffer, depth)
+			errorToString(bytesBuffer, colored, depth, value)
+		}
+	case []bool:
+		for index, value := range values {
+			if index > zero {
+				bytesBuffer.WriteString(comma)
+				bytesBuffer.WriteString(newLine)
+			}
+
+			tabToString(bytesBuffer, depth)
+			bytesBuffer.WriteString(strconv.FormatBool(value))
+		}
+	case []time.Time:
+		for index, value := range values {
+			if index > zero {
+				bytesBuffer.WriteString(comma)
+				bytesBuffer.WriteString(newLine)
+			}
+
+			tabToString(bytesBuffer, depth)
+			bytesBuffer.WriteString(timeToString(value))
+		}
+	case []time.Duration:
+		for index, value := range values {
+			if index > zero {
+				bytesBuffer.WriteString(comma)
+				bytesBuffer.WriteString(newLine)
+			}
+
+			tabToString(bytesBuffer, depth)
+			bytesBuffer.WriteString(durationToString(value))
+		}
+	case []int:
+		for index, value := range values {
+			if index > zero {
+				bytesBuffer.WriteString(comma)
+				bytesBuffer.WriteString(newLine)
+			}
+
+			tabToString(bytesBuffer, depth)
+			bytesBuffer.WriteString(strconv.Itoa(value))
+		}
+	case []int64:
+		for index, value := range values {
+			if index > zero {
+				bytesBuffer.WriteString(comma)
+				bytesBuffer.WriteString(newLine)
+			}
+
+			tabToString(bytesBuffer, depth)
+			bytesBuffer.WriteString(strconv.FormatInt(value, ten))
+		}
+	case []uint64:
+		for index, value := range values {
+			if index > zero {
+				bytesBuffer.WriteString(comma)
+				bytesBuffer.WriteString(newLine)
+			}
+
+			tabToString(bytesBuffer, depth)
+			bytesBuffer.WriteString(strconv.FormatUint(value, ten))
+		}
+	case []float64:
+		for index, value := range values {
+			if index > zero {
+				bytesBuffer.WriteString(comma)
+				bytesBuffer.WriteString(newLine)
+			}
+
+			tabToString(bytesBuffer, depth)
+			bytesBuffer.WriteString(strconv.FormatFloat(value, 'f', -1, sixtyFour))
+		}
+	case []string:
+		for index, value := range values {
+			if index > zero {
+				bytesBuffer.WriteString(comma)
+				bytesBuffer.WriteString(newLine)
+			}
+
+			tabToString(bytesBuffer, depth)
+			bytesBuffer.WriteString(strings.TrimSpace(value))
+		}
+	default:
+		for index, value := range slice {
+			if index > zero {
+				bytesBuffer.WriteString(comma)
+				bytesBuffer.WriteString(newLine)
+			}
+
+			tabToString(bytesBuffer, depth)
+			_, _ = fmt.Fprintf(bytesBuffer, verboseFormat, value)
+		}
+	}
+
+	bytesBuffer.WriteString(newLine)
+	tabToString(bytesBuffer, depth-1)
+	bytesBuffer.WriteString(closer)
+	bytesBuffer.WriteString(parenthesisClose)
+}
+
+// timeToString returns the given time formatted with the layout set via SetTimeFormat.
+// Unlike time.Time.String(), it never writes the monotonic clock reading.
+func timeToString(value time.Time) string {
+	return value.Format(timeFormat)
+}
+
+// durationToString returns the given duration formatted as set via SetDurationFormat.
+func durationToString(value time.Duration) string {
+	if durationFormat == DurationAsSeconds {
+		return strconv.FormatFloat(value.Seconds(), 'f', -1, sixtyFour)
+	}
+
+	return value.String()
+}
+
+// tabToString writes depth number of tabs to the provided bytes.Buffer.
+//
+// Parameters:
+//
+//	bytesBuffer - the bytes.Buffer to write to
+//	depth - the number of tabs to write
+//
+// Returns: depth number of tabs are written to the provided bytes.Buffer.
+func tabToString(bytesBuffer *bytes.Buffer, depth int) {
+	for i := zero; i < depth; i++ {
+		bytesBuffer.WriteString(tab)
+	}
+}
@@ -0,0 +1,538 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+)
+
+//nolint:gochecknoglobals,varnamelen // these are just aliases for the std errors package
+var (
+	// Unwrap returns the result of calling the Unwrap method on err, if err's
+	// type contains an Unwrap method returning error.
+	// Otherwise, Unwrap returns nil.
+	//
+	// Unwrap only calls a method of the form "Unwrap() error".
+	// In particular Unwrap does not unwrap errors returned by [Join] or [JoinIf].
+	Unwrap = stderrors.Unwrap
+
+	// Is reports whether any error in err's tree matches target.
+	//
+	// The tree consists of err itself, followed by the errors obtained by repeatedly
+	// calling its Unwrap() error or Unwrap() []error method. When err wraps multiple
+	// errors, Is examines err followed by a depth-first traversal of its children.
+	//
+	// An error is considered to match a target if it is equal to that target or if
+	// it implements a method Is(error) bool such that Is(target) returns true.
+	//
+	// An error type might provide an Is method so it can be treated as equivalent
+	// to an existing error. For example, if MyError defines
+	//
+	//	func (m MyError) Is(target error) bool { return target == fs.ErrExist }
+	//
+	// then Is(MyError{}, fs.ErrExist) returns true. See [syscall.Errno.Is] for
+	// an example in the standard library. An Is method should only shallowly
+	// compare err and the target and not call [Unwrap] on either.
+	Is = stderrors.Is
+
+	// As finds the first error in err's tree that matches target, and if one is found, sets
+	// target to that error value and returns true. Otherwise, it returns false.
+	//
+	// The tree consists of err itself, followed by the errors obtained by repeatedly
+	// calling its Unwrap() error or Unwrap() []error method. When err wraps multiple
+	// errors, As examines err followed by a depth-first traversal of its children.
+	//
+	// An error matches target if the error's concrete value is assignable to the value
+	// pointed to by target, or if the error has a method As(any) bool such that
+	// As(target) returns true. In the latter case, the As method is responsible for
+	// setting target.
+	//
+	// An error type might provide an As method so it can be treated as if it were a
+	// different error type.
+	//
+	// As panics if target is not a non-nil pointer to either a type that implements
+	// error, or to any interface type.
+	As = stderrors.As
+)
+
+const (
+	// timeoutTag is the tag added by ClassifyContext to errors matching context.DeadlineExceeded.
+	timeoutTag = "timeout"
+	// canceledTag is the tag added by ClassifyContext to errors matching context.Canceled.
+	canceledTag = "canceled"
+	// classifiedMessage is the message of the errors returned by ClassifyContext,
+	// so the message of the wrapped error is not repeated when marshaling.
+	classifiedMessage = "classified error"
+)
+
+// Wrapf returns a StructuredError with the message formatted according to a format specifier,
+// as fmt.Sprintf does, wrapping the given error.
+// If the given error is nil, Wrapf returns nil, like JoinIf it returns an error
+// so the result can be compared with nil.
+//
+// The %w verb is not treated specially, so it does not wrap its argument.
+func Wrapf(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	return Newf(format, args...).WithErrors(err)
+}
+
+// ClassifyContext returns a StructuredError with the message "classified error", wrapping the given error,
+// tagged "timeout" if the error matches context.DeadlineExceeded via Is, or "canceled" if it matches context.Canceled,
+// so retry logic only has to check the tags. Any other error is wrapped without those tags.
+// If the given error is nil, ClassifyContext returns nil.
+func ClassifyContext(err error) *StructuredError {
+	if err == nil {
+		return nil
+	}
+
+	classified := New(classifiedMessage).WithErrors(err)
+
+	switch {
+	case Is(err, context.DeadlineExceeded):
+		classified.WithTags(timeoutTag)
+	case Is(err, context.Canceled):
+		classified.WithTags(canceledTag)
+	}
+
+	return classified
+}
+
+// Is reports whether any error in StructuredError's chain matches target.
+// It first checks if the current error matches the target, then checks each error in the Errors slice.
+//
+// If the target is a *StructuredError with a non-empty Code, an error matches when it has the same Code,
+// regardless of its Message, Attrs or Tags. Otherwise, errors match by identity.
+func (receiver *StructuredError) Is(target error) bool {
+	if receiver == target {
+		return true
+	}
+
+	// Handle nil receiver
+	if receiver == nil {
+		return false
+	}
+
+	// Match by code when the target has one
+	structured, ok := target.(*StructuredError) //nolint:errorlint // the target itself is compared, not its chain
+	if ok && structured != nil && structured.Code != emptyString && receiver.Code == structured.Code {
+		return true
+	}
+
+	// Check each error in the chain
+	for _, err := range receiver.Errors {
+		if Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first error in StructuredError's chain that matches the target type,
+// and if one is found, sets the target to its value and returns true.
+func (receiver *StructuredError) As(target any) bool {
+	if receiver == nil {
+		return false
+	}
+
+	// Try to match the receiver itself first
+	if receiver == target {
+		return true
+	}
+
+	if as, ok := target.(*StructuredError); ok {
+		*as = *receiver
+
+		return true
+	}
+
+	// Check each error in the chain
+	for _, err := range receiver.Errors {
+		if As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CauseAt returns the child error at the given index, or nil if the index is out of range.
+//
+// The children are the ones shown when marshaling, where the errors of nested joined errors
+// are pulled up, and nested errors are normalized copies of the receiver's Errors.
+func (receiver *StructuredError) CauseAt(index int) error {
+	causes := receiver.causes()
+
+	if index < zero || index >= len(causes) {
+		return nil
+	}
+
+	return causes[index]
+}
+
+// RootCause returns the innermost error of the receiver's tree, following the first non-nil child
+// at every level, like the Cause function of github.com/pkg/errors does for a chain of wrapped errors.
+// Joined errors are followed through their first child as well.
+//
+// The children of a *StructuredError are its Errors, and the ones of any other error are given by its
+// Unwrap() error or Unwrap() []error method. RootCause returns the receiver itself if it has no children,
+// and nil if the receiver is nil.
+// Traversal stops at the depth set by SetMaxDepthMarshal.
+func (receiver *StructuredError) RootCause() error {
+	if receiver == nil {
+		return nil
+	}
+
+	var err error = receiver
+
+	for depth := zero; depth < maxDepthMarshal; depth++ {
+		child := firstChild(err)
+		if child == nil {
+			break
+		}
+
+		err = child
+	}
+
+	return err
+}
+
+// Cause returns the innermost error of the receiver's tree, like RootCause, so the receiver satisfies
+// the interface{ Cause() error } looked up by github.com/pkg/errors and error reporters like Sentry.
+//
+// Unlike RootCause, it returns nil instead of the receiver itself when the receiver has no children,
+// as those callers keep calling Cause on the result and would never stop on a leaf.
+func (receiver *StructuredError) Cause() error {
+	cause := receiver.RootCause()
+	if cause == error(receiver) {
+		return nil
+	}
+
+	return cause
+}
+
+// firstChild returns the first non-nil child of the given error, or nil if it has none.
+func firstChild(err error) error {
+	var children []error
+
+	switch value := err.(type) { //nolint:errorlint // the tree is traversed manually
+	case *StructuredError:
+		children = value.Errors
+	case MultiUnwrapper:
+		children = value.Unwrap()
+	case SingleUnwrapper:
+		children = []error{value.Unwrap()}
+	}
+
+	for _, child := range children {
+		structured, ok := child.(*StructuredError) //nolint:errorlint // only nil *StructuredError values are skipped
+		if child == nil || (ok && structured == nil) {
+			continue
+		}
+
+		return child
+	}
+
+	return nil
+}
+
+// CauseCount returns the number of child errors, as seen by CauseAt.
+func (receiver *StructuredError) CauseCount() int {
+	return len(receiver.causes())
+}
+
+// Depth returns how deeply nested the receiver's error tree is.
+//
+// A nil receiver has depth 0 and a receiver without Errors has depth 1.
+// Otherwise, the depth is 1 plus the maximum depth of its Errors, where a *StructuredError child
+// counts its own tree and any other error, including nil, counts as 1.
+// Counting stops at the depth set by SetMaxDepthMarshal.
+func (receiver *StructuredError) Depth() int {
+	return receiver.depth(zero)
+}
+
+// depth is the actual implementation for Depth.
+func (receiver *StructuredError) depth(level int) int {
+	if receiver == nil {
+		return zero
+	}
+
+	if level >= maxDepthMarshal {
+		return one
+	}
+
+	maxChild := zero
+
+	for _, err := range receiver.Errors {
+		childDepth := one
+
+		value, ok := err.(*StructuredError) //nolint:errorlint // wrapped errors count as 1, like any other error
+		if ok && value != nil {
+			childDepth = value.depth(level + one)
+		}
+
+		if childDepth > maxChild {
+			maxChild = childDepth
+		}
+	}
+
+	return one + maxChild
+}
+
+// LeafCount returns how many terminal errors the receiver's error tree has, like the underlying causes
+// of an aggregate error.
+//
+// A nil receiver has 0 leaves and a receiver without Errors is a leaf itself.
+// Otherwise, the leaves are the sum of the leaves of its Errors, where a *StructuredError child
+// counts its own tree, any other error counts as 1 and nil errors are skipped.
+// Counting stops at the depth set by SetMaxDepthMarshal.
+func (receiver *StructuredError) LeafCount() int {
+	return receiver.leafCount(zero)
+}
+
+// leafCount is the actual implementation for LeafCount.
+func (receiver *StructuredError) leafCount(level int) int {
+	if receiver == nil {
+		return zero
+	}
+
+	if len(receiver.Errors) == zero || level >= maxDepthMarshal {
+		return one
+	}
+
+	count := zero
+
+	for _, err := range receiver.Errors {
+		if err == nil {
+			continue
+		}
+
+		value, ok := err.(*StructuredError) //nolint:errorlint // wrapped errors count as 1, like any other error
+		if ok && value != nil {
+			count += value.leafCount(level + one)
+		} else {
+			count++
+		}
+	}
+
+	return count
+}
+
+// causes returns the normalized child errors of the receiver.
+func (receiver *StructuredError) causes() []error {
+	if receiver == nil || len(receiver.Errors) == zero {
+		return nil
+	}
+
+	return receiver.normalizedErrors()
+}
+
+// FindByTag returns the first *StructuredError in err's tree that has the given tag, and whether it was found.
+//
+// The tree is traversed depth-first, like Is, following the Errors of every *StructuredError
+// and the Unwrap() error or Unwrap() []error method of any other error.
+// Errors that are not a *StructuredError are traversed but never match.
+// Traversal stops at the depth set by SetMaxDepthMarshal.
+func FindByTag(err error, tag string) (*StructuredError, bool) {
+	return findByTag(zero, err, tag)
+}
+
+// AsTagged finds the first *StructuredError in err's tree that has the given tag, like FindByTag,
+// and if one is found, sets target to it and returns true. Otherwise, it returns false
+// and leaves target unchanged.
+//
+// It complements As for when the tag, and not the type, identifies the error to extract.
+// AsTagged panics if target is nil.
+func AsTagged(err error, tag string, target **StructuredError) bool {
+	if target == nil {
+		panic("errors: target cannot be nil")
+	}
+
+	found, ok := findByTag(zero, err, tag)
+	if ok {
+		*target = found
+	}
+
+	return ok
+}
+
+// IsMessage reports whether any error in err's tree has the given message, both trimmed of whitespace.
+// It is an opt-in escape hatch for libraries that only expose their errors by message text,
+// and it is unrelated to Is, which never compares messages.
+//
+// The tree is traversed like FindByTag does. The message of a *StructuredError is its Message field,
+// and the message of any other error is the result of its Error method.
+// Traversal stops at the depth set by SetMaxDepthMarshal.
+func IsMessage(err error, message string) bool {
+	return isMessage(zero, err, strings.TrimSpace(message))
+}
+
+// isMessage is the actual implementation for IsMessage.
+func isMessage(depth int, err error, message string) bool {
+	if err == nil || depth > maxDepthMarshal {
+		return false
+	}
+
+	var children []error
+
+	switch value := err.(type) { //nolint:errorlint // the tree is traversed manually
+	case *StructuredError:
+		if value == nil {
+			return false
+		}
+
+		if strings.TrimSpace(value.Message) == message {
+			return true
+		}
+
+		children = value.Errors
+	case MultiUnwrapper:
+		if strings.TrimSpace(err.Error()) == message {
+			return true
+		}
+
+		children = value.Unwrap()
+	case SingleUnwrapper:
+		if strings.TrimSpace(err.Error()) == message {
+			return true
+		}
+
+		children = []error{value.Unwrap()}
+	default:
+		return strings.TrimSpace(err.Error()) == message
+	}
+
+	for _, child := range children {
+		if isMessage(depth+one, child, message) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HTTPStatus returns the first non-zero HTTP status set via WithHTTPStatus in err's tree, and whether one was found.
+//
+// The tree is traversed depth-first like FindByTag does, so the status of an error takes precedence
+// over the statuses of its children, and earlier children over later ones.
+// Traversal stops at the depth set by SetMaxDepthMarshal.
+func HTTPStatus(err error) (int, bool) {
+	return httpStatus(zero, err)
+}
+
+// httpStatus is the actual implementation for HTTPStatus.
+func httpStatus(depth int, err error) (int, bool) {
+	if err == nil || depth > maxDepthMarshal {
+		return zero, false
+	}
+
+	var children []error
+
+	switch value := err.(type) { //nolint:errorlint // the tree is traversed manually
+	case *StructuredError:
+		if value == nil {
+			return zero, false
+		}
+
+		if value.HTTPStatus != zero {
+			return value.HTTPStatus, true
+		}
+
+		children = value.Errors
+	case MultiUnwrapper:
+		children = value.Unwrap()
+	case SingleUnwrapper:
+		children = []error{value.Unwrap()}
+	default:
+		return zero, false
+	}
+
+	for _, child := range children {
+		if status, ok := httpStatus(depth+one, child); ok {
+			return status, true
+		}
+	}
+
+	return zero, false
+}
+
+// Structure converts any error into a *StructuredError, to log arbitrary errors consistently.
+//
+// Structure returns nil if err is nil.
+// If err is, or wraps, a *StructuredError, it returns the first one found via As.
+// If err implements the Unwrap() []error method, like the errors returned by errors.Join,
+// it returns a joined *StructuredError, like Join does, with every wrapped error converted by Structure.
+// Otherwise, it returns a *StructuredError with the message of err.
+func Structure(err error) *StructuredError {
+	return structure(zero, err)
+}
+
+// structure is the actual implementation for Structure.
+// Errors nested deeper than the depth set by SetMaxDepthMarshal are converted by their message.
+func structure(depth int, err error) *StructuredError {
+	if err == nil {
+		return nil
+	}
+
+	if value, ok := err.(*StructuredError); ok { //nolint:errorlint // the wrapped errors are handled below
+		return value
+	}
+
+	if value, ok := err.(MultiUnwrapper); ok && depth < maxDepthMarshal { //nolint:errorlint // only err itself
+		joined := &StructuredError{
+			joined: true,
+		}
+
+		for _, child := range value.Unwrap() {
+			if child != nil {
+				joined.Errors = append(joined.Errors, structure(depth+one, child))
+			}
+		}
+
+		return joined
+	}
+
+	var value *StructuredError
+	if stderrors.As(err, &value) {
+		return value
+	}
+
+	return New(err.Error())
+}
+
+// findByTag is the actual implementation for FindByTag and AsTagged.
+func findByTag(depth int, err error, tag string) (*StructuredError, bool) {
+	if err == nil || depth > maxDepthMarshal {
+		return nil, false
+	}
+
+	var children []error
+
+	switch value := err.(type) { //nolint:errorlint // the tree is traversed manually
+	case *StructuredError:
+		if value == nil {
+			return nil, false
+		}
+
+		if containsTag(value.Tags, tag) {
+			return value, true
+		}
+
+		children = value.Errors
+	case MultiUnwrapper:
+		children = value.Unwrap()
+	case SingleUnwrapper:
+		children = []error{value.Unwrap()}
+	}
+
+	for _, child := range children {
+		if found, ok := findByTag(depth+one, child, tag); ok {
+			return found, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,850 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// MultiUnwrapper represents errors that unwrap to multiple underlying errors.
+	MultiUnwrapper interface {
+		Unwrap() []error
+	}
+
+	// SingleUnwrapper represents errors that unwrap to a single underlying error.
+	SingleUnwrapper interface {
+		Unwrap() error
+	}
+
+	// StructuredError represents an error with structured metadata including attributes,
+	// nested errors, tags, and optional stack traces.
+	StructuredError struct {
+		// Message is the primary error message.
+		// It is the only required field.
+		// If empty, the error is considered nil with and labeled with "!NILVALUE"
+		Message string `json:"message,omitempty"`
+
+		// Code is a machine-readable identifier for the error, like "NOT_FOUND".
+		// It is optional.
+		// If not empty, StructuredError.Is matches errors by Code instead of by identity.
+		Code string `json:"code,omitempty"`
+
+		// HTTPStatus is the HTTP status code of the error, like 404, read back with the HTTPStatus function.
+		// It is optional.
+		// If zero, it will not be marshaled.
+		HTTPStatus int `json:"http_status,omitempty"`
+
+		// Attrs contains key-value pairs providing additional context.
+		// It is optional.
+		// If empty, or nil, it will be marshaled as "[]"
+		Attrs []Attr `json:"attrs,omitempty"`
+
+		// Errors contains wrapped underlying errors.
+		// It is optional.
+		// If empty, or nil, it will be marshaled as "[]"
+		Errors []error `json:"errors,omitempty"`
+
+		// Tags contains categorical labels for error classification.
+		// It is optional.
+		// If empty, or nil, it will be marshaled as "[]"
+		Tags []string `json:"tags,omitempty"`
+
+		// Stack contains the stack trace bytes, typically from a panic recovery.
+		// It is optional.
+		// If empty, or nil, it will be marshaled as "[]"
+		Stack []byte `json:"stack,omitempty"`
+
+		// frames contains the parsed stack trace, set via WithParsedStack or CaptureStack.
+		frames []StackFrame
+
+		// pcs contains the program counters of the stack trace, set via CaptureStack.
+		pcs []uintptr
+
+		// caller is the "file:line" of the call site, set via WithCaller or WithCallerSkip.
+		caller string
+
+		// joined indicates whether this error was created via Join or JoinIf.
+		joined bool
+	}
+
+	// StackFrame represents a single frame of a parsed stack trace.
+	StackFrame struct {
+		// Function is the fully qualified function name, without arguments.
+		Function string `json:"function"`
+
+		// File is the absolute path of the source file.
+		File string `json:"file"`
+
+		// Line is the line number in File.
+		Line int `json:"line"`
+	}
+
+	// SafeError guards a StructuredError with a mutex, so it can be enriched from multiple goroutines,
+	// like a background aggregator collecting the failures of its workers.
+	//
+	// The builder methods of StructuredError mutate its slices in place and are not safe for concurrent use,
+	// so every access to the guarded error must go through the SafeError.
+	// The zero value is not usable, use NewSafe instead.
+	SafeError struct {
+		mu  sync.Mutex
+		err *StructuredError
+	}
+
+	// Registry holds the known error codes of an API with their default messages,
+	// so every code is declared once and the errors carrying it are built from the registry.
+	//
+	// It is safe for concurrent use. The zero value is not usable, use NewRegistry instead.
+	Registry struct {
+		mu       sync.RWMutex
+		messages map[string]string
+	}
+)
+
+//nolint:gochecknoglobals // needed to avoid use of specific instance of StructuredError
+var (
+	contextExtractor func(ctx context.Context) []Attr
+	hiddenTags       = make(map[string]struct{})
+
+	structuredErrorPool = sync.Pool{
+		New: func() any {
+			return &StructuredError{}
+		},
+	}
+)
+
+const (
+	// Version is the version of the errors package.
+	Version = "0.0.1"
+
+	// TraceIDKey is the key of the Attr set by WithTraceID and PropagateTraceID.
+	TraceIDKey = "trace_id"
+)
+
+//nolint:errcheck // this is for interface assertion
+var (
+	_ error        = (*StructuredError)(nil)
+	_ fmt.Stringer = (*StructuredError)(nil)
+	_ error        = (*SafeError)(nil)
+)
+
+// New creates a StructuredError with the specified message.
+// All other fields (Attrs, Errors, Tags, Stack) are initialized as empty.
+// The message is trimmed of leading and trailing whitespace after SetTrimMessages(true).
+func New(message string) *StructuredError {
+	return &StructuredError{Message: trimmedMessage(message)}
+}
+
+// Newf creates a StructuredError with the message formatted according to a format specifier, as fmt.Sprintf does.
+// The %w verb is not treated specially, so it does not wrap its argument.
+func Newf(format string, args ...any) *StructuredError {
+	return New(fmt.Sprintf(format, args...))
+}
+
+// NewPooled is similar to New, but it takes the StructuredError from a pool instead of allocating it.
+//
+// It is meant for hot paths where errors are logged and discarded right away.
+// Call Release once the error is no longer used, and do not retain a pooled error,
+// or any error wrapping it, after logging it, since it will be reset and reused.
+func NewPooled(message string) *StructuredError {
+	err := structuredErrorPool.Get().(*StructuredError) //nolint:forcetypeassert,errcheck // the pool only holds *StructuredError
+
+	err.Message = trimmedMessage(message)
+
+	return err
+}
+
+// Release resets the given StructuredError to its zero value and returns it to the pool used by NewPooled.
+// The error must not be used after calling Release.
+// If the error is nil, Release does nothing.
+func Release(err *StructuredError) {
+	if err == nil {
+		return
+	}
+
+	*err = StructuredError{}
+
+	structuredErrorPool.Put(err)
+}
+
+// WithCode sets the code on the receiver and returns it for chaining.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithCode(code string) *StructuredError {
+	receiver.Code = code
+
+	return receiver
+}
+
+// WithHTTPStatus sets the HTTP status code on the receiver and returns it for chaining.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithHTTPStatus(code int) *StructuredError {
+	receiver.HTTPStatus = code
+
+	return receiver
+}
+
+// WithAttrs assigns the given attributes to the receiver and returns it for chaining.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithAttrs(attrs ...Attr) *StructuredError {
+	receiver.Attrs = attrs
+
+	return receiver
+}
+
+// WithContext appends attributes read from the given context to the receiver and returns it for chaining.
+//
+// The attributes returned by the extractor set via SetContextExtractor are appended first,
+// then an attribute for the value of each given key. Its key is the key formatted with fmt.Sprint,
+// and its Type matches the concrete type of the value, like Int64Type for an int64.
+//
+// A nil context, and keys without a value in the context, are skipped.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithContext(ctx context.Context, keys ...any) *StructuredError {
+	if ctx == nil {
+		return receiver
+	}
+
+	if contextExtractor != nil {
+		receiver.Attrs = append(receiver.Attrs, contextExtractor(ctx)...)
+	}
+
+	for _, key := range keys {
+		value := ctx.Value(key)
+		if value == nil {
+			continue
+		}
+
+		receiver.Attrs = append(receiver.Attrs, attrOf(fmt.Sprint(key), value))
+	}
+
+	return receiver
+}
+
+// SetContextExtractor sets a function that WithContext calls to read well-known values,
+// like request or trace IDs, from every context it receives.
+// A nil extractor disables it, which is the default.
+//
+// SetContextExtractor is not thread-safe. It should be called before any
+// StructuredError is created.
+func SetContextExtractor(extractor func(ctx context.Context) []Attr) {
+	contextExtractor = extractor
+}
+
+// WithTags prepends the given tags to the receiver and returns it for chaining.
+// Tags already present in the receiver, or repeated in the given tags, are skipped,
+// so the receiver's tags keep their first-seen order and never hold duplicates.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithTags(tags ...string) *StructuredError {
+	result := make([]string, zero, len(tags)+len(receiver.Tags))
+
+	for _, tag := range tags {
+		if containsTag(result, tag) || containsTag(receiver.Tags, tag) {
+			continue
+		}
+
+		result = append(result, tag)
+	}
+
+	if len(result) == zero {
+		return receiver
+	}
+
+	receiver.Tags = append(result, receiver.Tags...)
+
+	return receiver
+}
+
+// WithTagsIf calls WithTags with the given tags only when cond is true,
+// otherwise it returns the receiver unchanged. It is meant for fluent chains.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithTagsIf(cond bool, tags ...string) *StructuredError {
+	if !cond {
+		return receiver
+	}
+
+	return receiver.WithTags(tags...)
+}
+
+// WithAttrsIf calls WithAttrs with the given attributes only when cond is true,
+// otherwise it returns the receiver unchanged. It is meant for fluent chains.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithAttrsIf(cond bool, attrs ...Attr) *StructuredError {
+	if !cond {
+		return receiver
+	}
+
+	return receiver.WithAttrs(attrs...)
+}
+
+// WithAttrsMap appends an attribute for each entry of the given map to the receiver and returns it for chaining.
+//
+// The entries are appended sorted by key, so the output is deterministic.
+// The Type of each attribute matches the concrete type of its value, like IntType for an int,
+// and values of types without a specific helper result in AnyType attributes.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithAttrsMap(attrs map[string]any) *StructuredError {
+	if len(attrs) == zero {
+		return receiver
+	}
+
+	keys := make([]string, zero, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		receiver.Attrs = append(receiver.Attrs, attrOf(key, attrs[key]))
+	}
+
+	return receiver
+}
+
+// WithTraceID sets the given correlation or trace ID as the TraceIDKey Attr of the receiver
+// and returns it for chaining. An existing TraceIDKey Attr is replaced, other attrs are kept.
+//
+// Call PropagateTraceID to stamp the ID onto the children of the receiver too.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithTraceID(id string) *StructuredError {
+	for index := range receiver.Attrs {
+		if receiver.Attrs[index].Key == TraceIDKey {
+			receiver.Attrs[index] = String(TraceIDKey, id)
+
+			return receiver
+		}
+	}
+
+	receiver.Attrs = append(receiver.Attrs, String(TraceIDKey, id))
+
+	return receiver
+}
+
+// PropagateTraceID stamps the trace ID of the receiver, set via WithTraceID, onto every *StructuredError
+// of its tree that lacks one, including the ones wrapped by other errors, and returns the receiver for chaining.
+//
+// A child that already has a trace ID keeps it, and its own children inherit that one instead.
+// Traversal stops at the depth set by SetMaxDepthMarshal.
+// This method mutates the receiver and its children in place.
+func (receiver *StructuredError) PropagateTraceID() *StructuredError {
+	if receiver == nil {
+		return nil
+	}
+
+	attr, ok := receiver.GetAttr(TraceIDKey)
+	if !ok {
+		return receiver
+	}
+
+	for _, err := range receiver.Errors {
+		propagateTraceID(one, err, attr)
+	}
+
+	return receiver
+}
+
+// propagateTraceID is the actual implementation for PropagateTraceID.
+func propagateTraceID(depth int, err error, traceID Attr) {
+	if err == nil || depth > maxDepthMarshal {
+		return
+	}
+
+	var children []error
+
+	switch value := err.(type) { //nolint:errorlint // the tree is traversed manually
+	case *StructuredError:
+		if value == nil {
+			return
+		}
+
+		if attr, ok := value.GetAttr(TraceIDKey); ok {
+			traceID = attr
+		} else {
+			value.Attrs = append(value.Attrs, traceID)
+		}
+
+		children = value.Errors
+	case MultiUnwrapper:
+		children = value.Unwrap()
+	case SingleUnwrapper:
+		children = []error{value.Unwrap()}
+	default:
+		return
+	}
+
+	for _, child := range children {
+		propagateTraceID(depth+one, child, traceID)
+	}
+}
+
+// containsTag reports whether tag is in tags.
+// A linear search is used, since errors usually carry only a few tags.
+func containsTag(tags []string, tag string) bool {
+	for _, value := range tags {
+		if value == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithErrors assigns the given errors to the receiver and returns it for chaining.
+func (receiver *StructuredError) WithErrors(errors ...error) *StructuredError {
+	receiver.Errors = errors
+
+	return receiver
+}
+
+// WithStack sets the stack trace on the receiver and returns it for chaining.
+// This is typically used when recovering from a panic to preserve the stack trace.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) WithStack(stack []byte) *StructuredError {
+	receiver.Stack = stack
+
+	return receiver
+}
+
+// PrependErrors adds the given errors before the receiver's existing errors and returns it for chaining.
+// Nil errors are dropped, and the given errors keep their order.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) PrependErrors(errors ...error) *StructuredError {
+	errs := make([]error, zero, len(errors)+len(receiver.Errors))
+
+	for _, err := range errors {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == zero {
+		return receiver
+	}
+
+	receiver.Errors = append(errs, receiver.Errors...)
+
+	return receiver
+}
+
+// AppendErrors adds the given errors after the receiver's existing errors and returns it for chaining.
+// This method mutates the receiver in place.
+func (receiver *StructuredError) AppendErrors(errors ...error) *StructuredError {
+	receiver.Errors = append(receiver.Errors, errors...)
+
+	return receiver
+}
+
+// Unwrap returns the wrapped errors, implementing the MultiUnwrapper interface.
+// This allows StructuredError to work with errors.Is and errors.As.
+// The errors are returned whether the receiver was joined or not, and it returns nil
+// if the receiver is nil or has no errors. There is no Unwrap() error form, so the
+// standard library errors.Unwrap always returns nil for a StructuredError.
+func (receiver *StructuredError) Unwrap() []error {
+	if receiver == nil || len(receiver.Errors) == zero {
+		return nil
+	}
+
+	return receiver.Errors
+}
+
+// GetAttr returns the first Attr of the receiver with the given key, and whether it was found.
+// The returned Attr keeps its raw value, even if it is redacted when marshaled.
+func (receiver *StructuredError) GetAttr(key string) (Attr, bool) {
+	if receiver == nil {
+		return Attr{}, false
+	}
+
+	for _, attr := range receiver.Attrs {
+		if attr.Key == key {
+			return attr, true
+		}
+	}
+
+	return Attr{}, false
+}
+
+// TagsCopy returns a copy of the receiver's tags, so they can be handed to other goroutines
+// or modified without changing the receiver. It returns nil if the receiver is nil or has no tags.
+func (receiver *StructuredError) TagsCopy() []string {
+	if receiver == nil || len(receiver.Tags) == zero {
+		return nil
+	}
+
+	tags := make([]string, len(receiver.Tags))
+	copy(tags, receiver.Tags)
+
+	return tags
+}
+
+// Clone returns a deep copy of the receiver.
+//
+// Tags, Attrs (including object and slice values), Stack and the parsed stack frames are copied,
+// and every *StructuredError in Errors is cloned recursively, so the builder methods can be used
+// on the copy without affecting the receiver. Other errors are kept as they are.
+//
+// If the receiver is nil, it returns nil.
+func (receiver *StructuredError) Clone() *StructuredError {
+	if receiver == nil {
+		return nil
+	}
+
+	clone := &StructuredError{
+		Message:    receiver.Message,
+		Code:       receiver.Code,
+		HTTPStatus: receiver.HTTPStatus,
+		Attrs:      cloneAttrs(receiver.Attrs),
+		Tags:       cloneSlice(receiver.Tags),
+		Stack:      cloneSlice(receiver.Stack),
+		frames:     cloneSlice(receiver.frames),
+		pcs:        cloneSlice(receiver.pcs),
+		caller:     receiver.caller,
+		joined:     receiver.joined,
+	}
+
+	if receiver.Errors != nil {
+		clone.Errors = make([]error, zero, len(receiver.Errors))
+
+		for _, err := range receiver.Errors {
+			if structured, ok := err.(*StructuredError); ok { //nolint:errorlint // only direct children are cloned
+				err = structured.Clone()
+			}
+
+			clone.Errors = append(clone.Errors, err)
+		}
+	}
+
+	return clone
+}
+
+// Sanitize returns a copy of the receiver that is safe to expose outside the process, like to an API client.
+//
+// The copy has no stack trace nor caller, and drops every sensitive Attr, created via Sensitive or with a key registered
+// via Redact, at any nesting level, and every tag registered via HideTag. Every *StructuredError in Errors
+// is sanitized recursively, other errors are kept as they are. The receiver is not modified.
+//
+// If the receiver is nil, it returns nil.
+func (receiver *StructuredError) Sanitize() *StructuredError {
+	return receiver.Clone().sanitize()
+}
+
+// sanitize is the actual implementation for Sanitize, stripping the receiver in place.
+// It must only be called on a clone.
+func (receiver *StructuredError) sanitize() *StructuredError {
+	if receiver == nil {
+		return nil
+	}
+
+	receiver.Stack = nil
+	receiver.frames = nil
+	receiver.pcs = nil
+	receiver.caller = emptyString
+	receiver.Attrs = withoutSensitiveAttrs(receiver.Attrs)
+	receiver.Tags = withoutHiddenTags(receiver.Tags)
+
+	for _, err := range receiver.Errors {
+		if structured, ok := err.(*StructuredError); ok { //nolint:errorlint // only direct children are sanitized
+			structured.sanitize()
+		}
+	}
+
+	return receiver
+}
+
+// HideTag registers the given tag as internal, so Sanitize drops it.
+// The tag is still marshaled as usual.
+//
+// HideTag is not thread-safe. It should be called before any
+// StructuredError is sanitized.
+func HideTag(tag string) {
+	hiddenTags[tag] = struct{}{}
+}
+
+// withoutHiddenTags removes the tags registered via HideTag from the given tags, in place.
+// It returns nil if no tag is left.
+func withoutHiddenTags(tags []string) []string {
+	result := tags[:zero]
+
+	for _, tag := range tags {
+		if _, hidden := hiddenTags[tag]; !hidden {
+			result = append(result, tag)
+		}
+	}
+
+	if len(result) == zero {
+		return nil
+	}
+
+	return result
+}
+
+// Equal reports whether the given errors are semantically equal, so they can be compared in tests
+// where reflect.DeepEqual is too strict.
+//
+// The Message, Code, HTTPStatus, Tags, Attrs and Errors are compared, the latter recursively for *StructuredError children
+// and by type and message for other errors. Times are compared with time.Time.Equal, so the monotonic clock
+// and the location are ignored, and IPs with net.IP.Equal. How the errors were built, like via Join,
+// and their stack traces are ignored.
+//
+// Two nil errors are equal, and a nil error is not equal to a non-nil one.
+// Tags must be in the same order, use EqualIgnoringTagOrder otherwise.
+func Equal(a, b *StructuredError) bool {
+	return equal(zero, a, b, false)
+}
+
+// EqualIgnoringTagOrder is similar to Equal, but the tags of each error may be in any order.
+func EqualIgnoringTagOrder(a, b *StructuredError) bool {
+	return equal(zero, a, b, true)
+}
+
+// equal is the actual implementation for Equal and EqualIgnoringTagOrder.
+// Errors nested deeper than maxDepthMarshal are not compared.
+func equal(depth int, a, b *StructuredError, ignoreTagOrder bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if depth > maxDepthMarshal {
+		return true
+	}
+
+	if a.Message != b.Message || a.Code != b.Code || a.HTTPStatus != b.HTTPStatus || !tagsEqual(a.Tags, b.Tags, ignoreTagOrder) ||
+		!attrsEqual(a.Attrs, b.Attrs) || len(a.Errors) != len(b.Errors) {
+		return false
+	}
+
+	for index := range a.Errors {
+		if !errorsEqual(depth+one, a.Errors[index], b.Errors[index], ignoreTagOrder) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// errorsEqual reports whether the given child errors are equal,
+// recursively for *StructuredError and by type and message otherwise.
+func errorsEqual(depth int, a, b error, ignoreTagOrder bool) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	structuredA, okA := a.(*StructuredError) //nolint:errorlint // only direct children are compared
+	structuredB, okB := b.(*StructuredError) //nolint:errorlint // only direct children are compared
+
+	if okA || okB {
+		return okA && okB && equal(depth, structuredA, structuredB, ignoreTagOrder)
+	}
+
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && a.Error() == b.Error()
+}
+
+// tagsEqual reports whether the given tags are equal, in any order if ignoreOrder is true.
+func tagsEqual(a, b []string, ignoreOrder bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	if !ignoreOrder {
+		return sliceEqual(a, b, func(x, y string) bool { return x == y })
+	}
+
+	counts := make(map[string]int, len(a))
+	for _, tag := range a {
+		counts[tag]++
+	}
+
+	for _, tag := range b {
+		counts[tag]--
+
+		if counts[tag] < zero {
+			return false
+		}
+	}
+
+	return true
+}
+
+// attrsEqual reports whether the given attrs have the same keys, types, sensitivity and values, in the same order.
+func attrsEqual(a, b []Attr) bool {
+	return sliceEqual(a, b, func(x, y Attr) bool {
+		return x.Key == y.Key && x.Type == y.Type && x.IsSensitive() == y.IsSensitive() && x.valueEqual(&y)
+	})
+}
+
+// valueEqual reports whether the values of the receiver and the given Attr, with the same Type, are equal.
+// Values that do not match their Type are compared with reflect.DeepEqual.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) valueEqual(other *Attr) bool {
+	if !receiver.valueMatchesType() || !other.valueMatchesType() {
+		return reflect.DeepEqual(receiver.Value, other.Value)
+	}
+
+	switch receiver.Type {
+	case ObjectType:
+		return attrsEqual(receiver.Value.([]Attr), other.Value.([]Attr))
+	case BoolType, DurationType, IntType, Int64Type, Uint64Type, Float64Type, StringType, RuneType, Complex128Type:
+		return receiver.Value == other.Value
+	case BoolsType:
+		return comparableSliceEqual(receiver.Value.([]bool), other.Value.([]bool))
+	case TimeType:
+		return receiver.Value.(time.Time).Equal(other.Value.(time.Time))
+	case TimesType:
+		return sliceEqual(receiver.Value.([]time.Time), other.Value.([]time.Time), time.Time.Equal)
+	case DurationsType:
+		return comparableSliceEqual(receiver.Value.([]time.Duration), other.Value.([]time.Duration))
+	case IntsType:
+		return comparableSliceEqual(receiver.Value.([]int), other.Value.([]int))
+	case Int64sType:
+		return comparableSliceEqual(receiver.Value.([]int64), other.Value.([]int64))
+	case Uint64sType:
+		return comparableSliceEqual(receiver.Value.([]uint64), other.Value.([]uint64))
+	case Float64sType:
+		return comparableSliceEqual(receiver.Value.([]float64), other.Value.([]float64))
+	case StringsType:
+		return comparableSliceEqual(receiver.Value.([]string), other.Value.([]string))
+	case IPType:
+		return receiver.Value.(net.IP).Equal(other.Value.(net.IP))
+	case URLType:
+		urlA, urlB := receiver.Value.(*url.URL), other.Value.(*url.URL)
+		if urlA == nil || urlB == nil {
+			return urlA == urlB
+		}
+
+		return urlA.String() == urlB.String()
+	case JSONRawType:
+		return bytes.Equal(receiver.Value.(json.RawMessage), other.Value.(json.RawMessage))
+	default:
+		return reflect.DeepEqual(receiver.Value, other.Value)
+	}
+}
+
+// comparableSliceEqual reports whether the given slices have the same elements, in the same order.
+func comparableSliceEqual[T comparable](a, b []T) bool {
+	return sliceEqual(a, b, func(x, y T) bool { return x == y })
+}
+
+// sliceEqual reports whether the given slices have the same length and eq holds for every pair of elements.
+func sliceEqual[T any](a, b []T, eq func(x, y T) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for index := range a {
+		if !eq(a[index], b[index]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewSafe returns a SafeError guarding the given StructuredError.
+// If err is nil, it guards an empty StructuredError.
+//
+// The given error must not be used directly afterward, use Snapshot to read it.
+func NewSafe(err *StructuredError) *SafeError {
+	if err == nil {
+		err = &StructuredError{}
+	}
+
+	return &SafeError{err: err}
+}
+
+// WithCode sets the code on the guarded error and returns the receiver for chaining.
+// It is safe for concurrent use.
+func (receiver *SafeError) WithCode(code string) *SafeError {
+	return receiver.Update(func(err *StructuredError) { err.WithCode(code) })
+}
+
+// AddAttrs appends the given attributes to the guarded error and returns the receiver for chaining.
+// Unlike StructuredError.WithAttrs, it appends instead of assigning, so concurrent callers do not
+// overwrite each other. It is safe for concurrent use.
+func (receiver *SafeError) AddAttrs(attrs ...Attr) *SafeError {
+	return receiver.Update(func(err *StructuredError) { err.Attrs = append(err.Attrs, attrs...) })
+}
+
+// WithTags prepends the given tags to the guarded error, as StructuredError.WithTags does,
+// and returns the receiver for chaining. It is safe for concurrent use.
+func (receiver *SafeError) WithTags(tags ...string) *SafeError {
+	return receiver.Update(func(err *StructuredError) { err.WithTags(tags...) })
+}
+
+// AppendErrors adds the given errors after the guarded error's existing errors and returns the receiver for chaining.
+// It is safe for concurrent use.
+func (receiver *SafeError) AppendErrors(errors ...error) *SafeError {
+	return receiver.Update(func(err *StructuredError) { err.AppendErrors(errors...) })
+}
+
+// Update calls fn with the guarded error while holding the lock and returns the receiver for chaining.
+// It gives access to the builder methods without a SafeError counterpart.
+// The error must not be retained by fn. It is safe for concurrent use.
+func (receiver *SafeError) Update(fn func(err *StructuredError)) *SafeError {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+
+	fn(receiver.err)
+
+	return receiver
+}
+
+// Snapshot returns a deep copy of the guarded error, as StructuredError.Clone does,
+// so it can be marshaled or inspected while other goroutines keep enriching the receiver.
+// It is safe for concurrent use.
+func (receiver *SafeError) Snapshot() *StructuredError {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+
+	return receiver.err.Clone()
+}
+
+// Error returns the message of the guarded error, as StructuredError.Error does.
+// It is safe for concurrent use.
+func (receiver *SafeError) Error() string {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+
+	return receiver.err.Error()
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{messages: make(map[string]string)}
+}
+
+// Register declares the given code with its default message and returns a sentinel error holding both,
+// meant to be stored in a package-level variable and compared with errors.Is.
+// Registering a code again replaces its default message.
+// The sentinel is built with New, so its message is trimmed after SetTrimMessages(true).
+//
+// Since StructuredError.Is matches by Code, every error built with NewFromCode, or carrying the same code,
+// matches the sentinel. The sentinel must not be modified, use NewFromCode to get an error to enrich.
+func (receiver *Registry) Register(code, defaultMessage string) *StructuredError {
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+
+	receiver.messages[code] = defaultMessage
+
+	return New(defaultMessage).WithCode(code)
+}
+
+// NewFromCode returns a new StructuredError with the given code and its registered default message.
+// If the code is not registered, the message is the code itself, so the error is never left without one.
+func (receiver *Registry) NewFromCode(code string) *StructuredError {
+	receiver.mu.RLock()
+	message, ok := receiver.messages[code]
+	receiver.mu.RUnlock()
+
+	if !ok {
+		message = code
+	}
+
+	return New(message).WithCode(code)
+}
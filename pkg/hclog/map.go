@@ -0,0 +1,184 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// AsMap marshals the StructuredError into a map[string]any
+// If the receiver is nil, it adds a single field to the map[string]any with the key "message"
+// and the value nilValue.
+//
+// Otherwise, it will have the following attributes:
+//   - Message
+//   - Code, if not empty
+//   - HTTPStatus, if not zero
+//   - Tags
+//   - Attrs
+//   - Errors
+//   - Stack.
+func (receiver *StructuredError) AsMap() map[string]any {
+	fields := make(map[string]any)
+
+	receiver.asMap(fields)
+
+	return fields
+}
+
+// ToMap returns the StructuredError as a nested map[string]any, for inspection
+// or for generic serializers. It is the same as AsMap:
+//   - "message" holds the message, or nilValue if the receiver is nil
+//   - "code" holds the code, if not empty
+//   - "http_status" holds the HTTP status as an int, if not zero
+//   - "tags" holds the tags as a []string
+//   - "attrs" holds a map[string]any of the attr values keyed by Attr.Key
+//   - "errors" holds the nested errors as a []map[string]any, built recursively
+//   - "stack" holds the lines of the stack as a []string.
+func (receiver *StructuredError) ToMap() map[string]any {
+	return receiver.AsMap()
+}
+
+// asMap is the actual implementation for AsMap.
+func (receiver *StructuredError) asMap(fields map[string]any) {
+	if receiver == nil {
+		fields[messageKey] = nilValue
+
+		return
+	}
+
+	fields[messageKey] = cmpOr(trimmedMessage(receiver.Message), nilValue)
+
+	if receiver.Code != emptyString {
+		fields[codeKey] = receiver.Code
+	}
+
+	if receiver.HTTPStatus != zero {
+		fields[httpStatusKey] = receiver.HTTPStatus
+	}
+
+	if keepField(len(receiver.Tags)) {
+		sliceToMap(fields, tagsKey, receiver.Tags)
+	}
+
+	if attrs := withoutOmittedAttrs(receiver.Attrs); keepField(len(attrs)) {
+		sliceToMap(fields, attrsKey, attrs)
+	}
+
+	if keepField(len(receiver.Errors)) {
+		normalized := receiver.normalizedErrors()
+
+		sliceToMap(fields, errorsKey, normalized)
+	}
+
+	if keepField(len(receiver.Stack)) {
+		sliceToMap(fields, stackKey, stackLines(receiver.Stack))
+	}
+}
+
+// AsMap marshals the Attr into a map[string]any
+// If the receiver is nil, it adds a single field to the map[string]any with the key "nil" and the value nilValue.
+//
+// Otherwise, it will have a single attribute with the key receiver.Key and the value receiver.Value.
+func (receiver *Attr) AsMap() map[string]any {
+	fields := make(map[string]any, one)
+
+	receiver.asMap(fields)
+
+	return fields
+}
+
+// asMap is the actual implementation for AsMap.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) asMap(fields map[string]any) {
+	if receiver == nil {
+		fields[nilValue] = nilValue
+
+		return
+	}
+
+	receiver = receiver.redacted()
+
+	switch receiver.Type { //nolint:exhaustive // just strings, IPs, URLs and raw JSON need specific assert
+	case StringsType:
+		sliceToMap(fields, receiver.Key, receiver.Value.([]string))
+	case IPType:
+		fields[receiver.Key] = ipToString(receiver.Value.(net.IP))
+	case URLType:
+		fields[receiver.Key] = urlToString(receiver.Value.(*url.URL))
+	case JSONRawType:
+		fields[receiver.Key] = jsonRawToString(receiver.Value.(json.RawMessage))
+	case LogValuerType:
+		fields[receiver.Key] = stringerToString(receiver.Value.(fmt.Stringer))
+	case RuneType:
+		fields[receiver.Key] = runeToString(receiver.Value.(rune))
+	case Complex128Type:
+		fields[receiver.Key] = complexToString(receiver.Value.(complex128))
+	default:
+		fields[receiver.Key] = receiver.Value
+	}
+}
+
+// errorToMap marshals an error into the given map[string]any.
+//
+// If the error is nil, it adds a single field to the map[string]any with the key "message"
+// and the value nilValue.
+//
+// If the error is a *StructuredError, it marshals the *StructuredError into the map[string]any.
+//
+// If the error is not a *StructuredError, it adds a single field to the map[string]any with the key "message"
+// and the value of the error's Error() method, or nilValue if the error is nil.
+func errorToMap(fields map[string]any, err error) {
+	var value *StructuredError
+	switch {
+	case err == nil:
+		fields[messageKey] = nilValue
+	case stderrors.As(err, &value):
+		value.asMap(fields)
+	default:
+		errStr := strings.TrimSpace(err.Error())
+		fields[messageKey] = cmpOr(errStr, nilValue)
+	}
+}
+
+// sliceToMap converts a slice of any type to a map[string]any value.
+func sliceToMap[T any](fields map[string]any, key string, slice []T) {
+	if len(slice) == zero {
+		fields[key] = []struct{}{}
+
+		return
+	}
+
+	switch values := any(slice).(type) {
+	case []Attr:
+		attrs := make(map[string]any, len(values))
+		for _, attr := range values {
+			attr.asMap(attrs)
+		}
+
+		fields[key] = attrs
+	case []error:
+		errs := make([]map[string]any, zero, len(values))
+		for index, err := range values {
+			errs = append(errs, make(map[string]any))
+
+			errorToMap(errs[index], err)
+		}
+
+		fields[key] = errs
+	case []string:
+		result := make([]string, zero, len(values))
+
+		for _, value := range values {
+			result = append(result, strings.TrimSpace(value))
+		}
+
+		fields[key] = result
+	default:
+		fields[key] = slice
+	}
+}
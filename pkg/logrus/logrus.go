@@ -19,6 +19,8 @@ type (
 //
 // Otherwise, it will have the following attributes:
 //   - Message
+//   - Code, if not empty
+//   - HTTPStatus, if not zero
 //   - Tags
 //   - Attrs
 //   - Errors
@@ -0,0 +1,189 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	otelKeySeparator = "."
+)
+
+// RecordSpanError records the given error onto the given span.
+//
+// It sets the span status to codes.Error and records an exception event for the error.
+// If the error is a StructuredError, its code, HTTP status, tags and attrs are also set as span attributes
+// and attached to the exception event, see OtelAttributes.
+//
+// Nothing is recorded if the span or the error is nil.
+//
+// Usage:
+//
+//	span := trace.SpanFromContext(ctx)
+//	errors.RecordSpanError(span, err)
+func RecordSpanError(span trace.Span, err error) {
+	if span == nil || err == nil {
+		return
+	}
+
+	var value *StructuredError
+	if !stderrors.As(err, &value) || value == nil {
+		span.SetStatus(codes.Error, cmpOr(strings.TrimSpace(err.Error()), nilValue))
+		span.RecordError(err)
+
+		return
+	}
+
+	attrs := value.OtelAttributes()
+
+	span.SetStatus(codes.Error, cmpOr(trimmedMessage(value.Message), nilValue))
+	span.RecordError(err, trace.WithAttributes(attrs...))
+	span.SetAttributes(attrs...)
+}
+
+// OtelAttributes returns the OpenTelemetry attributes representation of the receiver.
+//
+// The returned attributes will have the following values:
+//   - Code, as a string attribute with the key "code", if not empty
+//   - HTTPStatus, as an int attribute with the key "http_status", if not zero
+//   - Tags, as a string slice attribute with the key "tags"
+//   - Attrs, one attribute per Attr, keyed by the Attr key.
+//
+// String, int, bool and float attrs, and their slices, are mapped to the native
+// OpenTelemetry attribute types. Object attrs are flattened using dotted keys and
+// any other type is stringified.
+//
+// If the receiver is nil, the returned slice is empty.
+func (receiver *StructuredError) OtelAttributes() []attribute.KeyValue {
+	if receiver == nil {
+		return nil
+	}
+
+	attrs := make([]attribute.KeyValue, zero, len(receiver.Attrs)+one)
+
+	if receiver.Code != emptyString {
+		attrs = append(attrs, attribute.String(codeKey, receiver.Code))
+	}
+
+	if receiver.HTTPStatus != zero {
+		attrs = append(attrs, attribute.Int(httpStatusKey, receiver.HTTPStatus))
+	}
+
+	if keepField(len(receiver.Tags)) {
+		tags := make([]string, zero, len(receiver.Tags))
+		for _, tag := range receiver.Tags {
+			tags = append(tags, strings.TrimSpace(tag))
+		}
+
+		attrs = append(attrs, attribute.StringSlice(tagsKey, tags))
+	}
+
+	for _, attr := range withoutOmittedAttrs(receiver.Attrs) {
+		attrs = attr.asOtel(attrs, emptyString)
+	}
+
+	return attrs
+}
+
+// asOtel appends the OpenTelemetry attributes representation of the receiver to the given attrs.
+//
+// The key of the appended attributes is the receiver's key prefixed with the given prefix.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) asOtel(attrs []attribute.KeyValue, prefix string) []attribute.KeyValue {
+	if receiver == nil {
+		return append(attrs, attribute.String(prefix+nilValue, nilValue))
+	}
+
+	receiver = receiver.redacted()
+
+	key := prefix + receiver.Key
+
+	switch receiver.Type {
+	case AnyType:
+		return append(attrs, attribute.String(key, fmt.Sprintf(verboseFormat, receiver.Value)))
+	case ObjectType:
+		for _, attr := range receiver.Value.([]Attr) {
+			attrs = attr.asOtel(attrs, key+otelKeySeparator)
+		}
+
+		return attrs
+	case BoolType:
+		return append(attrs, attribute.Bool(key, receiver.Value.(bool)))
+	case BoolsType:
+		return append(attrs, attribute.BoolSlice(key, receiver.Value.([]bool)))
+	case TimeType:
+		return append(attrs, attribute.String(key, receiver.Value.(time.Time).Format(time.RFC3339Nano)))
+	case TimesType:
+		values := receiver.Value.([]time.Time)
+
+		times := make([]string, zero, len(values))
+		for _, value := range values {
+			times = append(times, value.Format(time.RFC3339Nano))
+		}
+
+		return append(attrs, attribute.StringSlice(key, times))
+	case DurationType:
+		return append(attrs, attribute.String(key, receiver.Value.(time.Duration).String()))
+	case DurationsType:
+		values := receiver.Value.([]time.Duration)
+
+		durations := make([]string, zero, len(values))
+		for _, value := range values {
+			durations = append(durations, value.String())
+		}
+
+		return append(attrs, attribute.StringSlice(key, durations))
+	case IntType:
+		return append(attrs, attribute.Int(key, receiver.Value.(int)))
+	case IntsType:
+		return append(attrs, attribute.IntSlice(key, receiver.Value.([]int)))
+	case Int64Type:
+		return append(attrs, attribute.Int64(key, receiver.Value.(int64)))
+	case Int64sType:
+		return append(attrs, attribute.Int64Slice(key, receiver.Value.([]int64)))
+	case Uint64Type:
+		return append(attrs, attribute.String(key, strconv.FormatUint(receiver.Value.(uint64), ten)))
+	case Uint64sType:
+		values := receiver.Value.([]uint64)
+
+		uints := make([]string, zero, len(values))
+		for _, value := range values {
+			uints = append(uints, strconv.FormatUint(value, ten))
+		}
+
+		return append(attrs, attribute.StringSlice(key, uints))
+	case Float64Type:
+		return append(attrs, attribute.Float64(key, receiver.Value.(float64)))
+	case Float64sType:
+		return append(attrs, attribute.Float64Slice(key, receiver.Value.([]float64)))
+	case StringType:
+		return append(attrs, attribute.String(key, receiver.Value.(string)))
+	case StringsType:
+		return append(attrs, attribute.StringSlice(key, receiver.Value.([]string)))
+	case IPType:
+		return append(attrs, attribute.String(key, ipToString(receiver.Value.(net.IP))))
+	case URLType:
+		return append(attrs, attribute.String(key, urlToString(receiver.Value.(*url.URL))))
+	case JSONRawType:
+		return append(attrs, attribute.String(key, jsonRawToString(receiver.Value.(json.RawMessage))))
+	case LogValuerType:
+		return append(attrs, attribute.String(key, stringerToString(receiver.Value.(fmt.Stringer))))
+	case RuneType:
+		return append(attrs, attribute.String(key, runeToString(receiver.Value.(rune))))
+	case Complex128Type:
+		return append(attrs, attribute.String(key, complexToString(receiver.Value.(complex128))))
+	default:
+		return append(attrs, attribute.String(key, fmt.Sprintf(verboseFormat, receiver.Value)))
+	}
+}
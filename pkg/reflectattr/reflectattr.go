@@ -0,0 +1,111 @@
+// Package reflectattr is an opt-in add-on that turns the exported fields of a struct
+// into Attr values of the core errors package using reflection.
+//
+// It is meant for quick debugging, the core packages never use reflection to build
+// attributes, so prefer the typed helpers like errors.String or errors.Int in hot paths.
+//
+// Basic usage:
+//
+//	err := errors.New("failed to create user").
+//	    WithAttrs(reflectattr.FromStruct("user", user)...)
+package reflectattr
+
+import (
+	"reflect"
+	"time"
+
+	errors "github.com/emiliogrv/errors/pkg/core"
+)
+
+const (
+	emptyString = ""
+	separator   = "."
+
+	zero = 0
+)
+
+//nolint:gochecknoglobals // needed to compare field types without allocating
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// FromStruct returns an Attr for every exported field of the given struct, or of the
+// struct the given pointer points to, keyed by the field name joined to the prefix with a dot.
+// An empty prefix keys the Attr by the bare field name.
+//
+// Fields of kind int, string, bool, float, time.Time and time.Duration become their typed Attr,
+// nested structs become an Object Attr holding their own fields with the dotted prefix,
+// and anything else falls back to Any.
+//
+// It returns nil if the value is neither a struct nor a non-nil pointer to a struct.
+func FromStruct(prefix string, v any) []errors.Attr {
+	value := reflect.ValueOf(v)
+
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return fromStructValue(prefix, value)
+}
+
+func fromStructValue(prefix string, value reflect.Value) []errors.Attr {
+	valueType := value.Type()
+	attrs := make([]errors.Attr, 0, valueType.NumField())
+
+	for index := zero; index < valueType.NumField(); index++ {
+		field := valueType.Field(index)
+		if !field.IsExported() {
+			continue
+		}
+
+		attrs = append(attrs, fromValue(joinKey(prefix, field.Name), value.Field(index)))
+	}
+
+	return attrs
+}
+
+func fromValue(key string, value reflect.Value) errors.Attr {
+	switch value.Type() {
+	case timeType:
+		return errors.Time(key, value.Interface().(time.Time)) //nolint:forcetypeassert // checked by the type
+	case durationType:
+		return errors.Duration(key, time.Duration(value.Int()))
+	}
+
+	//nolint:exhaustive // the remaining kinds fall back to Any
+	switch value.Kind() {
+	case reflect.Int:
+		return errors.Int(key, int(value.Int()))
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return errors.Int64(key, value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return errors.Uint64(key, value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return errors.Float64(key, value.Float())
+	case reflect.String:
+		return errors.String(key, value.String())
+	case reflect.Bool:
+		return errors.Bool(key, value.Bool())
+	case reflect.Struct:
+		return errors.Object(key, fromStructValue(key, value)...)
+	default:
+		return errors.Any(key, value.Interface())
+	}
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == emptyString {
+		return name
+	}
+
+	return prefix + separator + name
+}
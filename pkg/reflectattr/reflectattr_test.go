@@ -0,0 +1,128 @@
+package reflectattr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	errors "github.com/emiliogrv/errors/pkg/core"
+)
+
+type (
+	address struct {
+		City    string
+		ZipCode int
+	}
+
+	user struct {
+		CreatedAt time.Time
+		Tags      []string
+		Name      string
+		Address   address
+		password  string
+		Timeout   time.Duration
+		Score     float64
+		ID        int
+		Age       int32
+		Visits    uint
+		Active    bool
+	}
+)
+
+func TestFromStruct(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	value := user{
+		CreatedAt: createdAt,
+		Tags:      []string{"admin"},
+		Name:      "john",
+		Address:   address{City: "NYC", ZipCode: 10001},
+		password:  "secret",
+		Timeout:   time.Second,
+		Score:     9.5,
+		ID:        42,
+		Age:       30,
+		Visits:    7,
+		Active:    true,
+	}
+
+	expectedWithPrefix := []errors.Attr{
+		errors.Time("user.CreatedAt", createdAt),
+		errors.Any("user.Tags", []string{"admin"}),
+		errors.String("user.Name", "john"),
+		errors.Object(
+			"user.Address",
+			errors.String("user.Address.City", "NYC"),
+			errors.Int("user.Address.ZipCode", 10001),
+		),
+		errors.Duration("user.Timeout", time.Second),
+		errors.Float64("user.Score", 9.5),
+		errors.Int("user.ID", 42),
+		errors.Int64("user.Age", 30),
+		errors.Uint64("user.Visits", 7),
+		errors.Bool("user.Active", true),
+	}
+
+	tests := []struct {
+		value    any
+		name     string
+		prefix   string
+		expected []errors.Attr
+	}{
+		{
+			name:     "given_struct_with_mixed_fields_when_from_struct_then_typed_attrs",
+			prefix:   "user",
+			value:    value,
+			expected: expectedWithPrefix,
+		},
+		{
+			name:     "given_pointer_to_struct_when_from_struct_then_typed_attrs",
+			prefix:   "user",
+			value:    &value,
+			expected: expectedWithPrefix,
+		},
+		{
+			name:   "given_empty_prefix_when_from_struct_then_bare_field_names",
+			prefix: "",
+			value:  address{City: "NYC", ZipCode: 10001},
+			expected: []errors.Attr{
+				errors.String("City", "NYC"),
+				errors.Int("ZipCode", 10001),
+			},
+		},
+		{
+			name:     "given_nil_pointer_when_from_struct_then_nil",
+			prefix:   "user",
+			value:    (*user)(nil),
+			expected: nil,
+		},
+		{
+			name:     "given_non_struct_when_from_struct_then_nil",
+			prefix:   "user",
+			value:    42,
+			expected: nil,
+		},
+		{
+			name:     "given_nil_when_from_struct_then_nil",
+			prefix:   "user",
+			value:    nil,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		test := tt
+
+		t.Run(
+			test.name, func(t *testing.T) {
+				t.Parallel()
+
+				result := FromStruct(test.prefix, test.value)
+
+				assert.Equal(t, test.expected, result)
+			},
+		)
+	}
+}
@@ -24,6 +24,11 @@
 package errors
 
 import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -36,9 +41,20 @@ type (
 		Value any    `json:"value"`
 		Key   string `json:"key"`
 		Type  Type   `json:"type"`
+
+		// sensitive indicates whether the Attr was created via Sensitive.
+		sensitive bool
+
+		// omitEmpty indicates whether the Attr was marked via OmitEmpty.
+		omitEmpty bool
 	}
 )
 
+//nolint:gochecknoglobals // needed to avoid use of specific instance of Attr
+var (
+	sensitiveKeys = make(map[string]struct{})
+)
+
 // Type constants define the type of Attr.
 const (
 	AnyType Type = iota
@@ -59,6 +75,12 @@ const (
 	Float64sType
 	StringType
 	StringsType
+	IPType
+	URLType
+	JSONRawType
+	LogValuerType
+	RuneType
+	Complex128Type
 )
 
 // Any returns an Attr with the given key and value.
@@ -214,3 +236,582 @@ func String(key, value string) Attr {
 func Strings(key string, value ...string) Attr {
 	return Attr{Type: StringsType, Key: key, Value: value}
 }
+
+// ObjectBuilder builds an ObjectType Attr with a fluent syntax, so nested objects read top-down.
+// The zero value is not usable, use NewObject instead.
+//
+// Example:
+//
+//	attr := NewObject("user").
+//	    Str("name", "john").
+//	    Int("age", 30).
+//	    Object(NewObject("address").Str("city", "NYC")).
+//	    Build()
+type ObjectBuilder struct {
+	key   string
+	attrs []Attr
+}
+
+// NewObject returns an ObjectBuilder for an ObjectType Attr with the given key.
+func NewObject(key string) *ObjectBuilder {
+	return &ObjectBuilder{key: key}
+}
+
+// Str adds a StringType Attr with the given key and value to the object.
+func (receiver *ObjectBuilder) Str(key, value string) *ObjectBuilder {
+	return receiver.Attrs(String(key, value))
+}
+
+// Int adds an IntType Attr with the given key and value to the object.
+func (receiver *ObjectBuilder) Int(key string, value int) *ObjectBuilder {
+	return receiver.Attrs(Int(key, value))
+}
+
+// Int64 adds an Int64Type Attr with the given key and value to the object.
+func (receiver *ObjectBuilder) Int64(key string, value int64) *ObjectBuilder {
+	return receiver.Attrs(Int64(key, value))
+}
+
+// Uint64 adds a Uint64Type Attr with the given key and value to the object.
+func (receiver *ObjectBuilder) Uint64(key string, value uint64) *ObjectBuilder {
+	return receiver.Attrs(Uint64(key, value))
+}
+
+// Float64 adds a Float64Type Attr with the given key and value to the object.
+func (receiver *ObjectBuilder) Float64(key string, value float64) *ObjectBuilder {
+	return receiver.Attrs(Float64(key, value))
+}
+
+// Bool adds a BoolType Attr with the given key and value to the object.
+func (receiver *ObjectBuilder) Bool(key string, value bool) *ObjectBuilder {
+	return receiver.Attrs(Bool(key, value))
+}
+
+// Time adds a TimeType Attr with the given key and value to the object.
+func (receiver *ObjectBuilder) Time(key string, value time.Time) *ObjectBuilder {
+	return receiver.Attrs(Time(key, value))
+}
+
+// Duration adds a DurationType Attr with the given key and value to the object.
+func (receiver *ObjectBuilder) Duration(key string, value time.Duration) *ObjectBuilder {
+	return receiver.Attrs(Duration(key, value))
+}
+
+// Any adds an AnyType Attr with the given key and value to the object.
+func (receiver *ObjectBuilder) Any(key string, value any) *ObjectBuilder {
+	return receiver.Attrs(Any(key, value))
+}
+
+// Object adds the object built by the given ObjectBuilder as a nested ObjectType Attr.
+// A nil builder is ignored.
+func (receiver *ObjectBuilder) Object(builder *ObjectBuilder) *ObjectBuilder {
+	if builder == nil {
+		return receiver
+	}
+
+	return receiver.Attrs(builder.Build())
+}
+
+// Attrs adds the given attributes to the object as they are.
+func (receiver *ObjectBuilder) Attrs(attrs ...Attr) *ObjectBuilder {
+	receiver.attrs = append(receiver.attrs, attrs...)
+
+	return receiver
+}
+
+// Build returns the ObjectType Attr, equivalent to calling Object with the same key and attributes.
+// The builder can keep being used afterward without affecting the returned Attr.
+func (receiver *ObjectBuilder) Build() Attr {
+	attrs := make([]Attr, len(receiver.attrs))
+	copy(attrs, receiver.attrs)
+
+	return Object(receiver.key, attrs...)
+}
+
+// IP returns an Attr with the given key and value.
+// The value must be a net.IP, marshaled as its canonical dotted (IPv4) or colon (IPv6) string.
+//
+// The resulting Attr will have its Type field set to IPType.
+func IP(key string, value net.IP) Attr {
+	return Attr{Type: IPType, Key: key, Value: value}
+}
+
+// URL returns an Attr with the given key and value.
+// The value must be a *url.URL, marshaled via its Redacted method so the password is never leaked.
+//
+// The resulting Attr will have its Type field set to URLType.
+func URL(key string, value *url.URL) Attr {
+	return Attr{Type: URLType, Key: key, Value: value}
+}
+
+// JSONRaw returns an Attr with the given key and value.
+// The value must be a json.RawMessage, embedded verbatim by MarshalJSON when it is well-formed JSON,
+// or as a quoted string otherwise. The other marshalers render it as a plain string.
+//
+// The resulting Attr will have its Type field set to JSONRawType.
+func JSONRaw(key string, value json.RawMessage) Attr {
+	return Attr{Type: JSONRawType, Key: key, Value: value}
+}
+
+// Rune returns an Attr with the given key and value.
+// The value must be a rune, marshaled as the quoted character followed by its code point, like 'é' (U+00E9).
+//
+// The resulting Attr will have its Type field set to RuneType.
+func Rune(key string, value rune) Attr {
+	return Attr{Type: RuneType, Key: key, Value: value}
+}
+
+// Complex128 returns an Attr with the given key and value.
+// The value must be a complex128, marshaled as (re+imi), like (1.5-2i).
+//
+// The resulting Attr will have its Type field set to Complex128Type.
+func Complex128(key string, value complex128) Attr {
+	return Attr{Type: Complex128Type, Key: key, Value: value}
+}
+
+// ipToString returns the canonical string of the given IP, or nilValue if it is empty.
+func ipToString(value net.IP) string {
+	if len(value) == zero {
+		return nilValue
+	}
+
+	return value.String()
+}
+
+// urlToString returns the given URL with its password redacted, or nilValue if it is nil.
+func urlToString(value *url.URL) string {
+	if value == nil {
+		return nilValue
+	}
+
+	return value.Redacted()
+}
+
+// jsonRawToString returns the given raw JSON as a string, or nilValue if it is empty.
+func jsonRawToString(value json.RawMessage) string {
+	if len(value) == zero {
+		return nilValue
+	}
+
+	return string(value)
+}
+
+// runeToString returns the given rune quoted, followed by its code point, like 'é' (U+00E9).
+func runeToString(value rune) string {
+	return fmt.Sprintf(runeFormat, value, value)
+}
+
+// complexToString returns the given complex number as (re+imi), like (1.5-2i).
+func complexToString(value complex128) string {
+	return strconv.FormatComplex(value, 'g', -one, complexBitSize)
+}
+
+// stringerToString returns the String of the given value, or nilValue if it is nil.
+func stringerToString(value fmt.Stringer) string {
+	if value == nil {
+		return nilValue
+	}
+
+	return value.String()
+}
+
+// cloneURL returns a copy of the given URL, or nil if the given URL is nil.
+func cloneURL(value *url.URL) *url.URL {
+	if value == nil {
+		return nil
+	}
+
+	result := *value
+
+	if value.User != nil {
+		user := *value.User
+		result.User = &user
+	}
+
+	return &result
+}
+
+// attrOf returns an Attr with the given key and value, with its Type matching the concrete type of the value.
+// Values of types without a specific helper result in an AnyType Attr.
+// Runes cannot be told apart from int32 values, so they result in an AnyType Attr too.
+func attrOf(key string, value any) Attr {
+	switch value := value.(type) {
+	case []Attr:
+		return Object(key, value...)
+	case bool:
+		return Bool(key, value)
+	case []bool:
+		return Bools(key, value...)
+	case time.Time:
+		return Time(key, value)
+	case []time.Time:
+		return Times(key, value...)
+	case time.Duration:
+		return Duration(key, value)
+	case []time.Duration:
+		return Durations(key, value...)
+	case int:
+		return Int(key, value)
+	case []int:
+		return Ints(key, value...)
+	case int64:
+		return Int64(key, value)
+	case []int64:
+		return Int64s(key, value...)
+	case uint64:
+		return Uint64(key, value)
+	case []uint64:
+		return Uint64s(key, value...)
+	case float64:
+		return Float64(key, value)
+	case []float64:
+		return Float64s(key, value...)
+	case string:
+		return String(key, value)
+	case []string:
+		return Strings(key, value...)
+	case net.IP:
+		return IP(key, value)
+	case *url.URL:
+		return URL(key, value)
+	case json.RawMessage:
+		return JSONRaw(key, value)
+	case complex128:
+		return Complex128(key, value)
+	default:
+		return Any(key, value)
+	}
+}
+
+// clone returns a deep copy of the receiver.
+// Slice values, and the attrs of objects, are copied so they do not share memory with the receiver.
+//
+//nolint:forcetypeassert,errcheck // XXXType helpers avoid using reflection
+func (receiver *Attr) clone() Attr {
+	attr := *receiver
+
+	if !receiver.valueMatchesType() {
+		return attr
+	}
+
+	switch receiver.Type { //nolint:exhaustive // just objects and slices need to be copied
+	case ObjectType:
+		attr.Value = cloneAttrs(receiver.Value.([]Attr))
+	case BoolsType:
+		attr.Value = cloneSlice(receiver.Value.([]bool))
+	case TimesType:
+		attr.Value = cloneSlice(receiver.Value.([]time.Time))
+	case DurationsType:
+		attr.Value = cloneSlice(receiver.Value.([]time.Duration))
+	case IntsType:
+		attr.Value = cloneSlice(receiver.Value.([]int))
+	case Int64sType:
+		attr.Value = cloneSlice(receiver.Value.([]int64))
+	case Uint64sType:
+		attr.Value = cloneSlice(receiver.Value.([]uint64))
+	case Float64sType:
+		attr.Value = cloneSlice(receiver.Value.([]float64))
+	case StringsType:
+		attr.Value = cloneSlice(receiver.Value.([]string))
+	case IPType:
+		attr.Value = net.IP(cloneSlice(receiver.Value.(net.IP)))
+	case URLType:
+		attr.Value = cloneURL(receiver.Value.(*url.URL))
+	case JSONRawType:
+		attr.Value = json.RawMessage(cloneSlice(receiver.Value.(json.RawMessage)))
+	}
+
+	return attr
+}
+
+// cloneAttrs returns a deep copy of the given attrs, or nil if the given attrs are nil.
+func cloneAttrs(attrs []Attr) []Attr {
+	if attrs == nil {
+		return nil
+	}
+
+	result := make([]Attr, zero, len(attrs))
+	for _, attr := range attrs {
+		result = append(result, attr.clone())
+	}
+
+	return result
+}
+
+// Sensitive returns an Attr with the given key and value that is always redacted when marshaled.
+// The value must be a string.
+//
+// The raw value is kept in the Attr, so it can still be read with GetAttr.
+// The resulting Attr will have its Type field set to StringType.
+func Sensitive(key, value string) Attr {
+	return Attr{Type: StringType, Key: key, Value: value, sensitive: true}
+}
+
+// Redact registers the given key as sensitive.
+// Every Attr with that key, at any nesting level, is marshaled with the value "[REDACTED]",
+// while its raw value is kept in the Attr.
+//
+// Redact is not thread-safe. It should be called before any
+// StructuredError is marshaled.
+func Redact(key string) {
+	sensitiveKeys[key] = struct{}{}
+}
+
+// IsSensitive reports whether the receiver is redacted when marshaled,
+// either because it was created via Sensitive or because its key was registered via Redact.
+func (receiver *Attr) IsSensitive() bool {
+	if receiver == nil {
+		return false
+	}
+
+	if receiver.sensitive {
+		return true
+	}
+
+	_, ok := sensitiveKeys[receiver.Key]
+
+	return ok
+}
+
+// OmitEmpty returns a copy of the receiver that every marshaler skips when its value is the zero value
+// for its type, like an empty string, a zero number, a zero time, or an empty slice or object.
+// This avoids noisy empty fields without conditionals:
+//
+//	err.WithAttrs(String("user_id", userID).OmitEmpty())
+//
+// It has a value receiver so it can be chained on the helpers, like String or Int.
+func (receiver Attr) OmitEmpty() Attr { //nolint:recvcheck // a value receiver allows chaining on helpers
+	receiver.omitEmpty = true
+
+	return receiver
+}
+
+// isOmitted reports whether the receiver was marked via OmitEmpty and its value is the zero value for its type.
+// A value that does not match the Type of the receiver is never omitted.
+func (receiver *Attr) isOmitted() bool {
+	if !receiver.omitEmpty || !receiver.valueMatchesType() {
+		return false
+	}
+
+	switch value := receiver.Value.(type) {
+	case nil:
+		return true
+	case []Attr:
+		return len(withoutOmittedAttrs(value)) == zero
+	case bool:
+		return !value
+	case []bool:
+		return len(value) == zero
+	case time.Time:
+		return value.IsZero()
+	case []time.Time:
+		return len(value) == zero
+	case time.Duration:
+		return value == zero
+	case []time.Duration:
+		return len(value) == zero
+	case int:
+		return value == zero
+	case []int:
+		return len(value) == zero
+	case int64:
+		return value == zero
+	case []int64:
+		return len(value) == zero
+	case uint64:
+		return value == zero
+	case []uint64:
+		return len(value) == zero
+	case float64:
+		return value == zero
+	case []float64:
+		return len(value) == zero
+	case string:
+		return value == emptyString
+	case []string:
+		return len(value) == zero
+	case net.IP:
+		return len(value) == zero
+	case *url.URL:
+		return value == nil
+	case json.RawMessage:
+		return len(value) == zero
+	case rune:
+		return value == zero
+	case complex128:
+		return value == zero
+	default:
+		return false
+	}
+}
+
+// withoutOmittedAttrs returns the given attrs without the ones omitted via OmitEmpty.
+// The attrs of the objects among them are filtered by redacted, as every marshaler calls it.
+// It returns the given attrs as they are if none is omitted, to avoid allocating.
+func withoutOmittedAttrs(attrs []Attr) []Attr {
+	for index := range attrs {
+		if !attrs[index].isOmitted() {
+			continue
+		}
+
+		result := make([]Attr, zero, len(attrs)-one)
+		result = append(result, attrs[:index]...)
+
+		for _, attr := range attrs[index+one:] {
+			if !attr.isOmitted() {
+				result = append(result, attr)
+			}
+		}
+
+		return result
+	}
+
+	return attrs
+}
+
+// withoutSensitiveAttrs removes the sensitive attrs from the given attrs, in place,
+// and from the attrs of the objects among them, at any nesting level.
+// It returns nil if no Attr is left.
+func withoutSensitiveAttrs(attrs []Attr) []Attr {
+	result := attrs[:zero]
+
+	for _, attr := range attrs {
+		if attr.IsSensitive() {
+			continue
+		}
+
+		if attr.Type == ObjectType && attr.valueMatchesType() {
+			attr.Value = withoutSensitiveAttrs(attr.Value.([]Attr)) //nolint:forcetypeassert,errcheck // checked above
+		}
+
+		result = append(result, attr)
+	}
+
+	if len(result) == zero {
+		return nil
+	}
+
+	return result
+}
+
+// redacted returns the receiver, or a StringType Attr with the same key and the value "[REDACTED]"
+// if the receiver is sensitive. It is used by every marshaler, so raw values never leave the process.
+//
+// If the value of the receiver does not match its Type, like a hand-constructed Attr{Type: IntType, Value: "1"},
+// it returns an AnyType Attr with the same key and value, so the marshalers render it with %+v instead of panicking.
+//
+// If the receiver is an URLType Attr with a password, it returns a copy whose URL has the password replaced,
+// the same way url.URL.Redacted does.
+//
+// If the receiver is an ObjectType Attr with attrs omitted via OmitEmpty, it returns a copy without them.
+func (receiver *Attr) redacted() *Attr {
+	if receiver.IsSensitive() {
+		return &Attr{Type: StringType, Key: receiver.Key, Value: redactedValue}
+	}
+
+	if !receiver.valueMatchesType() {
+		return &Attr{Type: AnyType, Key: receiver.Key, Value: receiver.Value}
+	}
+
+	if receiver.Type == URLType {
+		if value, ok := redactURL(receiver.Value.(*url.URL)); ok { //nolint:forcetypeassert,errcheck // checked above
+			return &Attr{Type: URLType, Key: receiver.Key, Value: value}
+		}
+	}
+
+	if receiver.Type == ObjectType {
+		value := receiver.Value.([]Attr) //nolint:forcetypeassert,errcheck // checked above
+		if attrs := withoutOmittedAttrs(value); len(attrs) != len(value) {
+			return &Attr{Type: ObjectType, Key: receiver.Key, Value: attrs}
+		}
+	}
+
+	return receiver
+}
+
+// resolved returns the receiver with its value replaced by its string form when it cannot be serialized as is,
+// like the slog.LogValuer of a LogValuerType Attr. The resulting Attr has its Type field set to StringType.
+func (receiver *Attr) resolved() *Attr {
+	if receiver.Type != LogValuerType || !receiver.valueMatchesType() {
+		return receiver
+	}
+
+	//nolint:forcetypeassert,errcheck // checked above
+	return &Attr{Type: StringType, Key: receiver.Key, Value: stringerToString(receiver.Value.(fmt.Stringer))}
+}
+
+// redactURL returns a copy of the given URL with its password replaced by "xxxxx".
+// It returns false if the URL has no password.
+func redactURL(value *url.URL) (*url.URL, bool) {
+	if value == nil || value.User == nil {
+		return nil, false
+	}
+
+	if _, ok := value.User.Password(); !ok {
+		return nil, false
+	}
+
+	result := cloneURL(value)
+	result.User = url.UserPassword(value.User.Username(), redactedPassword)
+
+	return result, true
+}
+
+// valueMatchesType reports whether the Go type of the receiver's value is the one expected by its Type.
+// AnyType and unknown types accept any value, as the marshalers render them with %+v.
+func (receiver *Attr) valueMatchesType() bool {
+	var ok bool
+
+	switch receiver.Type { //nolint:exhaustive // AnyType and unknown types accept any value
+	case ObjectType:
+		_, ok = receiver.Value.([]Attr)
+	case BoolType:
+		_, ok = receiver.Value.(bool)
+	case BoolsType:
+		_, ok = receiver.Value.([]bool)
+	case TimeType:
+		_, ok = receiver.Value.(time.Time)
+	case TimesType:
+		_, ok = receiver.Value.([]time.Time)
+	case DurationType:
+		_, ok = receiver.Value.(time.Duration)
+	case DurationsType:
+		_, ok = receiver.Value.([]time.Duration)
+	case IntType:
+		_, ok = receiver.Value.(int)
+	case IntsType:
+		_, ok = receiver.Value.([]int)
+	case Int64Type:
+		_, ok = receiver.Value.(int64)
+	case Int64sType:
+		_, ok = receiver.Value.([]int64)
+	case Uint64Type:
+		_, ok = receiver.Value.(uint64)
+	case Uint64sType:
+		_, ok = receiver.Value.([]uint64)
+	case Float64Type:
+		_, ok = receiver.Value.(float64)
+	case Float64sType:
+		_, ok = receiver.Value.([]float64)
+	case StringType:
+		_, ok = receiver.Value.(string)
+	case StringsType:
+		_, ok = receiver.Value.([]string)
+	case IPType:
+		_, ok = receiver.Value.(net.IP)
+	case URLType:
+		_, ok = receiver.Value.(*url.URL)
+	case JSONRawType:
+		_, ok = receiver.Value.(json.RawMessage)
+	case LogValuerType:
+		_, ok = receiver.Value.(fmt.Stringer)
+	case RuneType:
+		_, ok = receiver.Value.(rune)
+	case Complex128Type:
+		_, ok = receiver.Value.(complex128)
+	default:
+		ok = true
+	}
+
+	return ok
+}
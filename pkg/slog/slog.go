@@ -1,71 +1,215 @@
 package errors
 
 import (
+	"encoding/json"
 	stderrors "errors"
+	"fmt"
 	"log/slog"
+	"net"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 )
 
+type (
+	// KeyConfig holds the group attribute names used by LogValue.
+	//
+	// Empty fields fall back to their default names:
+	// "message", "code", "http_status", "attrs", "errors", "tags", "stack", "frames", "caller" and "joined".
+	KeyConfig struct {
+		Message    string
+		Code       string
+		HTTPStatus string
+		Attrs      string
+		Errors     string
+		Tags       string
+		Stack      string
+		Frames     string
+		Caller     string
+		Joined     string
+	}
+
+	// logValuer wraps the slog.LogValuer of a LogValuerType Attr,
+	// so the marshalers that do not depend on slog can render it via fmt.Stringer.
+	logValuer struct {
+		value slog.LogValuer
+	}
+)
+
+//nolint:gochecknoglobals // needed to avoid use of specific instance of StructuredError
+var (
+	slogKeys = defaultKeyConfig()
+)
+
+// SlogKeys returns the group attribute names currently used by LogValue.
+func SlogKeys() KeyConfig {
+	return slogKeys
+}
+
+// SetSlogKeys overrides the group attribute names used by LogValue.
+//
+// Empty fields of the given KeyConfig fall back to their default names,
+// so SetSlogKeys(KeyConfig{}) restores the defaults.
+//
+// SetSlogKeys is not thread-safe. It should be called before any
+// StructuredError is logged.
+func SetSlogKeys(keys KeyConfig) {
+	defaults := defaultKeyConfig()
+
+	slogKeys = KeyConfig{
+		Message:    cmpOr(keys.Message, defaults.Message),
+		Code:       cmpOr(keys.Code, defaults.Code),
+		HTTPStatus: cmpOr(keys.HTTPStatus, defaults.HTTPStatus),
+		Attrs:      cmpOr(keys.Attrs, defaults.Attrs),
+		Errors:     cmpOr(keys.Errors, defaults.Errors),
+		Tags:       cmpOr(keys.Tags, defaults.Tags),
+		Stack:      cmpOr(keys.Stack, defaults.Stack),
+		Frames:     cmpOr(keys.Frames, defaults.Frames),
+		Caller:     cmpOr(keys.Caller, defaults.Caller),
+		Joined:     cmpOr(keys.Joined, defaults.Joined),
+	}
+}
+
+// LogValuer returns an Attr with the given key and value.
+// The value must be a slog.LogValuer, passed as is to slog so the handler resolves it,
+// while the other marshalers render the string of its resolved slog.Value.
+//
+// The resulting Attr will have its Type field set to LogValuerType.
+func LogValuer(key string, value slog.LogValuer) Attr {
+	return Attr{Type: LogValuerType, Key: key, Value: logValuer{value: value}}
+}
+
+// String returns the string of the resolved slog.Value of the receiver, or nilValue if it is nil.
+func (receiver logValuer) String() string {
+	if receiver.value == nil {
+		return nilValue
+	}
+
+	return receiver.value.LogValue().Resolve().String()
+}
+
+// defaultKeyConfig returns the KeyConfig with the default group attribute names.
+func defaultKeyConfig() KeyConfig {
+	return KeyConfig{
+		Message:    messageKey,
+		Code:       codeKey,
+		HTTPStatus: httpStatusKey,
+		Attrs:      attrsKey,
+		Errors:     errorsKey,
+		Tags:       tagsKey,
+		Stack:      stackKey,
+		Frames:     framesKey,
+		Caller:     callerKey,
+		Joined:     joinedKey,
+	}
+}
+
 // LogValue returns a slog.Value representation of the receiver.
 //
 // The returned slog.Value will have the following attributes:
 //   - Message
+//   - Code, if not empty
+//   - HTTPStatus, if not zero
 //   - Tags
 //   - Attrs
 //   - Errors
-//   - Stack.
+//   - Stack
+//   - Frames, one group per frame
+//   - Caller, only for errors with a caller set via WithCaller or WithCallerSkip
+//   - Joined, only for errors created via Join or JoinIf.
 //
 // If the receiver is not nil, the returned slog.Value is guaranteed not to be of Kind slog.KindLogValuer.
 // If the receiver is nil, the returned slog.Value is guaranteed to be of Kind slog.KindGroup.
 //
+// The group attribute names can be overridden with SetSlogKeys.
+//
 // Usage must be with slog.Any or slog.Group.
 func (receiver *StructuredError) LogValue() slog.Value {
+	keys := slogKeys
+
 	if receiver == nil {
-		return slog.GroupValue(slog.String(messageKey, nilValue))
+		return slog.GroupValue(slog.String(keys.Message, nilValue))
 	}
 
 	length := one
+	attrs := withoutOmittedAttrs(receiver.Attrs)
+
+	if receiver.Code != emptyString {
+		length++
+	}
 
-	if len(receiver.Attrs) > zero {
+	if receiver.HTTPStatus != zero {
 		length++
 	}
 
-	if len(receiver.Errors) > zero {
+	if keepField(len(attrs)) {
 		length++
 	}
 
-	if len(receiver.Tags) > zero {
+	if keepField(len(receiver.Errors)) {
 		length++
 	}
 
-	if len(receiver.Stack) > zero {
+	if keepField(len(receiver.Tags)) {
+		length++
+	}
+
+	if keepField(len(receiver.Stack)) {
+		length++
+	}
+
+	if len(receiver.frames) > zero {
+		length++
+	}
+
+	if receiver.caller != emptyString {
+		length++
+	}
+
+	if receiver.joined {
 		length++
 	}
 
 	values := make([]slog.Attr, zero, length)
-	values = append(values, slog.String(messageKey, cmpOr(receiver.Message, nilValue)))
+	values = append(values, slog.String(keys.Message, cmpOr(trimmedMessage(receiver.Message), nilValue)))
 
-	if len(receiver.Tags) > zero {
-		values = append(values, sliceToSlog(tagsKey, receiver.Tags))
+	if receiver.Code != emptyString {
+		values = append(values, slog.String(keys.Code, receiver.Code))
 	}
 
-	if len(receiver.Attrs) > zero {
-		values = append(values, sliceToSlog(attrsKey, receiver.Attrs))
+	if receiver.HTTPStatus != zero {
+		values = append(values, slog.Int(keys.HTTPStatus, receiver.HTTPStatus))
 	}
 
-	if len(receiver.Errors) > zero {
-		target := normalizerTarget{
-			errs: make([]error, zero, len(receiver.Errors)),
-		}
-		normalizeErrors(zero, &target, receiver.Errors...)
+	if keepField(len(receiver.Tags)) {
+		values = append(values, fieldToSlog(keys.Tags, receiver.Tags))
+	}
 
-		values = append(values, sliceToSlog(errorsKey, target.errs))
+	if keepField(len(attrs)) {
+		values = append(values, fieldToSlog(keys.Attrs, attrs))
 	}
 
-	if len(receiver.Stack) > zero {
-		values = append(values, sliceToSlog(stackKey, strings.Split(string(receiver.Stack), newLine)))
+	if keepField(len(receiver.Errors)) {
+		normalized := receiver.normalizedErrors()
+
+		values = append(values, fieldToSlog(keys.Errors, normalized))
+	}
+
+	if keepField(len(receiver.Stack)) {
+		values = append(values, fieldToSlog(keys.Stack, stackLines(receiver.Stack)))
+	}
+
+	if len(receiver.frames) > zero {
+		values = append(values, sliceToSlog(keys.Frames, receiver.frames))
+	}
+
+	if receiver.caller != emptyString {
+		values = append(values, slog.String(keys.Caller, receiver.caller))
+	}
+
+	if receiver.joined {
+		values = append(values, slog.Bool(keys.Joined, true))
 	}
 
 	return slog.GroupValue(values...)
@@ -94,6 +238,8 @@ func (receiver *Attr) asSlog() slog.Attr {
 		return slog.String(nilValue, nilValue)
 	}
 
+	receiver = receiver.redacted()
+
 	switch receiver.Type {
 	case AnyType:
 		return slog.Any(receiver.Key, receiver.Value)
@@ -131,6 +277,22 @@ func (receiver *Attr) asSlog() slog.Attr {
 		return slog.String(receiver.Key, receiver.Value.(string))
 	case StringsType:
 		return sliceToSlog(receiver.Key, receiver.Value.([]string))
+	case IPType:
+		return slog.String(receiver.Key, ipToString(receiver.Value.(net.IP)))
+	case URLType:
+		return slog.String(receiver.Key, urlToString(receiver.Value.(*url.URL)))
+	case JSONRawType:
+		return slog.String(receiver.Key, jsonRawToString(receiver.Value.(json.RawMessage)))
+	case LogValuerType:
+		if valuer, ok := receiver.Value.(logValuer); ok && valuer.value != nil {
+			return slog.Any(receiver.Key, valuer.value)
+		}
+
+		return slog.String(receiver.Key, stringerToString(receiver.Value.(fmt.Stringer)))
+	case RuneType:
+		return slog.String(receiver.Key, runeToString(receiver.Value.(rune)))
+	case Complex128Type:
+		return slog.String(receiver.Key, complexToString(receiver.Value.(complex128)))
 	default:
 		return slog.Any(receiver.Key, receiver.Value)
 	}
@@ -156,16 +318,27 @@ func errorToSlog(key string, err error) slog.Attr {
 	var value *StructuredError
 	switch {
 	case err == nil:
-		return slog.Group(key, slog.String(messageKey, nilValue))
+		return slog.Group(key, slog.String(slogKeys.Message, nilValue))
 	case stderrors.As(err, &value):
 		return slog.Attr{Key: key, Value: value.LogValue()}
 	default:
 		errStr := strings.TrimSpace(err.Error())
 
-		return slog.Group(key, slog.String(messageKey, cmpOr(errStr, nilValue)))
+		return slog.Group(key, slog.String(slogKeys.Message, cmpOr(errStr, nilValue)))
 	}
 }
 
+// fieldToSlog converts a field of a StructuredError to a slog.Attr, like sliceToSlog does.
+// An empty field is converted to an empty list, as slog.GroupValue drops empty groups,
+// so it is kept when SetOmitEmpty is disabled.
+func fieldToSlog[T any](key string, slice []T) slog.Attr {
+	if len(slice) == zero {
+		return slog.Any(key, []struct{}{})
+	}
+
+	return sliceToSlog(key, slice)
+}
+
 // sliceToSlog converts a slice of any type to a slice of slog.Attr.
 // It is needed in order to avoid reflection as much as possible.
 func sliceToSlog[T any](key string, slice []T) slog.Attr {
@@ -184,6 +357,15 @@ func sliceToSlog[T any](key string, slice []T) slog.Attr {
 		for i, value := range values {
 			attrs = append(attrs, errorToSlog(strconv.Itoa(i), value))
 		}
+	case []StackFrame:
+		for i, value := range values {
+			attrs = append(attrs, slog.Group(
+				strconv.Itoa(i),
+				slog.String(functionKey, value.Function),
+				slog.String(fileKey, value.File),
+				slog.Int(lineKey, value.Line),
+			))
+		}
 	case []bool:
 		for i, value := range values {
 			attrs = append(attrs, slog.Bool(strconv.Itoa(i), value))
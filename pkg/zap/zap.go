@@ -1,7 +1,11 @@
 package errors
 
 import (
+	"encoding/json"
 	stderrors "errors"
+	"fmt"
+	"net"
+	"net/url"
 	"strings"
 	"time"
 
@@ -21,6 +25,8 @@ var (
 //
 // Otherwise, it will have the following attributes:
 //   - Message
+//   - Code, if not empty
+//   - HTTPStatus, if not zero
 //   - Tags
 //   - Attrs
 //   - Errors
@@ -34,36 +40,41 @@ func (receiver *StructuredError) MarshalLogObject(encoder zapcore.ObjectEncoder)
 		return nil
 	}
 
-	encoder.AddString(messageKey, cmpOr(receiver.Message, nilValue))
+	encoder.AddString(messageKey, cmpOr(trimmedMessage(receiver.Message), nilValue))
 
-	if len(receiver.Tags) > zero {
+	if receiver.Code != emptyString {
+		encoder.AddString(codeKey, receiver.Code)
+	}
+
+	if receiver.HTTPStatus != zero {
+		encoder.AddInt(httpStatusKey, receiver.HTTPStatus)
+	}
+
+	if keepField(len(receiver.Tags)) {
 		err := sliceToZap(encoder, tagsKey, receiver.Tags)
 		if err != nil {
 			return err
 		}
 	}
 
-	if len(receiver.Attrs) > zero {
-		err := sliceToZap(encoder, attrsKey, receiver.Attrs)
+	if attrs := withoutOmittedAttrs(receiver.Attrs); keepField(len(attrs)) {
+		err := sliceToZap(encoder, attrsKey, attrs)
 		if err != nil {
 			return err
 		}
 	}
 
-	if len(receiver.Errors) > zero {
-		target := normalizerTarget{
-			errs: make([]error, zero, len(receiver.Errors)),
-		}
-		normalizeErrors(zero, &target, receiver.Errors...)
+	if keepField(len(receiver.Errors)) {
+		normalized := receiver.normalizedErrors()
 
-		err := sliceToZap(encoder, errorsKey, target.errs)
+		err := sliceToZap(encoder, errorsKey, normalized)
 		if err != nil {
 			return err
 		}
 	}
 
-	if len(receiver.Stack) > zero {
-		err := sliceToZap(encoder, stackKey, strings.Split(string(receiver.Stack), newLine))
+	if keepField(len(receiver.Stack)) {
+		err := sliceToZap(encoder, stackKey, stackLines(receiver.Stack))
 		if err != nil {
 			return err
 		}
@@ -92,6 +103,8 @@ func (receiver *Attr) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 		return nil
 	}
 
+	receiver = receiver.redacted()
+
 	switch receiver.Type {
 	case AnyType:
 		return JoinIf(encoder.AddReflected(receiver.Key, receiver.Value), ErrUnmarshalZap)
@@ -129,6 +142,18 @@ func (receiver *Attr) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
 		encoder.AddString(receiver.Key, receiver.Value.(string))
 	case StringsType:
 		return sliceToZap(encoder, receiver.Key, receiver.Value.([]string))
+	case IPType:
+		encoder.AddString(receiver.Key, ipToString(receiver.Value.(net.IP)))
+	case URLType:
+		encoder.AddString(receiver.Key, urlToString(receiver.Value.(*url.URL)))
+	case JSONRawType:
+		encoder.AddString(receiver.Key, jsonRawToString(receiver.Value.(json.RawMessage)))
+	case LogValuerType:
+		encoder.AddString(receiver.Key, stringerToString(receiver.Value.(fmt.Stringer)))
+	case RuneType:
+		encoder.AddString(receiver.Key, runeToString(receiver.Value.(rune)))
+	case Complex128Type:
+		encoder.AddString(receiver.Key, complexToString(receiver.Value.(complex128)))
 	default:
 		return JoinIf(encoder.AddReflected(receiver.Key, receiver.Value), ErrUnmarshalZap)
 	}
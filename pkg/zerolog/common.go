@@ -2,28 +2,52 @@ package errors
 
 import (
 	stderrors "errors"
+	"strings"
 )
 
 type (
 	normalizerTarget struct {
 		errs []error
 	}
+
+	// asser represents errors that provide their own As method, which stderrors.As calls.
+	asser interface {
+		As(target any) bool
+	}
 )
 
 const (
 	messageKey       = "message"
+	codeKey          = "code"
+	httpStatusKey    = "http_status"
 	attrsKey         = "attrs"
 	errorsKey        = "errors"
 	tagsKey          = "tags"
 	stackKey         = "stack"
+	framesKey        = "frames"
+	callerKey        = "caller"
+	joinedKey        = "joined"
+	functionKey      = "function"
+	fileKey          = "file"
+	lineKey          = "line"
 	depthKey         = "depth"
-	nilValue         = "!NILVALUE"
+	errorKey         = "error"
+	attrKey          = "attr"
+	tagKey           = "tag"
+	keyKey           = "key"
+	valueKey         = "value"
+	defaultNilValue  = "!NILVALUE"
+	redactedValue    = "[REDACTED]"
+	redactedPassword = "xxxxx"
+	emptyString      = ""
 	equals           = "="
 	colon            = ":"
 	quote            = `"`
 	newLine          = "\n"
 	tab              = "\t"
 	comma            = ","
+	dot              = "."
+	space            = " "
 	curlyOpen        = "{"
 	curlyClose       = "}"
 	bracketOpen      = "["
@@ -33,12 +57,29 @@ const (
 
 	maxDepthExceeded = "max depth exceeded"
 
-	zero      = 0
-	one       = 1
-	ten       = 10
-	sixtyFour = 64
+	// maxPooledBufferSize is the capacity above which a buffer is not returned to its pool,
+	// so a few large errors do not keep large buffers alive.
+	maxPooledBufferSize = 64 << ten
+
+	zero           = 0
+	one            = 1
+	ten            = 10
+	sixteen        = 16
+	thirtyTwo      = 32
+	sixtyFour      = 64
+	complexBitSize = 128
 
 	verboseFormat = "%+v"
+	runeFormat    = "%q (%U)"
+
+	runeCodePointPrefix = "(U+"
+)
+
+//nolint:gochecknoglobals // needed to avoid use of specific instance of StructuredError
+var (
+	nilValue     = defaultNilValue
+	omitEmpty    = true
+	trimMessages = false
 )
 
 var (
@@ -101,6 +142,78 @@ func SetMaxDepthMarshal(depth int) {
 	*ErrDepthExceeded = *err
 }
 
+// NilValue returns the marker written by every marshaler in place of nil errors, nil attrs and empty messages.
+func NilValue() string {
+	return nilValue
+}
+
+// SetNilValue sets the marker written by every marshaler in place of nil errors, nil attrs and empty messages,
+// for log backends that treat the default "!NILVALUE" marker specially.
+// An empty value restores the default "!NILVALUE".
+//
+// SetNilValue is not thread-safe. It should be called before any
+// StructuredError is marshaled.
+func SetNilValue(value string) {
+	nilValue = cmpOr(value, defaultNilValue)
+}
+
+// OmitEmpty reports whether the marshalers omit the empty tags, attrs, errors and stack of a StructuredError.
+func OmitEmpty() bool {
+	return omitEmpty
+}
+
+// SetOmitEmpty sets whether the marshalers omit the empty tags, attrs, errors and stack of a StructuredError.
+//
+// By default, empty fields are omitted. When disabled, the text and logger marshalers always emit them,
+// as empty lists, objects, groups or strings, for log backends that require a stable schema.
+// The gob, CBOR and MessagePack encodings are not affected, as they are decoded by this package.
+//
+// SetOmitEmpty is not thread-safe. It should be called before any
+// StructuredError is marshaled.
+func SetOmitEmpty(enabled bool) {
+	omitEmpty = enabled
+}
+
+// keepField reports whether a field with the given length is marshaled, according to SetOmitEmpty.
+func keepField(length int) bool {
+	return length > zero || !omitEmpty
+}
+
+// TrimMessages reports whether the messages of a StructuredError are trimmed of leading and trailing whitespace.
+func TrimMessages() bool {
+	return trimMessages
+}
+
+// SetTrimMessages sets whether the messages of a StructuredError are trimmed of leading and trailing whitespace,
+// as the marshalers already do for the messages of errors that are not a StructuredError.
+//
+// By default, messages are kept as given. When enabled, New, Newf and NewPooled store the trimmed message,
+// and Error() and every marshaler emit it trimmed, even for errors created before or built by hand.
+//
+// SetTrimMessages is not thread-safe. It should be called before any
+// StructuredError is created or marshaled.
+func SetTrimMessages(enabled bool) {
+	trimMessages = enabled
+}
+
+// trimmedMessage returns the given message trimmed of leading and trailing whitespace, according to SetTrimMessages.
+func trimmedMessage(message string) string {
+	if !trimMessages {
+		return message
+	}
+
+	return strings.TrimSpace(message)
+}
+
+// stackLines returns the lines of the given stack, or nil if it is empty.
+func stackLines(stack []byte) []string {
+	if len(stack) == zero {
+		return nil
+	}
+
+	return strings.Split(string(stack), newLine)
+}
+
 // add appends the given errors to the receiver's errors.
 //
 // The given errors are appended to the end of the receiver's errors.
@@ -115,6 +228,50 @@ func (receiver *normalizerTarget) add(err ...error) {
 	receiver.errs = append(receiver.errs, err...)
 }
 
+// grow makes sure the receiver's errors have room for the given errors once normalized,
+// so wide joins do not grow the receiver's errors one append at a time.
+//
+// The room is computed from the given errors, counting the children of joined StructuredErrors
+// and of the errors implementing MultiUnwrapper instead of the errors themselves,
+// since normalizeErrors adds their children directly to the receiver's errors.
+func (receiver *normalizerTarget) grow(errs []error) {
+	size := zero
+
+	for _, err := range errs {
+		switch value := err.(type) { //nolint:errorlint // only direct children are counted
+		case *StructuredError:
+			if value != nil && value.joined {
+				size += len(value.Errors)
+			} else {
+				size++
+			}
+		case MultiUnwrapper:
+			size += len(value.Unwrap())
+		default:
+			size++
+		}
+	}
+
+	if cap(receiver.errs)-len(receiver.errs) >= size {
+		return
+	}
+
+	errs = make([]error, len(receiver.errs), len(receiver.errs)+size)
+	copy(errs, receiver.errs)
+
+	receiver.errs = errs
+}
+
+// normalizedErrors returns the receiver's errors normalized by normalizeErrors.
+func (receiver *StructuredError) normalizedErrors() []error {
+	target := normalizerTarget{
+		errs: make([]error, zero, len(receiver.Errors)),
+	}
+	normalizeErrors(zero, &target, receiver.Errors...)
+
+	return target.errs
+}
+
 // normalizeErrors takes a depth, a target, and a variable number of errors
 // and normalizes the given errors.
 //
@@ -138,67 +295,90 @@ func normalizeErrors(depth int, target *normalizerTarget, errs ...error) {
 		return
 	}
 
-	_depth := depth + one
+	target.grow(errs)
 
 	for _, err := range errs {
-		if err == nil {
+		// Errors that are a StructuredError or cannot be unwrapped are handled without stderrors.As,
+		// which allocates its targets and walks the error again for every type it looks for.
+		switch value := err.(type) { //nolint:errorlint // wrapped errors are handled by normalizeWrapped
+		case nil:
+			target.add(err)
+		case *StructuredError:
+			normalizeStructured(depth, target, err, value)
+		case SingleUnwrapper, MultiUnwrapper, asser:
+			normalizeWrapped(depth, target, err)
+		default:
 			target.add(err)
-
-			continue
 		}
+	}
+}
 
-		{
-			var (
-				_err  *StructuredError
-				_err1 SingleUnwrapper
-				_err2 MultiUnwrapper
-			)
-
-			switch {
-			case stderrors.As(err, &_err):
-				if _err == nil {
-					target.add(err)
-
-					continue
-				}
-
-				if _err.joined {
-					normalizeErrors(depth, target, _err.Errors...)
-
-					continue
-				}
-
-				if len(_err.Errors) == zero {
-					target.add(err)
-
-					continue
-				}
-
-				_target := normalizerTarget{errs: make([]error, zero, len(_err.Errors))}
-				normalizeErrors(_depth, &_target, _err.Errors...)
-				target.add(
-					&StructuredError{
-						Message: _err.Message,
-						Attrs:   _err.Attrs,
-						Errors:  _target.errs,
-						Tags:    _err.Tags,
-						Stack:   _err.Stack,
-					},
-				)
-			case stderrors.As(err, &_err1):
-				normalizeErrors(depth, target, _err1.Unwrap())
-			case stderrors.As(err, &_err2):
-				normalizeErrors(depth, target, _err2.Unwrap()...)
-			default:
-				target.add(err)
-			}
-		}
+// normalizeWrapped normalizes an error that may wrap other errors, using stderrors.As
+// to find the first StructuredError, SingleUnwrapper or MultiUnwrapper in its tree.
+func normalizeWrapped(depth int, target *normalizerTarget, err error) {
+	var (
+		_err  *StructuredError
+		_err1 SingleUnwrapper
+		_err2 MultiUnwrapper
+	)
+
+	switch {
+	case stderrors.As(err, &_err):
+		normalizeStructured(depth, target, err, _err)
+	case stderrors.As(err, &_err1):
+		normalizeErrors(depth, target, _err1.Unwrap())
+	case stderrors.As(err, &_err2):
+		normalizeErrors(depth, target, _err2.Unwrap()...)
+	default:
+		target.add(err)
+	}
+}
+
+// normalizeStructured normalizes the StructuredError found in err.
+//
+// The errors of joined StructuredErrors are added to the target, StructuredErrors without errors
+// are added as they are, and the others are added as copies with their errors normalized.
+func normalizeStructured(depth int, target *normalizerTarget, err error, value *StructuredError) {
+	if value == nil {
+		target.add(err)
+
+		return
+	}
+
+	if value.joined {
+		normalizeErrors(depth, target, value.Errors...)
+
+		return
+	}
+
+	if len(value.Errors) == zero {
+		target.add(err)
+
+		return
 	}
+
+	_target := normalizerTarget{errs: make([]error, zero, len(value.Errors))}
+	normalizeErrors(depth+one, &_target, value.Errors...)
+	target.add(
+		&StructuredError{
+			Message:    value.Message,
+			Code:       value.Code,
+			HTTPStatus: value.HTTPStatus,
+			Attrs:      value.Attrs,
+			Errors:     _target.errs,
+			Tags:       value.Tags,
+			Stack:      value.Stack,
+			frames:     value.frames,
+			pcs:        value.pcs,
+			caller:     value.caller,
+		},
+	)
 }
 
 // cmpOr returns the first of its arguments that is not equal to the zero value.
 // If no argument is non-zero, it returns the zero value.
-// This is here since cmp.Or is not available in Go 1.18.
+// This is here since cmp.Or is not available before Go 1.22.
+//
 //nolint:ireturn // this is a helper function
 func cmpOr[T comparable](vals ...T) T {
 	var def T
@@ -210,3 +390,15 @@ func cmpOr[T comparable](vals ...T) T {
 
 	return def
 }
+
+// cloneSlice returns a shallow copy of the given slice, or nil if the given slice is nil.
+func cloneSlice[T any](slice []T) []T {
+	if slice == nil {
+		return nil
+	}
+
+	result := make([]T, len(slice))
+	copy(result, slice)
+
+	return result
+}
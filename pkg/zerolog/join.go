@@ -57,3 +57,194 @@ func JoinIf(errs ...error) error {
 
 	return nil
 }
+
+// Flatten pulls up the errors of every joined StructuredError directly in the receiver's Errors,
+// one level deep, and returns the receiver for chaining.
+// For example, the Errors of Join(Join(a, b), c) become a, b and c.
+//
+// Flatten does nothing if the receiver was not created via Join or JoinIf,
+// and errors that were not created via Join or JoinIf are kept as they are.
+// This method mutates the receiver in place, but not the nested errors.
+func (receiver *StructuredError) Flatten() *StructuredError {
+	if receiver == nil || !receiver.joined {
+		return receiver
+	}
+
+	receiver.Errors = appendFlattened(make([]error, zero, len(receiver.Errors)), receiver.Errors, false)
+
+	return receiver
+}
+
+// FlattenAll is similar to Flatten, but it pulls up the errors of nested joined StructuredErrors at any depth.
+// For example, the Errors of Join(Join(a, Join(b, c)), d) become a, b, c and d.
+// This method mutates the receiver in place, but not the nested errors.
+func (receiver *StructuredError) FlattenAll() *StructuredError {
+	if receiver == nil || !receiver.joined {
+		return receiver
+	}
+
+	receiver.Errors = appendFlattened(make([]error, zero, len(receiver.Errors)), receiver.Errors, true)
+
+	return receiver
+}
+
+// appendFlattened appends errs to target, replacing every joined StructuredError with its errors.
+// If recursive is true, the errors of nested joined StructuredErrors are replaced as well.
+func appendFlattened(target, errs []error, recursive bool) []error {
+	for _, err := range errs {
+		structured, ok := err.(*StructuredError) //nolint:errorlint // only direct joined errors are flattened
+		if !ok || structured == nil || !structured.joined {
+			target = append(target, err)
+
+			continue
+		}
+
+		if recursive {
+			target = appendFlattened(target, structured.Errors, recursive)
+		} else {
+			target = append(target, structured.Errors...)
+		}
+	}
+
+	return target
+}
+
+// ValidationBuilder aggregates field validation errors into a joined StructuredError,
+// with one child error per field. The zero value is not usable, use NewValidation instead.
+//
+// Example:
+//
+//	validation := NewValidation()
+//	validation.AddField("email", "must be a valid email")
+//	validation.AddField("age", "must be positive").WithCode("invalid_age")
+//
+//	if err := validation.Err(); err != nil {
+//	    return err
+//	}
+type ValidationBuilder struct {
+	err *StructuredError
+}
+
+const (
+	// validationTag is the tag of the field errors added by AddField.
+	validationTag = "validation"
+
+	// fieldKey is the key of the attr holding the field of the errors added by AddField.
+	fieldKey = "field"
+)
+
+// NewValidation returns an empty ValidationBuilder.
+func NewValidation() *ValidationBuilder {
+	return &ValidationBuilder{
+		err: &StructuredError{
+			joined: true,
+		},
+	}
+}
+
+// AddField appends a child error with the given message, tagged "validation"
+// and with a StringType "field" attr holding the given field.
+// It returns the child error, so more context can be added to it,
+// keeping in mind that WithAttrs replaces the "field" attr.
+func (receiver *ValidationBuilder) AddField(field, message string) *StructuredError {
+	child := New(message).WithTags(validationTag).WithAttrs(String(fieldKey, field))
+	receiver.err.Errors = append(receiver.err.Errors, child)
+
+	return child
+}
+
+// Fields returns the message of every added field error, keyed by its field.
+// If a field was added more than once, the last message is kept.
+func (receiver *ValidationBuilder) Fields() map[string]string {
+	fields := make(map[string]string, len(receiver.err.Errors))
+
+	for _, err := range receiver.err.Errors {
+		child, ok := err.(*StructuredError) //nolint:errorlint // AddField only appends *StructuredError
+		if !ok {
+			continue
+		}
+
+		if field, ok := child.GetAttr(fieldKey); ok && field.Type == StringType {
+			fields[field.Value.(string)] = child.Message //nolint:forcetypeassert,errcheck // checked by Type
+		}
+	}
+
+	return fields
+}
+
+// Err returns the joined StructuredError of the added field errors, or nil if no field was added,
+// so the result can be compared with nil.
+// Fields added after Err is called are added to the same StructuredError.
+func (receiver *ValidationBuilder) Err() error {
+	if len(receiver.err.Errors) == zero {
+		return nil
+	}
+
+	return receiver.err
+}
+
+// Merge returns a new StructuredError combining a and b, as MergeAll(a, b) does.
+func Merge(a, b *StructuredError) *StructuredError {
+	return MergeAll(a, b)
+}
+
+// MergeAll returns a new StructuredError combining the given errors, any nil error values are skipped.
+// MergeAll returns nil if every value in errs is nil.
+//
+// The returned StructuredError has:
+//   - the first non-empty Message
+//   - the first non-empty Code
+//   - the Tags of every error, in order and without duplicates
+//   - the Attrs of every error, appended in order
+//   - the Errors of every error, appended in order
+//   - the stack of the first error that has one.
+//
+// The given errors are not mutated, and the returned StructuredError does not share
+// its Tags, Attrs or Errors with them.
+func MergeAll(errs ...*StructuredError) *StructuredError {
+	var merged *StructuredError
+
+	seenTags := make(map[string]struct{})
+
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		if merged == nil {
+			merged = &StructuredError{}
+		}
+
+		if merged.Message == emptyString {
+			merged.Message = err.Message
+		}
+
+		if merged.Code == emptyString {
+			merged.Code = err.Code
+		}
+
+		if merged.HTTPStatus == zero {
+			merged.HTTPStatus = err.HTTPStatus
+		}
+
+		for _, tag := range err.Tags {
+			if _, ok := seenTags[tag]; ok {
+				continue
+			}
+
+			seenTags[tag] = struct{}{}
+			merged.Tags = append(merged.Tags, tag)
+		}
+
+		merged.Attrs = append(merged.Attrs, cloneAttrs(err.Attrs)...)
+		merged.Errors = append(merged.Errors, err.Errors...)
+
+		if len(merged.Stack) == zero && len(merged.frames) == zero {
+			merged.Stack = cloneSlice(err.Stack)
+			merged.frames = cloneSlice(err.frames)
+			merged.pcs = cloneSlice(err.pcs)
+		}
+	}
+
+	return merged
+}